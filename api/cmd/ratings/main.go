@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/tt-trichter/app/api/internal/database"
+	"github.com/tt-trichter/app/api/internal/rating"
+)
+
+func main() {
+	if len(os.Args) != 2 || os.Args[1] != "replay" {
+		fmt.Fprintln(os.Stderr, "usage: ratings replay")
+		os.Exit(2)
+	}
+
+	db := database.NewService()
+	defer db.Close()
+
+	ctx := context.Background()
+	tx, err := db.Pool().Begin(ctx)
+	if err != nil {
+		log.Fatalf("Error starting transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	games, err := rating.ConfigFromEnv().Replay(ctx, db.Queries().WithTx(tx))
+	if err != nil {
+		log.Fatalf("Error replaying ratings: %v", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Fatalf("Error committing ratings: %v", err)
+	}
+
+	log.Printf("Replayed %d rated games", games)
+}
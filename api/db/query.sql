@@ -1,11 +1,12 @@
 -- name: GetRuns :many
-SELECT id, user_id, data, created_at, image FROM runs
+SELECT id, user_id, data, created_at, image, deleted_at, personal_bests, records, device_id, event_id, division_id, review_status, anomaly_score, review_reasons FROM runs
+WHERE deleted_at IS NULL
 ORDER BY created_at DESC;
 
 -- name: SaveRun :one
-INSERT INTO runs (user_id, data, image, created_at)
-VALUES ($1, $2, $3, NOW())
-RETURNING id, user_id, data, created_at, image;
+INSERT INTO runs (user_id, data, image, device_id, event_id, division_id, review_status, anomaly_score, review_reasons, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, COALESCE($10::timestamp, NOW()))
+RETURNING id, user_id, data, created_at, image, deleted_at, personal_bests, records, device_id, event_id, division_id, review_status, anomaly_score, review_reasons;
 
 -- name: GetAllRunsWithUsers :many
 SELECT 
@@ -19,35 +20,997 @@ SELECT
     u.username as user_username
 FROM runs r
 LEFT JOIN "user" u ON r.user_id = u.id
+WHERE r.deleted_at IS NULL AND r.review_status = 'approved'
 ORDER BY (r.data->>'rate')::float DESC;
 
+-- name: GetDeletedRunsWithUsers :many
+SELECT
+    r.id,
+    r.user_id,
+    r.data,
+    r.image,
+    r.created_at,
+    r.deleted_at,
+    u.id as user_id_full,
+    u.name as user_name,
+    u.username as user_username
+FROM runs r
+LEFT JOIN "user" u ON r.user_id = u.id
+WHERE r.deleted_at IS NOT NULL
+ORDER BY r.deleted_at DESC;
+
 -- name: UpdateRunWithUser :one
 UPDATE runs 
 SET user_id = $2
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, user_id, data, created_at, image, deleted_at, personal_bests, records, device_id, event_id, division_id, review_status, anomaly_score, review_reasons;
+
+-- name: DeleteRun :execrows
+UPDATE runs
+SET deleted_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL;
+
+-- name: RestoreRun :one
+UPDATE runs
+SET deleted_at = NULL
+WHERE id = $1 AND deleted_at IS NOT NULL
+RETURNING id, user_id, data, created_at, image, deleted_at, personal_bests, records, device_id, event_id, division_id, review_status, anomaly_score, review_reasons;
+
+-- name: PurgeDeletedRuns :execrows
+DELETE FROM runs
+WHERE deleted_at IS NOT NULL AND deleted_at < $1;
+
+-- name: GetRunById :one
+SELECT id, user_id, data, created_at, image, deleted_at, personal_bests, records, device_id, event_id, division_id, review_status, anomaly_score, review_reasons
+FROM runs
+WHERE id = $1 AND deleted_at IS NULL;
+
+-- name: GetRunWithUserById :one
+SELECT
+    r.id,
+    r.user_id,
+    r.data,
+    r.image,
+    r.created_at,
+    u.id as user_id_full,
+    u.name as user_name,
+    u.username as user_username,
+    r.personal_bests,
+    r.records
+FROM runs r
+LEFT JOIN "user" u ON r.user_id = u.id
+WHERE r.id = $1 AND r.deleted_at IS NULL AND r.review_status = 'approved';
+
+-- name: GetRunStandings :one
+SELECT
+    (SELECT COUNT(*) FROM runs o
+     WHERE o.deleted_at IS NULL AND o.review_status = 'approved'
+       AND (o.data->>'rate')::float > (r.data->>'rate')::float)::bigint + 1 as overall_rank,
+    (SELECT COUNT(*) FROM runs o
+     WHERE o.deleted_at IS NULL AND o.review_status = 'approved'
+       AND o.user_id = r.user_id
+       AND (o.data->>'rate')::float > (r.data->>'rate')::float)::bigint + 1 as user_rank
+FROM runs r
+WHERE r.id = $1 AND r.deleted_at IS NULL;
+
+-- name: GetRunForUpdate :one
+SELECT id, user_id, data, created_at, image, deleted_at, personal_bests, records, device_id, event_id, division_id, review_status, anomaly_score, review_reasons
+FROM runs
+WHERE id = $1 AND deleted_at IS NULL
+FOR UPDATE;
+
+-- name: UpdateRunData :one
+UPDATE runs
+SET data = $2
 WHERE id = $1
-RETURNING id, user_id, data, created_at, image;
+RETURNING id, user_id, data, created_at, image, deleted_at, personal_bests, records, device_id, event_id, division_id, review_status, anomaly_score, review_reasons;
+
+-- name: CreateRunRevision :one
+INSERT INTO run_revisions (run_id, data, reason, edited_by, created_at)
+VALUES ($1, $2, $3, $4, NOW())
+RETURNING id, run_id, data, reason, edited_by, created_at;
 
--- name: DeleteRun :exec
-DELETE FROM runs WHERE id = $1;
+-- name: GetRunRevisions :many
+SELECT id, run_id, data, reason, edited_by, created_at
+FROM run_revisions
+WHERE run_id = $1
+ORDER BY created_at DESC;
+
+-- name: UpdateRunRecords :exec
+UPDATE runs
+SET personal_bests = $2, records = $3
+WHERE id = $1;
 
 -- name: GetRunsByUserId :many
-SELECT id, user_id, data, created_at, image 
+SELECT id, user_id, data, created_at, image, deleted_at, personal_bests, records, device_id, event_id, division_id, review_status, anomaly_score, review_reasons
 FROM runs 
-WHERE user_id = $1
+WHERE user_id = $1 AND deleted_at IS NULL AND review_status = 'approved'
 ORDER BY created_at DESC;
 
 -- name: GetRecentRunsForUser :many
-SELECT id, user_id, data, created_at, image 
+SELECT id, user_id, data, created_at, image, deleted_at, personal_bests, records, device_id, event_id, division_id, review_status, anomaly_score, review_reasons
 FROM runs 
-WHERE user_id = $1
+WHERE user_id = $1 AND deleted_at IS NULL AND review_status = 'approved'
 ORDER BY created_at DESC
 LIMIT $2;
 
+-- name: CreateEvent :one
+INSERT INTO events (name, venue, starts_at, ends_at, device_ids, created_at)
+VALUES ($1, $2, $3, $4, $5, NOW())
+RETURNING id, name, venue, starts_at, ends_at, device_ids, created_at;
+
+-- name: UpdateEvent :one
+UPDATE events
+SET name = $2, venue = $3, starts_at = $4, ends_at = $5, device_ids = $6
+WHERE id = $1
+RETURNING id, name, venue, starts_at, ends_at, device_ids, created_at;
+
+-- name: DeleteEvent :execrows
+DELETE FROM events WHERE id = $1;
+
+-- name: GetEvent :one
+SELECT id, name, venue, starts_at, ends_at, device_ids, created_at
+FROM events
+WHERE id = $1;
+
+-- name: ListEvents :many
+SELECT id, name, venue, starts_at, ends_at, device_ids, created_at
+FROM events
+ORDER BY starts_at DESC;
+
+-- name: FindActiveEventId :one
+SELECT id
+FROM events
+WHERE starts_at <= sqlc.arg(at)
+  AND (ends_at IS NULL OR sqlc.arg(at) < ends_at)
+  AND (sqlc.narg(device_id)::text IS NULL
+       OR cardinality(device_ids) = 0
+       OR sqlc.narg(device_id)::text = ANY(device_ids))
+ORDER BY starts_at DESC
+LIMIT 1;
+
+-- name: AssignRunsToEvents :execrows
+UPDATE runs r
+SET event_id = (
+    SELECT e.id
+    FROM events e
+    WHERE e.starts_at <= r.created_at
+      AND (e.ends_at IS NULL OR r.created_at < e.ends_at)
+      AND (r.device_id IS NULL
+           OR cardinality(e.device_ids) = 0
+           OR r.device_id = ANY(e.device_ids))
+    ORDER BY e.starts_at DESC
+    LIMIT 1
+)
+WHERE r.event_id IS NULL OR r.event_id = $1;
+
+-- name: CreateDivision :one
+INSERT INTO divisions (name, volume, tolerance, handicap, created_at)
+VALUES ($1, $2, $3, $4, NOW())
+RETURNING id, name, volume, tolerance, handicap, created_at;
+
+-- name: UpdateDivision :one
+UPDATE divisions
+SET name = $2, volume = $3, tolerance = $4, handicap = $5
+WHERE id = $1
+RETURNING id, name, volume, tolerance, handicap, created_at;
+
+-- name: DeleteDivision :execrows
+DELETE FROM divisions
+WHERE id = $1;
+
+-- name: GetDivision :one
+SELECT id, name, volume, tolerance, handicap, created_at
+FROM divisions
+WHERE id = $1;
+
+-- name: ListDivisions :many
+SELECT id, name, volume, tolerance, handicap, created_at
+FROM divisions
+ORDER BY volume;
+
+-- name: FindDivisionId :one
+SELECT id
+FROM divisions
+WHERE abs(volume - sqlc.arg(volume)::float) <= tolerance
+ORDER BY abs(volume - sqlc.arg(volume)::float), volume
+LIMIT 1;
+
+-- name: AssignRunsToDivisions :execrows
+UPDATE runs r
+SET division_id = (
+    SELECT d.id
+    FROM divisions d
+    WHERE abs(d.volume - (r.data->>'volume')::float) <= d.tolerance
+    ORDER BY abs(d.volume - (r.data->>'volume')::float), d.volume
+    LIMIT 1
+)
+WHERE sqlc.narg(run_id)::uuid IS NULL OR r.id = sqlc.narg(run_id);
+
+-- name: GetEventSummary :one
+SELECT
+    COUNT(*) as run_count,
+    COUNT(DISTINCT user_id) as participant_count,
+    COALESCE(SUM((data->>'volume')::float), 0)::float as total_volume,
+    COALESCE(MAX((data->>'rate')::float), 0)::float as best_rate,
+    COALESCE(AVG((data->>'rate')::float), 0)::float as mean_rate,
+    COALESCE(MIN((data->>'duration')::float), 0)::float as fastest_duration,
+    MIN(created_at)::timestamp as first_run_at,
+    MAX(created_at)::timestamp as last_run_at
+FROM runs
+WHERE event_id = $1 AND deleted_at IS NULL AND review_status = 'approved';
+
+-- name: CreateDuel :one
+INSERT INTO duels (metric, device_a, device_b, status, started_at)
+VALUES ($1, $2, $3, 'open', NOW())
+RETURNING id, metric, device_a, device_b, run_a, run_b, winner_run_id, status, started_at, finished_at;
+
+-- name: GetDuel :one
+SELECT id, metric, device_a, device_b, run_a, run_b, winner_run_id, status, started_at, finished_at
+FROM duels
+WHERE id = $1;
+
+-- name: ListDuels :many
+SELECT id, metric, device_a, device_b, run_a, run_b, winner_run_id, status, started_at, finished_at
+FROM duels
+WHERE sqlc.narg(status)::text IS NULL OR status = sqlc.narg(status)
+ORDER BY started_at DESC
+LIMIT sqlc.arg(limit_count);
+
+-- name: GetOpenDuelForDevice :one
+SELECT id, metric, device_a, device_b, run_a, run_b, winner_run_id, status, started_at, finished_at
+FROM duels
+WHERE status = 'open' AND (device_a = sqlc.arg(device_id) OR device_b = sqlc.arg(device_id))
+ORDER BY started_at DESC
+LIMIT 1
+FOR UPDATE;
+
+-- name: SetDuelRuns :one
+UPDATE duels
+SET run_a = $2, run_b = $3
+WHERE id = $1
+RETURNING id, metric, device_a, device_b, run_a, run_b, winner_run_id, status, started_at, finished_at;
+
+-- name: FinishDuel :one
+UPDATE duels
+SET status = $2, winner_run_id = $3, finished_at = NOW()
+WHERE id = $1 AND status = 'open'
+RETURNING id, metric, device_a, device_b, run_a, run_b, winner_run_id, status, started_at, finished_at;
+
+-- name: ExpireDuels :many
+UPDATE duels
+SET status = 'expired', finished_at = NOW()
+WHERE status = 'open' AND started_at < $1
+RETURNING id, metric, device_a, device_b, run_a, run_b, winner_run_id, status, started_at, finished_at;
+
+-- name: GetUserDuels :many
+SELECT
+    d.id, d.metric, d.device_a, d.device_b, d.run_a, d.run_b, d.winner_run_id, d.status, d.started_at, d.finished_at,
+    ra.user_id as user_a,
+    rb.user_id as user_b
+FROM duels d
+JOIN runs ra ON d.run_a = ra.id
+JOIN runs rb ON d.run_b = rb.id
+WHERE d.status = 'finished'
+  AND (ra.user_id = sqlc.arg(user_id) OR rb.user_id = sqlc.arg(user_id))
+  AND (sqlc.narg(opponent_id)::text IS NULL
+       OR ra.user_id = sqlc.narg(opponent_id)
+       OR rb.user_id = sqlc.narg(opponent_id))
+ORDER BY d.finished_at DESC
+LIMIT sqlc.arg(limit_count);
+
+-- name: CreateTournament :one
+INSERT INTO tournaments (name, format, metric, status, created_at)
+VALUES ($1, $2, $3, 'registration', NOW())
+RETURNING id, name, format, metric, status, winner_user_id, started_at, finished_at, created_at;
+
+-- name: GetTournament :one
+SELECT id, name, format, metric, status, winner_user_id, started_at, finished_at, created_at
+FROM tournaments
+WHERE id = $1;
+
+-- name: GetTournamentForUpdate :one
+SELECT id, name, format, metric, status, winner_user_id, started_at, finished_at, created_at
+FROM tournaments
+WHERE id = $1
+FOR UPDATE;
+
+-- name: ListTournaments :many
+SELECT id, name, format, metric, status, winner_user_id, started_at, finished_at, created_at
+FROM tournaments
+ORDER BY created_at DESC;
+
+-- name: StartTournament :execrows
+UPDATE tournaments
+SET status = 'running', started_at = NOW()
+WHERE id = $1 AND status = 'registration';
+
+-- name: FinishTournament :exec
+UPDATE tournaments
+SET status = 'finished', winner_user_id = $2, finished_at = NOW()
+WHERE id = $1;
+
+-- name: AddTournamentParticipant :execrows
+INSERT INTO tournament_participants (tournament_id, user_id, created_at)
+VALUES ($1, $2, NOW())
+ON CONFLICT (tournament_id, user_id) DO NOTHING;
+
+-- name: RemoveTournamentParticipant :execrows
+DELETE FROM tournament_participants
+WHERE tournament_id = $1 AND user_id = $2;
+
+-- name: GetTournamentParticipants :many
+SELECT
+    p.user_id,
+    p.seed,
+    p.created_at,
+    u.name as user_name,
+    u.username as user_username
+FROM tournament_participants p
+JOIN "user" u ON p.user_id = u.id
+WHERE p.tournament_id = $1
+ORDER BY p.seed NULLS LAST, p.created_at;
+
+-- name: SetTournamentSeed :exec
+UPDATE tournament_participants
+SET seed = $3
+WHERE tournament_id = $1 AND user_id = $2;
+
+-- name: CreateTournamentMatch :exec
+INSERT INTO tournament_matches (tournament_id, bracket, round, position, player_a, player_b, status)
+VALUES ($1, $2, $3, $4, $5, $6, 'pending');
+
+-- name: GetTournamentMatches :many
+SELECT id, tournament_id, bracket, round, position, player_a, player_b, winner_user_id, status, duel_id, run_a, run_b, finished_at
+FROM tournament_matches
+WHERE tournament_id = $1
+ORDER BY bracket DESC, round, position;
+
+-- name: GetTournamentMatch :one
+SELECT id, tournament_id, bracket, round, position, player_a, player_b, winner_user_id, status, duel_id, run_a, run_b, finished_at
+FROM tournament_matches
+WHERE id = $1 AND tournament_id = $2;
+
+-- name: UpdateTournamentMatchState :exec
+UPDATE tournament_matches
+SET player_a = $2, player_b = $3, winner_user_id = $4, status = $5
+WHERE id = $1;
+
+-- name: RecordTournamentMatchResult :execrows
+UPDATE tournament_matches
+SET winner_user_id = $2, duel_id = $3, run_a = $4, run_b = $5, status = 'finished', finished_at = NOW()
+WHERE id = $1 AND status = 'ready';
+
+-- name: FindReadyTournamentMatches :many
+SELECT m.id, m.tournament_id, m.bracket, m.round, m.position, m.player_a, m.player_b, m.winner_user_id, m.status, m.duel_id, m.run_a, m.run_b, m.finished_at
+FROM tournament_matches m
+JOIN tournaments t ON m.tournament_id = t.id
+WHERE t.status = 'running'
+  AND m.status = 'ready'
+  AND ((m.player_a = sqlc.arg(user_a) AND m.player_b = sqlc.arg(user_b))
+       OR (m.player_a = sqlc.arg(user_b) AND m.player_b = sqlc.arg(user_a)));
+
+-- name: CreateInitialUserRating :exec
+INSERT INTO user_ratings (user_id, rating, games, last_played_at, updated_at)
+VALUES ($1, $2, 0, $3, NOW())
+ON CONFLICT (user_id) DO NOTHING;
+
+-- name: GetUserRatingForUpdate :one
+SELECT user_id, rating, games, last_played_at, updated_at
+FROM user_ratings
+WHERE user_id = $1
+FOR UPDATE;
+
+-- name: SaveUserRating :exec
+INSERT INTO user_ratings (user_id, rating, games, last_played_at, updated_at)
+VALUES ($1, $2, 1, $3, NOW())
+ON CONFLICT (user_id) DO UPDATE
+SET rating = EXCLUDED.rating,
+    games = user_ratings.games + 1,
+    last_played_at = EXCLUDED.last_played_at,
+    updated_at = NOW();
+
+-- name: CreateRatingHistory :exec
+INSERT INTO rating_history (user_id, opponent_id, source, source_id, score, rating_before, rating_after, played_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
+
+-- name: GetUserRatings :many
+SELECT
+    r.user_id,
+    r.rating,
+    r.games,
+    r.last_played_at,
+    u.name as user_name,
+    u.username as user_username
+FROM user_ratings r
+JOIN "user" u ON r.user_id = u.id;
+
+-- name: GetUserRating :one
+SELECT user_id, rating, games, last_played_at, updated_at
+FROM user_ratings
+WHERE user_id = $1;
+
+-- name: GetRatingHistory :many
+SELECT id, user_id, opponent_id, source, source_id, score, rating_before, rating_after, played_at
+FROM rating_history
+WHERE user_id = $1
+ORDER BY played_at DESC
+LIMIT $2;
+
+-- name: DeleteAllRatingHistory :exec
+DELETE FROM rating_history;
+
+-- name: DeleteAllUserRatings :exec
+DELETE FROM user_ratings;
+
+-- name: GetRatedGames :many
+SELECT
+    'duel'::text as source,
+    d.id as source_id,
+    ra.user_id::text as player_a,
+    rb.user_id::text as player_b,
+    (CASE WHEN d.winner_run_id IS NULL THEN 0.5 WHEN d.winner_run_id = d.run_a THEN 1 ELSE 0 END)::float as score_a,
+    d.finished_at::timestamp as played_at
+FROM duels d
+JOIN runs ra ON d.run_a = ra.id
+JOIN runs rb ON d.run_b = rb.id
+WHERE d.status = 'finished'
+  AND ra.user_id IS NOT NULL
+  AND rb.user_id IS NOT NULL
+  AND ra.user_id <> rb.user_id
+UNION ALL
+SELECT
+    'match'::text as source,
+    m.id as source_id,
+    m.player_a::text as player_a,
+    m.player_b::text as player_b,
+    (CASE WHEN m.winner_user_id = m.player_a THEN 1 ELSE 0 END)::float as score_a,
+    m.finished_at::timestamp as played_at
+FROM tournament_matches m
+WHERE m.status = 'finished' AND m.duel_id IS NULL
+ORDER BY played_at, source_id;
+
+-- name: CreateTeam :one
+INSERT INTO teams (name, description, created_at)
+VALUES ($1, $2, NOW())
+RETURNING id, name, description, created_at;
+
+-- name: GetTeam :one
+SELECT id, name, description, created_at
+FROM teams
+WHERE id = $1;
+
+-- name: ListTeams :many
+SELECT
+    t.id,
+    t.name,
+    t.description,
+    t.created_at,
+    COUNT(m.user_id) as member_count
+FROM teams t
+LEFT JOIN team_members m ON m.team_id = t.id
+GROUP BY t.id
+ORDER BY t.name;
+
+-- name: UpdateTeam :one
+UPDATE teams
+SET name = $2, description = $3
+WHERE id = $1
+RETURNING id, name, description, created_at;
+
+-- name: DeleteTeam :execrows
+DELETE FROM teams
+WHERE id = $1;
+
+-- name: AddTeamMember :execrows
+INSERT INTO team_members (team_id, user_id, role, joined_at)
+VALUES ($1, $2, $3, NOW())
+ON CONFLICT (team_id, user_id) DO NOTHING;
+
+-- name: GetTeamMember :one
+SELECT team_id, user_id, role, joined_at
+FROM team_members
+WHERE team_id = $1 AND user_id = $2;
+
+-- name: GetTeamMembers :many
+SELECT
+    m.user_id,
+    m.role,
+    m.joined_at,
+    u.name as user_name,
+    u.username as user_username
+FROM team_members m
+JOIN "user" u ON m.user_id = u.id
+WHERE m.team_id = $1
+ORDER BY m.role = 'captain' DESC, m.joined_at;
+
+-- name: SetTeamMemberRole :execrows
+UPDATE team_members
+SET role = $3
+WHERE team_id = $1 AND user_id = $2;
+
+-- name: RemoveTeamMember :execrows
+DELETE FROM team_members
+WHERE team_id = $1 AND user_id = $2;
+
+-- name: LockTeamMembers :many
+SELECT team_id, user_id, role, joined_at
+FROM team_members
+WHERE team_id = $1
+FOR UPDATE;
+
+-- name: GetUserTeams :many
+SELECT
+    t.id,
+    t.name,
+    t.description,
+    t.created_at,
+    m.role,
+    m.joined_at
+FROM team_members m
+JOIN teams t ON m.team_id = t.id
+WHERE m.user_id = $1
+ORDER BY m.joined_at;
+
+-- name: GetTeamStats :one
+SELECT
+    COUNT(r.id) as runs,
+    COALESCE(SUM((r.data->>'volume')::float), 0)::float as total_volume,
+    COALESCE(MAX((r.data->>'rate')::float), 0)::float as best_rate,
+    COALESCE(AVG((r.data->>'rate')::float), 0)::float as average_rate,
+    MAX(r.created_at)::timestamp as last_run_at
+FROM team_members m
+JOIN runs r ON r.user_id = m.user_id
+WHERE m.team_id = $1
+  AND r.deleted_at IS NULL
+  AND r.review_status = 'approved'
+  AND r.created_at >= m.joined_at;
+
+-- name: CreateTeamInvitation :one
+INSERT INTO team_invitations (team_id, user_id, invited_by, status, created_at)
+VALUES ($1, $2, $3, 'pending', NOW())
+RETURNING id, team_id, user_id, invited_by, status, created_at, responded_at;
+
+-- name: GetTeamInvitationForUpdate :one
+SELECT id, team_id, user_id, invited_by, status, created_at, responded_at
+FROM team_invitations
+WHERE id = $1 AND team_id = $2
+FOR UPDATE;
+
+-- name: RespondToTeamInvitation :execrows
+UPDATE team_invitations
+SET status = $2, responded_at = NOW()
+WHERE id = $1 AND status = 'pending';
+
+-- name: GetTeamInvitations :many
+SELECT
+    i.id,
+    i.user_id,
+    i.invited_by,
+    i.created_at,
+    u.name as user_name,
+    u.username as user_username
+FROM team_invitations i
+JOIN "user" u ON i.user_id = u.id
+WHERE i.team_id = $1 AND i.status = 'pending'
+ORDER BY i.created_at;
+
+-- name: GetUserTeamInvitations :many
+SELECT
+    i.id,
+    i.team_id,
+    i.invited_by,
+    i.created_at,
+    t.name as team_name
+FROM team_invitations i
+JOIN teams t ON i.team_id = t.id
+WHERE i.user_id = $1 AND i.status = 'pending'
+ORDER BY i.created_at DESC;
+
+-- name: SaveRunSamples :execrows
+INSERT INTO run_samples (run_id, sample_count, data, created_at)
+VALUES ($1, $2, $3, NOW())
+ON CONFLICT (run_id) DO NOTHING;
+
+-- name: GetRunSamples :one
+SELECT run_id, sample_count, data, metrics, reported, verdict, created_at
+FROM run_samples
+WHERE run_id = $1;
+
+-- name: SaveRunVerification :exec
+UPDATE run_samples
+SET metrics = $2, reported = $3, verdict = $4
+WHERE run_id = $1;
+
+-- name: GetRunsByVerdict :many
+SELECT
+    r.id,
+    r.user_id,
+    r.data,
+    r.image,
+    r.created_at,
+    u.id as user_id_full,
+    u.name as user_name,
+    u.username as user_username,
+    s.metrics,
+    s.reported
+FROM run_samples s
+JOIN runs r ON s.run_id = r.id
+LEFT JOIN "user" u ON r.user_id = u.id
+WHERE s.verdict = $1 AND r.deleted_at IS NULL
+ORDER BY r.created_at DESC
+LIMIT $2;
+
+-- name: GetRunHistoryStats :one
+SELECT
+    COUNT(*) as run_count,
+    COALESCE(AVG((data->>'rate')::float), 0)::float as mean_rate,
+    COALESCE(STDDEV_SAMP((data->>'rate')::float), 0)::float as stddev_rate,
+    COALESCE(AVG((data->>'duration')::float), 0)::float as mean_duration,
+    COALESCE(STDDEV_SAMP((data->>'duration')::float), 0)::float as stddev_duration,
+    COALESCE(AVG((data->>'volume')::float), 0)::float as mean_volume,
+    COALESCE(STDDEV_SAMP((data->>'volume')::float), 0)::float as stddev_volume
+FROM runs
+WHERE deleted_at IS NULL AND review_status = 'approved';
+
+-- name: HoldRunForReview :one
+UPDATE runs
+SET review_status = 'pending', review_reasons = array_append(review_reasons, sqlc.arg(reason)::text)
+WHERE id = sqlc.arg(id)
+RETURNING id, user_id, data, created_at, image, deleted_at, personal_bests, records, device_id, event_id, division_id, review_status, anomaly_score, review_reasons;
+
+-- name: ReviewRun :one
+UPDATE runs
+SET review_status = sqlc.arg(status)::text
+WHERE id = sqlc.arg(id) AND review_status = 'pending' AND deleted_at IS NULL
+RETURNING id, user_id, data, created_at, image, deleted_at, personal_bests, records, device_id, event_id, division_id, review_status, anomaly_score, review_reasons;
+
+-- name: CreateRunReview :exec
+INSERT INTO run_reviews (run_id, status, reviewer, reason, created_at)
+VALUES ($1, $2, $3, $4, NOW());
+
+-- name: GetRunReviews :many
+SELECT id, run_id, status, reviewer, reason, created_at
+FROM run_reviews
+WHERE run_id = $1
+ORDER BY created_at DESC;
+
+-- name: GetRunsPendingReview :many
+SELECT
+    r.id,
+    r.user_id,
+    r.data,
+    r.image,
+    r.created_at,
+    u.id as user_id_full,
+    u.name as user_name,
+    u.username as user_username,
+    r.anomaly_score,
+    r.review_reasons,
+    (SELECT COUNT(*) FROM run_reports rp
+     WHERE rp.run_id = r.id
+       AND rp.created_at > COALESCE(
+           (SELECT MAX(rv.created_at) FROM run_reviews rv WHERE rv.run_id = r.id),
+           '-infinity'::timestamp
+       )) as report_count
+FROM runs r
+LEFT JOIN "user" u ON r.user_id = u.id
+WHERE r.review_status = 'pending' AND r.deleted_at IS NULL
+ORDER BY r.anomaly_score DESC, r.created_at
+LIMIT $1;
+
+-- name: CreateRunReport :one
+INSERT INTO run_reports (run_id, user_id, reason, created_at)
+VALUES ($1, $2, $3, NOW())
+RETURNING id, run_id, user_id, reason, created_at;
+
+-- name: CountOpenRunReports :one
+SELECT COUNT(*)
+FROM run_reports rp
+WHERE rp.run_id = $1
+  AND rp.created_at > COALESCE(
+      (SELECT MAX(rv.created_at) FROM run_reviews rv WHERE rv.run_id = $1),
+      '-infinity'::timestamp
+  );
+
+-- name: GetRunsRecordedSince :many
+SELECT id, user_id, data, created_at, image, deleted_at, personal_bests, records, device_id, event_id, division_id, review_status, anomaly_score, review_reasons
+FROM runs
+WHERE deleted_at IS NULL AND review_status = 'approved'
+  AND created_at >= sqlc.arg(since) AND id <> sqlc.arg(exclude_id)
+  AND (sqlc.narg(user_id)::text IS NULL OR user_id = sqlc.narg(user_id))
+ORDER BY created_at;
+
+-- name: RevokeAchievement :exec
+DELETE FROM user_achievements
+WHERE user_id = $1 AND achievement = $2;
+
+-- name: ClaimIdempotencyKey :execrows
+INSERT INTO idempotency_keys (key, route, fingerprint, created_at)
+VALUES (sqlc.arg(key), sqlc.arg(route), sqlc.arg(fingerprint), NOW())
+ON CONFLICT (key, route) DO UPDATE
+SET fingerprint = EXCLUDED.fingerprint,
+    status_code = NULL,
+    content_type = NULL,
+    response = NULL,
+    created_at = EXCLUDED.created_at
+WHERE idempotency_keys.created_at < sqlc.arg(expired_before);
+
+-- name: GetIdempotencyKey :one
+SELECT key, route, fingerprint, status_code, content_type, response, created_at
+FROM idempotency_keys
+WHERE key = $1 AND route = $2;
+
+-- name: CompleteIdempotencyKey :exec
+UPDATE idempotency_keys
+SET status_code = $3, content_type = $4, response = $5
+WHERE key = $1 AND route = $2;
+
+-- name: ReleaseIdempotencyKey :exec
+DELETE FROM idempotency_keys
+WHERE key = $1 AND route = $2;
+
+-- name: PurgeIdempotencyKeys :execrows
+DELETE FROM idempotency_keys
+WHERE created_at < $1;
+
+-- name: GetRunIdByClientId :one
+SELECT run_id
+FROM run_client_ids
+WHERE client_id = $1;
+
+-- name: SaveRunClientId :exec
+INSERT INTO run_client_ids (client_id, run_id)
+VALUES ($1, $2);
+
+-- name: SaveDeviceClock :exec
+UPDATE devices
+SET clock_offset_ms = $2,
+    clock_boot_id = $3,
+    clock_synced_at = NOW()
+WHERE id = $1 AND registered_at IS NOT NULL;
+
+-- name: GetDevice :one
+SELECT id, name, clock_offset_ms, clock_boot_id, clock_synced_at, registered_at, status, status_changed_at, last_heartbeat_at, firmware_version, uptime_seconds, wifi_rssi, sensor_healthy, sensor_error, created_at
+FROM devices
+WHERE id = $1;
+
+-- name: GetDeviceForUpdate :one
+SELECT id, name, clock_offset_ms, clock_boot_id, clock_synced_at, registered_at, status, status_changed_at, last_heartbeat_at, firmware_version, uptime_seconds, wifi_rssi, sensor_healthy, sensor_error, created_at
+FROM devices
+WHERE id = $1
+FOR UPDATE;
+
+-- name: ListDevices :many
+SELECT id, name, clock_offset_ms, clock_boot_id, clock_synced_at, registered_at, status, status_changed_at, last_heartbeat_at, firmware_version, uptime_seconds, wifi_rssi, sensor_healthy, sensor_error, created_at
+FROM devices
+ORDER BY registered_at IS NULL, id;
+
+-- name: RegisterDevice :one
+INSERT INTO devices (id, name, registered_at, created_at)
+VALUES ($1, $2, NOW(), NOW())
+ON CONFLICT (id) DO UPDATE
+SET name = EXCLUDED.name,
+    registered_at = COALESCE(devices.registered_at, EXCLUDED.registered_at)
+RETURNING id, name, clock_offset_ms, clock_boot_id, clock_synced_at, registered_at, status, status_changed_at, last_heartbeat_at, firmware_version, uptime_seconds, wifi_rssi, sensor_healthy, sensor_error, created_at;
+
+-- name: RecordDeviceHeartbeat :one
+WITH previous AS (
+    SELECT id, status
+    FROM devices
+    WHERE id = $1 AND registered_at IS NOT NULL
+    FOR UPDATE
+)
+UPDATE devices d
+SET firmware_version = $2,
+    uptime_seconds = $3,
+    wifi_rssi = $4,
+    sensor_healthy = $5,
+    sensor_error = $6,
+    last_heartbeat_at = NOW(),
+    status = 'online',
+    status_changed_at = CASE WHEN previous.status = 'online' THEN d.status_changed_at ELSE NOW() END
+FROM previous
+WHERE d.id = previous.id
+RETURNING d.id, d.name, d.clock_offset_ms, d.clock_boot_id, d.clock_synced_at, d.registered_at, d.status, d.status_changed_at, d.last_heartbeat_at, d.firmware_version, d.uptime_seconds, d.wifi_rssi, d.sensor_healthy, d.sensor_error, d.created_at, previous.status as previous_status;
+
+-- name: MarkDevicesOffline :many
+UPDATE devices
+SET status = 'offline', status_changed_at = NOW()
+WHERE status = 'online' AND last_heartbeat_at < $1
+RETURNING id, name, clock_offset_ms, clock_boot_id, clock_synced_at, registered_at, status, status_changed_at, last_heartbeat_at, firmware_version, uptime_seconds, wifi_rssi, sensor_healthy, sensor_error, created_at;
+
+-- name: GetDeviceConfig :one
+SELECT device_id, version, config, updated_by, updated_at, applied_version, applied_at
+FROM device_configs
+WHERE device_id = $1;
+
+-- name: SaveDeviceConfig :one
+INSERT INTO device_configs (device_id, version, config, updated_by, updated_at)
+VALUES ($1, 1, $2, $3, NOW())
+ON CONFLICT (device_id) DO UPDATE
+SET version = device_configs.version + 1,
+    config = EXCLUDED.config,
+    updated_by = EXCLUDED.updated_by,
+    updated_at = EXCLUDED.updated_at
+RETURNING device_id, version, config, updated_by, updated_at, applied_version, applied_at;
+
+-- name: CreateDeviceConfigRevision :exec
+INSERT INTO device_config_revisions (device_id, version, config, changed_by, created_at)
+VALUES ($1, $2, $3, $4, NOW());
+
+-- name: GetDeviceConfigRevisions :many
+SELECT id, device_id, version, config, changed_by, created_at
+FROM device_config_revisions
+WHERE device_id = $1
+ORDER BY version DESC;
+
+-- name: AcknowledgeDeviceConfig :one
+UPDATE device_configs
+SET applied_version = $2, applied_at = NOW()
+WHERE device_id = $1 AND version >= $2
+RETURNING device_id, version, config, updated_by, updated_at, applied_version, applied_at;
+
+-- name: CreateSeason :one
+INSERT INTO seasons (name, starts_at, ends_at, created_at)
+VALUES ($1, $2, $3, NOW())
+RETURNING id, name, starts_at, ends_at, closed_at, created_at;
+
+-- name: UpdateSeason :one
+UPDATE seasons
+SET name = $2, starts_at = $3, ends_at = $4
+WHERE id = $1 AND closed_at IS NULL
+RETURNING id, name, starts_at, ends_at, closed_at, created_at;
+
+-- name: GetSeason :one
+SELECT id, name, starts_at, ends_at, closed_at, created_at
+FROM seasons
+WHERE id = $1;
+
+-- name: GetCurrentSeason :one
+SELECT id, name, starts_at, ends_at, closed_at, created_at
+FROM seasons
+WHERE starts_at <= sqlc.arg(at) AND sqlc.arg(at) < ends_at
+ORDER BY starts_at DESC
+LIMIT 1;
+
+-- name: ListSeasons :many
+SELECT id, name, starts_at, ends_at, closed_at, created_at
+FROM seasons
+ORDER BY starts_at DESC;
+
+-- name: GetSeasonsToClose :many
+SELECT id, name, starts_at, ends_at, closed_at, created_at
+FROM seasons
+WHERE closed_at IS NULL AND ends_at <= $1
+ORDER BY ends_at;
+
+-- name: CloseSeason :execrows
+UPDATE seasons
+SET closed_at = NOW()
+WHERE id = $1 AND closed_at IS NULL;
+
+-- name: CreateSeasonStanding :exec
+INSERT INTO season_standings (season_id, metric, rank, user_id, user_name, user_username, run_id, score, recorded_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);
+
+-- name: GetSeasonStandings :many
+SELECT season_id, metric, rank, user_id, user_name, user_username, run_id, score, recorded_at
+FROM season_standings
+WHERE season_id = $1 AND metric = $2
+ORDER BY rank, user_username;
+
+-- name: GetUserChampionships :many
+SELECT
+    s.id as season_id,
+    s.name as season_name,
+    s.ends_at as season_ends_at,
+    ss.metric,
+    ss.score,
+    ss.run_id
+FROM season_standings ss
+JOIN seasons s ON ss.season_id = s.id
+WHERE ss.user_id = $1 AND ss.rank = 1
+ORDER BY s.ends_at DESC, ss.metric;
+
+-- name: CreateScoringFormula :one
+INSERT INTO scoring_formulas (name, expression, description, created_at, updated_at)
+VALUES ($1, $2, $3, NOW(), NOW())
+RETURNING name, expression, description, created_at, updated_at;
+
+-- name: UpdateScoringFormula :one
+UPDATE scoring_formulas
+SET expression = $2, description = $3, updated_at = NOW()
+WHERE name = $1
+RETURNING name, expression, description, created_at, updated_at;
+
+-- name: DeleteScoringFormula :execrows
+DELETE FROM scoring_formulas
+WHERE name = $1;
+
+-- name: GetScoringFormula :one
+SELECT name, expression, description, created_at, updated_at
+FROM scoring_formulas
+WHERE name = $1;
+
+-- name: ListScoringFormulas :many
+SELECT name, expression, description, created_at, updated_at
+FROM scoring_formulas
+ORDER BY name;
+
+-- name: GetRunDataForScoring :many
+SELECT id, data
+FROM runs;
+
+-- name: SaveRunScore :exec
+INSERT INTO run_scores (run_id, formula, score)
+VALUES ($1, $2, $3)
+ON CONFLICT (run_id, formula) DO UPDATE SET score = EXCLUDED.score;
+
+-- name: DeleteRunScore :exec
+DELETE FROM run_scores
+WHERE run_id = $1 AND formula = $2;
+
+-- name: DeleteFormulaScores :exec
+DELETE FROM run_scores
+WHERE formula = $1;
+
 -- name: GetUserById :one
 SELECT id, name, username 
 FROM "user" 
 WHERE id = $1;
 
+-- name: GetUserProfile :one
+SELECT id, name, username, display_username, image, created_at
+FROM "user"
+WHERE id = $1;
+
+-- name: GetUserRunStats :one
+SELECT
+    COUNT(*) as run_count,
+    COALESCE(MAX((data->>'rate')::float), 0)::float as best_rate,
+    COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY (data->>'rate')::float), 0)::float as median_rate,
+    COALESCE(AVG((data->>'rate')::float), 0)::float as mean_rate,
+    COALESCE(MIN((data->>'duration')::float), 0)::float as fastest_duration,
+    COALESCE(SUM((data->>'volume')::float), 0)::float as total_volume,
+    MIN(created_at)::timestamp as first_run_at,
+    MAX(created_at)::timestamp as last_run_at
+FROM runs
+WHERE user_id = $1 AND deleted_at IS NULL AND review_status = 'approved';
+
+-- name: GetUserRank :one
+WITH best AS (
+    SELECT user_id, MAX((data->>'rate')::float) as best_rate
+    FROM runs
+    WHERE deleted_at IS NULL AND review_status = 'approved' AND user_id IS NOT NULL
+    GROUP BY user_id
+)
+SELECT (COUNT(*) + 1)::bigint as rank
+FROM best
+WHERE best_rate > (SELECT b.best_rate FROM best b WHERE b.user_id = $1);
+
+-- name: GetRunTimesForUser :many
+SELECT created_at
+FROM runs
+WHERE user_id = $1 AND deleted_at IS NULL AND review_status = 'approved'
+ORDER BY created_at;
+
+-- name: GetUserIdsWithRuns :many
+SELECT DISTINCT user_id
+FROM runs
+WHERE user_id IS NOT NULL AND deleted_at IS NULL;
+
+-- name: AwardAchievement :execrows
+INSERT INTO user_achievements (user_id, achievement, run_id, achieved_at, created_at)
+VALUES ($1, $2, $3, $4, NOW())
+ON CONFLICT (user_id, achievement) DO NOTHING;
+
+-- name: GetUserAchievements :many
+SELECT user_id, achievement, run_id, achieved_at, created_at
+FROM user_achievements
+WHERE user_id = $1
+ORDER BY achieved_at;
+
 -- name: SearchUsersByName :many
 SELECT id, name, username, display_username
 FROM "user"
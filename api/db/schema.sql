@@ -59,11 +59,327 @@ CREATE TABLE "runs" (
 	"user_id" text,
 	"data" jsonb NOT NULL,
 	"created_at" timestamp NOT NULL,
-	"image" text NOT NULL
+	"image" text NOT NULL,
+	"deleted_at" timestamp,
+	"personal_bests" text[] DEFAULT '{}' NOT NULL,
+	"records" text[] DEFAULT '{}' NOT NULL,
+	"device_id" text,
+	"event_id" uuid,
+	"division_id" uuid,
+	"review_status" text DEFAULT 'approved' NOT NULL,
+	"anomaly_score" double precision DEFAULT 0 NOT NULL,
+	"review_reasons" text[] DEFAULT '{}' NOT NULL
+);
+
+CREATE TABLE "devices" (
+	"id" text PRIMARY KEY NOT NULL,
+	"name" text,
+	"clock_offset_ms" bigint,
+	"clock_boot_id" text,
+	"clock_synced_at" timestamp,
+	"registered_at" timestamp,
+	"status" text DEFAULT 'offline' NOT NULL,
+	"status_changed_at" timestamp,
+	"last_heartbeat_at" timestamp,
+	"firmware_version" text,
+	"uptime_seconds" bigint,
+	"wifi_rssi" integer,
+	"sensor_healthy" boolean,
+	"sensor_error" text,
+	"created_at" timestamp NOT NULL
+);
+
+CREATE TABLE "device_configs" (
+	"device_id" text PRIMARY KEY NOT NULL,
+	"version" integer NOT NULL,
+	"config" jsonb NOT NULL,
+	"updated_by" text NOT NULL,
+	"updated_at" timestamp NOT NULL,
+	"applied_version" integer,
+	"applied_at" timestamp
+);
+
+CREATE TABLE "device_config_revisions" (
+	"id" uuid PRIMARY KEY DEFAULT gen_random_uuid() NOT NULL,
+	"device_id" text NOT NULL,
+	"version" integer NOT NULL,
+	"config" jsonb NOT NULL,
+	"changed_by" text NOT NULL,
+	"created_at" timestamp NOT NULL
+);
+
+CREATE TABLE "events" (
+	"id" uuid PRIMARY KEY DEFAULT gen_random_uuid() NOT NULL,
+	"name" text NOT NULL,
+	"venue" text,
+	"starts_at" timestamp NOT NULL,
+	"ends_at" timestamp,
+	"device_ids" text[] DEFAULT '{}' NOT NULL,
+	"created_at" timestamp NOT NULL
+);
+
+CREATE TABLE "divisions" (
+	"id" uuid PRIMARY KEY DEFAULT gen_random_uuid() NOT NULL,
+	"name" text NOT NULL,
+	"volume" double precision NOT NULL,
+	"tolerance" double precision NOT NULL,
+	"handicap" double precision DEFAULT 1 NOT NULL,
+	"created_at" timestamp NOT NULL
+);
+
+CREATE TABLE "duels" (
+	"id" uuid PRIMARY KEY DEFAULT gen_random_uuid() NOT NULL,
+	"metric" text NOT NULL,
+	"device_a" text NOT NULL,
+	"device_b" text NOT NULL,
+	"run_a" uuid,
+	"run_b" uuid,
+	"winner_run_id" uuid,
+	"status" text NOT NULL,
+	"started_at" timestamp NOT NULL,
+	"finished_at" timestamp
+);
+
+CREATE TABLE "tournaments" (
+	"id" uuid PRIMARY KEY DEFAULT gen_random_uuid() NOT NULL,
+	"name" text NOT NULL,
+	"format" text NOT NULL,
+	"metric" text NOT NULL,
+	"status" text NOT NULL,
+	"winner_user_id" text,
+	"started_at" timestamp,
+	"finished_at" timestamp,
+	"created_at" timestamp NOT NULL
+);
+
+CREATE TABLE "tournament_participants" (
+	"tournament_id" uuid NOT NULL,
+	"user_id" text NOT NULL,
+	"seed" integer,
+	"created_at" timestamp NOT NULL,
+	CONSTRAINT "tournament_participants_tournament_id_user_id_pk" PRIMARY KEY("tournament_id","user_id")
+);
+
+CREATE TABLE "tournament_matches" (
+	"id" uuid PRIMARY KEY DEFAULT gen_random_uuid() NOT NULL,
+	"tournament_id" uuid NOT NULL,
+	"bracket" text NOT NULL,
+	"round" integer NOT NULL,
+	"position" integer NOT NULL,
+	"player_a" text,
+	"player_b" text,
+	"winner_user_id" text,
+	"status" text NOT NULL,
+	"duel_id" uuid,
+	"run_a" uuid,
+	"run_b" uuid,
+	"finished_at" timestamp,
+	CONSTRAINT "tournament_matches_tournament_id_bracket_round_position_unique" UNIQUE("tournament_id","bracket","round","position")
+);
+
+CREATE TABLE "user_ratings" (
+	"user_id" text PRIMARY KEY NOT NULL,
+	"rating" double precision NOT NULL,
+	"games" integer NOT NULL,
+	"last_played_at" timestamp NOT NULL,
+	"updated_at" timestamp NOT NULL
+);
+
+CREATE TABLE "rating_history" (
+	"id" uuid PRIMARY KEY DEFAULT gen_random_uuid() NOT NULL,
+	"user_id" text NOT NULL,
+	"opponent_id" text NOT NULL,
+	"source" text NOT NULL,
+	"source_id" uuid NOT NULL,
+	"score" double precision NOT NULL,
+	"rating_before" double precision NOT NULL,
+	"rating_after" double precision NOT NULL,
+	"played_at" timestamp NOT NULL
+);
+
+CREATE TABLE "teams" (
+	"id" uuid PRIMARY KEY DEFAULT gen_random_uuid() NOT NULL,
+	"name" text NOT NULL,
+	"description" text,
+	"created_at" timestamp NOT NULL,
+	CONSTRAINT "teams_name_unique" UNIQUE("name")
+);
+
+CREATE TABLE "team_members" (
+	"team_id" uuid NOT NULL,
+	"user_id" text NOT NULL,
+	"role" text NOT NULL,
+	"joined_at" timestamp NOT NULL,
+	CONSTRAINT "team_members_team_id_user_id_pk" PRIMARY KEY("team_id","user_id")
+);
+
+CREATE TABLE "team_invitations" (
+	"id" uuid PRIMARY KEY DEFAULT gen_random_uuid() NOT NULL,
+	"team_id" uuid NOT NULL,
+	"user_id" text NOT NULL,
+	"invited_by" text,
+	"status" text NOT NULL,
+	"created_at" timestamp NOT NULL,
+	"responded_at" timestamp
+);
+
+CREATE TABLE "run_samples" (
+	"run_id" uuid PRIMARY KEY NOT NULL,
+	"sample_count" integer NOT NULL,
+	"data" bytea NOT NULL,
+	"metrics" jsonb,
+	"reported" jsonb,
+	"verdict" text,
+	"created_at" timestamp NOT NULL
+);
+
+CREATE TABLE "run_client_ids" (
+	"client_id" text PRIMARY KEY NOT NULL,
+	"run_id" uuid NOT NULL
+);
+
+CREATE TABLE "run_reports" (
+	"id" uuid PRIMARY KEY DEFAULT gen_random_uuid() NOT NULL,
+	"run_id" uuid NOT NULL,
+	"user_id" text NOT NULL,
+	"reason" text,
+	"created_at" timestamp NOT NULL
+);
+
+CREATE TABLE "run_reviews" (
+	"id" uuid PRIMARY KEY DEFAULT gen_random_uuid() NOT NULL,
+	"run_id" uuid NOT NULL,
+	"status" text NOT NULL,
+	"reviewer" text NOT NULL,
+	"reason" text,
+	"created_at" timestamp NOT NULL
+);
+
+CREATE TABLE "idempotency_keys" (
+	"key" text NOT NULL,
+	"route" text NOT NULL,
+	"fingerprint" text NOT NULL,
+	"status_code" integer,
+	"content_type" text,
+	"response" bytea,
+	"created_at" timestamp NOT NULL,
+	CONSTRAINT "idempotency_keys_key_route_pk" PRIMARY KEY("key","route")
+);
+
+CREATE TABLE "run_revisions" (
+	"id" uuid PRIMARY KEY DEFAULT gen_random_uuid() NOT NULL,
+	"run_id" uuid NOT NULL,
+	"data" jsonb NOT NULL,
+	"reason" text NOT NULL,
+	"edited_by" text NOT NULL,
+	"created_at" timestamp NOT NULL
+);
+
+CREATE TABLE "user_achievements" (
+	"user_id" text NOT NULL,
+	"achievement" text NOT NULL,
+	"run_id" uuid,
+	"achieved_at" timestamp NOT NULL,
+	"created_at" timestamp NOT NULL,
+	CONSTRAINT "user_achievements_user_id_achievement_pk" PRIMARY KEY("user_id","achievement")
+);
+
+CREATE TABLE "seasons" (
+	"id" uuid PRIMARY KEY DEFAULT gen_random_uuid() NOT NULL,
+	"name" text NOT NULL,
+	"starts_at" timestamp NOT NULL,
+	"ends_at" timestamp NOT NULL,
+	"closed_at" timestamp,
+	"created_at" timestamp NOT NULL
+);
+
+CREATE TABLE "season_standings" (
+	"season_id" uuid NOT NULL,
+	"metric" text NOT NULL,
+	"rank" integer NOT NULL,
+	"user_id" text NOT NULL,
+	"user_name" text NOT NULL,
+	"user_username" text NOT NULL,
+	"run_id" uuid NOT NULL,
+	"score" double precision NOT NULL,
+	"recorded_at" timestamp NOT NULL,
+	CONSTRAINT "season_standings_season_id_metric_user_id_pk" PRIMARY KEY("season_id","metric","user_id")
+);
+
+CREATE TABLE "scoring_formulas" (
+	"name" text PRIMARY KEY NOT NULL,
+	"expression" text NOT NULL,
+	"description" text,
+	"created_at" timestamp NOT NULL,
+	"updated_at" timestamp NOT NULL
+);
+
+CREATE TABLE "run_scores" (
+	"run_id" uuid NOT NULL,
+	"formula" text NOT NULL,
+	"score" double precision NOT NULL,
+	CONSTRAINT "run_scores_run_id_formula_pk" PRIMARY KEY("run_id","formula")
 );
 
 ALTER TABLE "account" ADD CONSTRAINT "account_user_id_user_id_fk" FOREIGN KEY ("user_id") REFERENCES "public"."user"("id") ON DELETE cascade ON UPDATE no action;
 ALTER TABLE "session" ADD CONSTRAINT "session_user_id_user_id_fk" FOREIGN KEY ("user_id") REFERENCES "public"."user"("id") ON DELETE cascade ON UPDATE no action;
 ALTER TABLE "runs" ADD CONSTRAINT "runs_user_id_user_id_fk" FOREIGN KEY ("user_id") REFERENCES "public"."user"("id") ON DELETE cascade ON UPDATE no action;
+ALTER TABLE "user_achievements" ADD CONSTRAINT "user_achievements_user_id_user_id_fk" FOREIGN KEY ("user_id") REFERENCES "public"."user"("id") ON DELETE cascade ON UPDATE no action;
+ALTER TABLE "user_achievements" ADD CONSTRAINT "user_achievements_run_id_runs_id_fk" FOREIGN KEY ("run_id") REFERENCES "public"."runs"("id") ON DELETE set null ON UPDATE no action;
+ALTER TABLE "runs" ADD CONSTRAINT "runs_event_id_events_id_fk" FOREIGN KEY ("event_id") REFERENCES "public"."events"("id") ON DELETE set null ON UPDATE no action;
+ALTER TABLE "runs" ADD CONSTRAINT "runs_division_id_divisions_id_fk" FOREIGN KEY ("division_id") REFERENCES "public"."divisions"("id") ON DELETE set null ON UPDATE no action;
+ALTER TABLE "duels" ADD CONSTRAINT "duels_run_a_runs_id_fk" FOREIGN KEY ("run_a") REFERENCES "public"."runs"("id") ON DELETE set null ON UPDATE no action;
+ALTER TABLE "duels" ADD CONSTRAINT "duels_run_b_runs_id_fk" FOREIGN KEY ("run_b") REFERENCES "public"."runs"("id") ON DELETE set null ON UPDATE no action;
+ALTER TABLE "duels" ADD CONSTRAINT "duels_winner_run_id_runs_id_fk" FOREIGN KEY ("winner_run_id") REFERENCES "public"."runs"("id") ON DELETE set null ON UPDATE no action;
+ALTER TABLE "tournament_participants" ADD CONSTRAINT "tournament_participants_tournament_id_tournaments_id_fk" FOREIGN KEY ("tournament_id") REFERENCES "public"."tournaments"("id") ON DELETE cascade ON UPDATE no action;
+ALTER TABLE "tournament_participants" ADD CONSTRAINT "tournament_participants_user_id_user_id_fk" FOREIGN KEY ("user_id") REFERENCES "public"."user"("id") ON DELETE cascade ON UPDATE no action;
+ALTER TABLE "tournament_matches" ADD CONSTRAINT "tournament_matches_tournament_id_tournaments_id_fk" FOREIGN KEY ("tournament_id") REFERENCES "public"."tournaments"("id") ON DELETE cascade ON UPDATE no action;
+ALTER TABLE "tournament_matches" ADD CONSTRAINT "tournament_matches_duel_id_duels_id_fk" FOREIGN KEY ("duel_id") REFERENCES "public"."duels"("id") ON DELETE set null ON UPDATE no action;
+ALTER TABLE "user_ratings" ADD CONSTRAINT "user_ratings_user_id_user_id_fk" FOREIGN KEY ("user_id") REFERENCES "public"."user"("id") ON DELETE cascade ON UPDATE no action;
+ALTER TABLE "rating_history" ADD CONSTRAINT "rating_history_user_id_user_id_fk" FOREIGN KEY ("user_id") REFERENCES "public"."user"("id") ON DELETE cascade ON UPDATE no action;
+ALTER TABLE "team_members" ADD CONSTRAINT "team_members_team_id_teams_id_fk" FOREIGN KEY ("team_id") REFERENCES "public"."teams"("id") ON DELETE cascade ON UPDATE no action;
+ALTER TABLE "team_members" ADD CONSTRAINT "team_members_user_id_user_id_fk" FOREIGN KEY ("user_id") REFERENCES "public"."user"("id") ON DELETE cascade ON UPDATE no action;
+ALTER TABLE "team_invitations" ADD CONSTRAINT "team_invitations_team_id_teams_id_fk" FOREIGN KEY ("team_id") REFERENCES "public"."teams"("id") ON DELETE cascade ON UPDATE no action;
+ALTER TABLE "team_invitations" ADD CONSTRAINT "team_invitations_user_id_user_id_fk" FOREIGN KEY ("user_id") REFERENCES "public"."user"("id") ON DELETE cascade ON UPDATE no action;
+ALTER TABLE "team_invitations" ADD CONSTRAINT "team_invitations_invited_by_user_id_fk" FOREIGN KEY ("invited_by") REFERENCES "public"."user"("id") ON DELETE set null ON UPDATE no action;
+ALTER TABLE "run_samples" ADD CONSTRAINT "run_samples_run_id_runs_id_fk" FOREIGN KEY ("run_id") REFERENCES "public"."runs"("id") ON DELETE cascade ON UPDATE no action;
+ALTER TABLE "device_configs" ADD CONSTRAINT "device_configs_device_id_devices_id_fk" FOREIGN KEY ("device_id") REFERENCES "public"."devices"("id") ON DELETE cascade ON UPDATE no action;
+ALTER TABLE "device_config_revisions" ADD CONSTRAINT "device_config_revisions_device_id_devices_id_fk" FOREIGN KEY ("device_id") REFERENCES "public"."devices"("id") ON DELETE cascade ON UPDATE no action;
+ALTER TABLE "season_standings" ADD CONSTRAINT "season_standings_season_id_seasons_id_fk" FOREIGN KEY ("season_id") REFERENCES "public"."seasons"("id") ON DELETE restrict ON UPDATE no action;
+ALTER TABLE "run_client_ids" ADD CONSTRAINT "run_client_ids_run_id_runs_id_fk" FOREIGN KEY ("run_id") REFERENCES "public"."runs"("id") ON DELETE cascade ON UPDATE no action;
+ALTER TABLE "run_reports" ADD CONSTRAINT "run_reports_run_id_runs_id_fk" FOREIGN KEY ("run_id") REFERENCES "public"."runs"("id") ON DELETE cascade ON UPDATE no action;
+ALTER TABLE "run_reports" ADD CONSTRAINT "run_reports_user_id_user_id_fk" FOREIGN KEY ("user_id") REFERENCES "public"."user"("id") ON DELETE cascade ON UPDATE no action;
+ALTER TABLE "run_reviews" ADD CONSTRAINT "run_reviews_run_id_runs_id_fk" FOREIGN KEY ("run_id") REFERENCES "public"."runs"("id") ON DELETE cascade ON UPDATE no action;
+ALTER TABLE "run_revisions" ADD CONSTRAINT "run_revisions_run_id_runs_id_fk" FOREIGN KEY ("run_id") REFERENCES "public"."runs"("id") ON DELETE cascade ON UPDATE no action;
+ALTER TABLE "run_scores" ADD CONSTRAINT "run_scores_run_id_runs_id_fk" FOREIGN KEY ("run_id") REFERENCES "public"."runs"("id") ON DELETE cascade ON UPDATE no action;
+ALTER TABLE "run_scores" ADD CONSTRAINT "run_scores_formula_scoring_formulas_name_fk" FOREIGN KEY ("formula") REFERENCES "public"."scoring_formulas"("name") ON DELETE cascade ON UPDATE no action;
 CREATE INDEX "user_username_idkx" ON "user" USING btree ("username");
 CREATE INDEX "user_display_username_idkx" ON "user" USING btree ("display_username");
+CREATE INDEX "runs_deleted_at_idx" ON "runs" USING btree ("deleted_at");
+CREATE UNIQUE INDEX "run_reports_run_id_user_id_idx" ON "run_reports" USING btree ("run_id","user_id");
+CREATE INDEX "run_reviews_run_id_idx" ON "run_reviews" USING btree ("run_id","created_at");
+CREATE INDEX "run_revisions_run_id_idx" ON "run_revisions" USING btree ("run_id","created_at");
+CREATE INDEX "runs_rate_idx" ON "runs" USING btree (((data->>'rate')::float) DESC,"id" DESC) WHERE "deleted_at" IS NULL;
+CREATE INDEX "runs_duration_idx" ON "runs" USING btree (((data->>'duration')::float) DESC,"id" DESC) WHERE "deleted_at" IS NULL;
+CREATE INDEX "runs_volume_idx" ON "runs" USING btree (((data->>'volume')::float) DESC,"id" DESC) WHERE "deleted_at" IS NULL;
+CREATE INDEX "runs_created_at_idx" ON "runs" USING btree ("created_at" DESC,"id" DESC) WHERE "deleted_at" IS NULL;
+CREATE INDEX "runs_user_id_created_at_idx" ON "runs" USING btree ("user_id","created_at" DESC) WHERE "deleted_at" IS NULL;
+CREATE INDEX "runs_event_id_idx" ON "runs" USING btree ("event_id") WHERE "deleted_at" IS NULL;
+CREATE INDEX "runs_division_id_idx" ON "runs" USING btree ("division_id") WHERE "deleted_at" IS NULL;
+CREATE INDEX "runs_pending_review_idx" ON "runs" USING btree ("created_at") WHERE "review_status" = 'pending' AND "deleted_at" IS NULL;
+CREATE INDEX "duels_status_idx" ON "duels" USING btree ("status","started_at" DESC);
+CREATE INDEX "duels_run_a_idx" ON "duels" USING btree ("run_a");
+CREATE INDEX "duels_run_b_idx" ON "duels" USING btree ("run_b");
+CREATE INDEX "tournament_matches_players_idx" ON "tournament_matches" USING btree ("player_a","player_b") WHERE "status" = 'ready';
+CREATE INDEX "rating_history_user_id_idx" ON "rating_history" USING btree ("user_id","played_at" DESC);
+CREATE INDEX "team_members_user_id_idx" ON "team_members" USING btree ("user_id");
+CREATE UNIQUE INDEX "team_invitations_pending_idx" ON "team_invitations" USING btree ("team_id","user_id") WHERE "status" = 'pending';
+CREATE INDEX "team_invitations_user_id_idx" ON "team_invitations" USING btree ("user_id","status");
+CREATE INDEX "run_samples_verdict_idx" ON "run_samples" USING btree ("verdict");
+CREATE INDEX "idempotency_keys_created_at_idx" ON "idempotency_keys" USING btree ("created_at");
+CREATE INDEX "devices_online_idx" ON "devices" USING btree ("last_heartbeat_at") WHERE "status" = 'online';
+CREATE UNIQUE INDEX "device_config_revisions_device_id_version_idx" ON "device_config_revisions" USING btree ("device_id","version");
+CREATE INDEX "events_starts_at_idx" ON "events" USING btree ("starts_at");
+CREATE INDEX "seasons_starts_at_idx" ON "seasons" USING btree ("starts_at","ends_at");
+CREATE INDEX "season_standings_user_id_idx" ON "season_standings" USING btree ("user_id","rank");
+CREATE INDEX "run_scores_formula_score_idx" ON "run_scores" USING btree ("formula","score" DESC);
@@ -0,0 +1,269 @@
+// Hand-maintained, not generated by sqlc: the ranking column depends on the
+// requested metric. Keep it in step with db/query.sql when the runs table
+// changes.
+
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// LeaderboardMetric is a run field a leaderboard can be ranked by.
+type LeaderboardMetric string
+
+const (
+	LeaderboardMetricRate     LeaderboardMetric = "rate"
+	LeaderboardMetricDuration LeaderboardMetric = "duration"
+	LeaderboardMetricVolume   LeaderboardMetric = "volume"
+)
+
+var LeaderboardMetrics = []LeaderboardMetric{
+	LeaderboardMetricRate,
+	LeaderboardMetricDuration,
+	LeaderboardMetricVolume,
+}
+
+type leaderboardColumn struct {
+	expr      string
+	direction string
+}
+
+// A higher rate or volume is better, a shorter duration is better.
+var leaderboardColumns = map[LeaderboardMetric]leaderboardColumn{
+	LeaderboardMetricRate:     {expr: "(r.data->>'rate')::float", direction: "DESC"},
+	LeaderboardMetricDuration: {expr: "(r.data->>'duration')::float", direction: "ASC"},
+	LeaderboardMetricVolume:   {expr: "(r.data->>'volume')::float", direction: "DESC"},
+}
+
+func (m LeaderboardMetric) Valid() bool {
+	_, ok := leaderboardColumns[m]
+	return ok
+}
+
+type GetLeaderboardParams struct {
+	Metric LeaderboardMetric
+	// Formula ranks by the cached run_scores of a scoring formula instead of
+	// Metric. Higher scores are better.
+	Formula string
+	From    pgtype.Timestamp
+	To      pgtype.Timestamp
+	EventID pgtype.UUID
+	// DivisionID limits the board to runs classified into one division.
+	DivisionID pgtype.UUID
+	// Handicap scales each score by its division's handicap so runs from
+	// different divisions compare fairly. Multiplying a better-is-higher
+	// score and dividing a better-is-lower one means a handicap above 1
+	// always helps. Unclassified runs keep their raw score.
+	Handicap bool
+	// BestPerUser keeps only each user's best run. Unassigned runs are
+	// ranked individually unless RequireUser excludes them.
+	BestPerUser bool
+	RequireUser bool
+	Limit       int32
+}
+
+type GetLeaderboardRow struct {
+	ID           pgtype.UUID      `json:"id"`
+	UserID       pgtype.Text      `json:"userId"`
+	Data         []byte           `json:"data"`
+	Image        string           `json:"image"`
+	CreatedAt    pgtype.Timestamp `json:"createdAt"`
+	UserIDFull   pgtype.Text      `json:"userIdFull"`
+	UserName     pgtype.Text      `json:"userName"`
+	UserUsername pgtype.Text      `json:"userUsername"`
+	Score        float64          `json:"score"`
+	Rank         int64            `json:"rank"`
+}
+
+// GetLeaderboard ranks the approved, non-deleted runs inside a time window.
+// Like ListRunsWithUsers the ranking column is picked at runtime from a
+// whitelist.
+func (q *Queries) GetLeaderboard(ctx context.Context, arg GetLeaderboardParams) ([]GetLeaderboardRow, error) {
+	column, ok := leaderboardColumns[arg.Metric]
+	if !ok {
+		return nil, fmt.Errorf("unsupported leaderboard metric %q", arg.Metric)
+	}
+
+	conditions := []string{"r.deleted_at IS NULL", "r.review_status = 'approved'"}
+	var args []interface{}
+	join := ""
+	if arg.Formula != "" {
+		args = append(args, arg.Formula)
+		column = leaderboardColumn{expr: "rs.score", direction: "DESC"}
+		join = fmt.Sprintf("\n    JOIN run_scores rs ON rs.run_id = r.id AND rs.formula = $%d", len(args))
+	}
+	if arg.Handicap {
+		operator := "*"
+		if column.direction == "ASC" {
+			operator = "/"
+		}
+		column.expr = fmt.Sprintf("(%s %s COALESCE(d.handicap, 1))", column.expr, operator)
+		join += "\n    LEFT JOIN divisions d ON d.id = r.division_id"
+	}
+	if arg.From.Valid {
+		args = append(args, arg.From)
+		conditions = append(conditions, fmt.Sprintf("r.created_at >= $%d", len(args)))
+	}
+	if arg.To.Valid {
+		args = append(args, arg.To)
+		conditions = append(conditions, fmt.Sprintf("r.created_at < $%d", len(args)))
+	}
+	if arg.EventID.Valid {
+		args = append(args, arg.EventID)
+		conditions = append(conditions, fmt.Sprintf("r.event_id = $%d", len(args)))
+	}
+	if arg.DivisionID.Valid {
+		args = append(args, arg.DivisionID)
+		conditions = append(conditions, fmt.Sprintf("r.division_id = $%d", len(args)))
+	}
+	if arg.RequireUser {
+		conditions = append(conditions, "r.user_id IS NOT NULL")
+	}
+	args = append(args, arg.Limit)
+
+	distinct, order := "", ""
+	if arg.BestPerUser {
+		distinct = "DISTINCT ON (COALESCE(r.user_id, r.id::text))"
+		order = fmt.Sprintf("\n    ORDER BY COALESCE(r.user_id, r.id::text), %s %s, r.created_at ASC", column.expr, column.direction)
+	}
+
+	query := fmt.Sprintf(`WITH candidates AS (
+    SELECT %s
+        r.id,
+        r.user_id,
+        r.data,
+        r.image,
+        r.created_at,
+        %s as score
+    FROM runs r%s
+    WHERE %s%s
+)
+SELECT
+    c.id,
+    c.user_id,
+    c.data,
+    c.image,
+    c.created_at,
+    u.id as user_id_full,
+    u.name as user_name,
+    u.username as user_username,
+    c.score,
+    RANK() OVER (ORDER BY c.score %s) as rank
+FROM candidates c
+LEFT JOIN "user" u ON c.user_id = u.id
+ORDER BY c.score %s, c.created_at ASC
+LIMIT $%d`,
+		distinct,
+		column.expr,
+		join,
+		strings.Join(conditions, " AND "),
+		order,
+		column.direction,
+		column.direction,
+		len(args),
+	)
+
+	rows, err := q.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetLeaderboardRow
+	for rows.Next() {
+		var i GetLeaderboardRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Data,
+			&i.Image,
+			&i.CreatedAt,
+			&i.UserIDFull,
+			&i.UserName,
+			&i.UserUsername,
+			&i.Score,
+			&i.Rank,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+type GetPreviousBestParams struct {
+	Metric LeaderboardMetric
+	// UserID limits the search to one user's runs; leave it invalid to
+	// search all runs.
+	UserID    pgtype.Text
+	Before    pgtype.Timestamp
+	ExcludeID pgtype.UUID
+}
+
+type GetPreviousBestRow struct {
+	ID           pgtype.UUID `json:"id"`
+	UserIDFull   pgtype.Text `json:"userIdFull"`
+	UserName     pgtype.Text `json:"userName"`
+	UserUsername pgtype.Text `json:"userUsername"`
+	Value        float64     `json:"value"`
+}
+
+// GetPreviousBest returns the best approved, non-deleted run for a metric
+// recorded before the given time. It returns pgx.ErrNoRows when there is none.
+func (q *Queries) GetPreviousBest(ctx context.Context, arg GetPreviousBestParams) (GetPreviousBestRow, error) {
+	var i GetPreviousBestRow
+	column, ok := leaderboardColumns[arg.Metric]
+	if !ok {
+		return i, fmt.Errorf("unsupported leaderboard metric %q", arg.Metric)
+	}
+
+	args := []interface{}{arg.Before, arg.ExcludeID}
+	userCondition := ""
+	if arg.UserID.Valid {
+		args = append(args, arg.UserID)
+		userCondition = "\n  AND r.user_id = $3"
+	}
+
+	query := fmt.Sprintf(`SELECT
+    r.id,
+    u.id as user_id_full,
+    u.name as user_name,
+    u.username as user_username,
+    %s as value
+FROM runs r
+LEFT JOIN "user" u ON r.user_id = u.id
+WHERE r.deleted_at IS NULL
+  AND r.review_status = 'approved'
+  AND r.created_at <= $1
+  AND r.id <> $2%s
+ORDER BY value %s, r.created_at ASC
+LIMIT 1`,
+		column.expr,
+		userCondition,
+		column.direction,
+	)
+
+	row := q.db.QueryRow(ctx, query, args...)
+	err := row.Scan(
+		&i.ID,
+		&i.UserIDFull,
+		&i.UserName,
+		&i.UserUsername,
+		&i.Value,
+	)
+	return i, err
+}
+
+// Better reports whether value beats previous for the metric.
+func (m LeaderboardMetric) Better(value, previous float64) bool {
+	if leaderboardColumns[m].direction == "ASC" {
+		return value < previous
+	}
+	return value > previous
+}
@@ -0,0 +1,156 @@
+// Hand-maintained, not generated by sqlc: how a team's runs are aggregated
+// depends on the requested rule and metric.
+
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// TeamLeaderboardRule is how a team's runs are combined into one score.
+type TeamLeaderboardRule string
+
+const (
+	// TeamRuleBest averages the team's N best runs for the metric.
+	TeamRuleBest TeamLeaderboardRule = "best"
+	// TeamRuleAverage averages all of the team's runs for the metric.
+	TeamRuleAverage TeamLeaderboardRule = "average"
+	// TeamRuleTotal adds up the litres of all of the team's runs and ignores
+	// the metric.
+	TeamRuleTotal TeamLeaderboardRule = "total"
+)
+
+func (r TeamLeaderboardRule) Valid() bool {
+	switch r {
+	case TeamRuleBest, TeamRuleAverage, TeamRuleTotal:
+		return true
+	}
+	return false
+}
+
+type GetTeamLeaderboardParams struct {
+	Rule   TeamLeaderboardRule
+	Metric LeaderboardMetric
+	// BestN is the number of runs TeamRuleBest takes into account.
+	BestN      int32
+	From       pgtype.Timestamp
+	To         pgtype.Timestamp
+	DivisionID pgtype.UUID
+	Limit      int32
+}
+
+type GetTeamLeaderboardRow struct {
+	ID      pgtype.UUID `json:"id"`
+	Name    string      `json:"name"`
+	Score   float64     `json:"score"`
+	Runs    int64       `json:"runs"`
+	Members int64       `json:"members"`
+	Rank    int64       `json:"rank"`
+}
+
+// GetTeamLeaderboard ranks teams by their members' approved, non-deleted
+// runs. A run only counts for a team if it was recorded after its user joined
+// the team, so recruiting someone does not bring their history along.
+func (q *Queries) GetTeamLeaderboard(ctx context.Context, arg GetTeamLeaderboardParams) ([]GetTeamLeaderboardRow, error) {
+	column, ok := leaderboardColumns[arg.Metric]
+	if !ok {
+		return nil, fmt.Errorf("unsupported leaderboard metric %q", arg.Metric)
+	}
+
+	conditions := []string{"r.deleted_at IS NULL", "r.review_status = 'approved'", "r.created_at >= m.joined_at"}
+	var args []interface{}
+	if arg.From.Valid {
+		args = append(args, arg.From)
+		conditions = append(conditions, fmt.Sprintf("r.created_at >= $%d", len(args)))
+	}
+	if arg.To.Valid {
+		args = append(args, arg.To)
+		conditions = append(conditions, fmt.Sprintf("r.created_at < $%d", len(args)))
+	}
+	if arg.DivisionID.Valid {
+		args = append(args, arg.DivisionID)
+		conditions = append(conditions, fmt.Sprintf("r.division_id = $%d", len(args)))
+	}
+
+	aggregate, filter := "AVG(tr.value)", ""
+	switch arg.Rule {
+	case TeamRuleBest:
+		args = append(args, arg.BestN)
+		filter = fmt.Sprintf("\n    WHERE tr.position <= $%d", len(args))
+	case TeamRuleAverage:
+	case TeamRuleTotal:
+		column = leaderboardColumns[LeaderboardMetricVolume]
+		aggregate = "SUM(tr.value)"
+	default:
+		return nil, fmt.Errorf("unsupported team leaderboard rule %q", arg.Rule)
+	}
+	args = append(args, arg.Limit)
+
+	query := fmt.Sprintf(`WITH team_runs AS (
+    SELECT
+        m.team_id,
+        r.user_id,
+        %s as value,
+        ROW_NUMBER() OVER (PARTITION BY m.team_id ORDER BY %s %s, r.created_at ASC) as position
+    FROM team_members m
+    JOIN runs r ON r.user_id = m.user_id
+    WHERE %s
+), scores AS (
+    SELECT
+        tr.team_id,
+        %s as score,
+        COUNT(*) as runs,
+        COUNT(DISTINCT tr.user_id) as members
+    FROM team_runs tr%s
+    GROUP BY tr.team_id
+)
+SELECT
+    t.id,
+    t.name,
+    s.score,
+    s.runs,
+    s.members,
+    RANK() OVER (ORDER BY s.score %s) as rank
+FROM scores s
+JOIN teams t ON s.team_id = t.id
+ORDER BY s.score %s, t.name
+LIMIT $%d`,
+		column.expr,
+		column.expr, column.direction,
+		strings.Join(conditions, " AND "),
+		aggregate,
+		filter,
+		column.direction,
+		column.direction,
+		len(args),
+	)
+
+	rows, err := q.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetTeamLeaderboardRow
+	for rows.Next() {
+		var i GetTeamLeaderboardRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Score,
+			&i.Runs,
+			&i.Members,
+			&i.Rank,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -24,12 +24,183 @@ type Account struct {
 	UpdatedAt             pgtype.Timestamp `json:"updatedAt"`
 }
 
+type Device struct {
+	ID              string           `json:"id"`
+	Name            pgtype.Text      `json:"name"`
+	ClockOffsetMs   pgtype.Int8      `json:"clockOffsetMs"`
+	ClockBootID     pgtype.Text      `json:"clockBootId"`
+	ClockSyncedAt   pgtype.Timestamp `json:"clockSyncedAt"`
+	RegisteredAt    pgtype.Timestamp `json:"registeredAt"`
+	Status          string           `json:"status"`
+	StatusChangedAt pgtype.Timestamp `json:"statusChangedAt"`
+	LastHeartbeatAt pgtype.Timestamp `json:"lastHeartbeatAt"`
+	FirmwareVersion pgtype.Text      `json:"firmwareVersion"`
+	UptimeSeconds   pgtype.Int8      `json:"uptimeSeconds"`
+	WifiRssi        pgtype.Int4      `json:"wifiRssi"`
+	SensorHealthy   pgtype.Bool      `json:"sensorHealthy"`
+	SensorError     pgtype.Text      `json:"sensorError"`
+	CreatedAt       pgtype.Timestamp `json:"createdAt"`
+}
+
+type DeviceConfig struct {
+	DeviceID       string           `json:"deviceId"`
+	Version        int32            `json:"version"`
+	Config         []byte           `json:"config"`
+	UpdatedBy      string           `json:"updatedBy"`
+	UpdatedAt      pgtype.Timestamp `json:"updatedAt"`
+	AppliedVersion pgtype.Int4      `json:"appliedVersion"`
+	AppliedAt      pgtype.Timestamp `json:"appliedAt"`
+}
+
+type DeviceConfigRevision struct {
+	ID        pgtype.UUID      `json:"id"`
+	DeviceID  string           `json:"deviceId"`
+	Version   int32            `json:"version"`
+	Config    []byte           `json:"config"`
+	ChangedBy string           `json:"changedBy"`
+	CreatedAt pgtype.Timestamp `json:"createdAt"`
+}
+
+type Division struct {
+	ID        pgtype.UUID      `json:"id"`
+	Name      string           `json:"name"`
+	Volume    float64          `json:"volume"`
+	Tolerance float64          `json:"tolerance"`
+	Handicap  float64          `json:"handicap"`
+	CreatedAt pgtype.Timestamp `json:"createdAt"`
+}
+
+type Duel struct {
+	ID          pgtype.UUID      `json:"id"`
+	Metric      string           `json:"metric"`
+	DeviceA     string           `json:"deviceA"`
+	DeviceB     string           `json:"deviceB"`
+	RunA        pgtype.UUID      `json:"runA"`
+	RunB        pgtype.UUID      `json:"runB"`
+	WinnerRunID pgtype.UUID      `json:"winnerRunId"`
+	Status      string           `json:"status"`
+	StartedAt   pgtype.Timestamp `json:"startedAt"`
+	FinishedAt  pgtype.Timestamp `json:"finishedAt"`
+}
+
+type Event struct {
+	ID        pgtype.UUID      `json:"id"`
+	Name      string           `json:"name"`
+	Venue     pgtype.Text      `json:"venue"`
+	StartsAt  pgtype.Timestamp `json:"startsAt"`
+	EndsAt    pgtype.Timestamp `json:"endsAt"`
+	DeviceIds []string         `json:"deviceIds"`
+	CreatedAt pgtype.Timestamp `json:"createdAt"`
+}
+
+type IdempotencyKey struct {
+	Key         string           `json:"key"`
+	Route       string           `json:"route"`
+	Fingerprint string           `json:"fingerprint"`
+	StatusCode  pgtype.Int4      `json:"statusCode"`
+	ContentType pgtype.Text      `json:"contentType"`
+	Response    []byte           `json:"response"`
+	CreatedAt   pgtype.Timestamp `json:"createdAt"`
+}
+
+type RatingHistory struct {
+	ID           pgtype.UUID      `json:"id"`
+	UserID       string           `json:"userId"`
+	OpponentID   string           `json:"opponentId"`
+	Source       string           `json:"source"`
+	SourceID     pgtype.UUID      `json:"sourceId"`
+	Score        float64          `json:"score"`
+	RatingBefore float64          `json:"ratingBefore"`
+	RatingAfter  float64          `json:"ratingAfter"`
+	PlayedAt     pgtype.Timestamp `json:"playedAt"`
+}
+
 type Run struct {
+	ID            pgtype.UUID      `json:"id"`
+	UserID        pgtype.Text      `json:"userId"`
+	Data          []byte           `json:"data"`
+	CreatedAt     pgtype.Timestamp `json:"createdAt"`
+	Image         string           `json:"image"`
+	DeletedAt     pgtype.Timestamp `json:"deletedAt"`
+	PersonalBests []string         `json:"personalBests"`
+	Records       []string         `json:"records"`
+	DeviceID      pgtype.Text      `json:"deviceId"`
+	EventID       pgtype.UUID      `json:"eventId"`
+	DivisionID    pgtype.UUID      `json:"divisionId"`
+	ReviewStatus  string           `json:"reviewStatus"`
+	AnomalyScore  float64          `json:"anomalyScore"`
+	ReviewReasons []string         `json:"reviewReasons"`
+}
+
+type RunReport struct {
+	ID        pgtype.UUID      `json:"id"`
+	RunID     pgtype.UUID      `json:"runId"`
+	UserID    string           `json:"userId"`
+	Reason    pgtype.Text      `json:"reason"`
+	CreatedAt pgtype.Timestamp `json:"createdAt"`
+}
+
+type RunReview struct {
 	ID        pgtype.UUID      `json:"id"`
-	UserID    pgtype.Text      `json:"userId"`
+	RunID     pgtype.UUID      `json:"runId"`
+	Status    string           `json:"status"`
+	Reviewer  string           `json:"reviewer"`
+	Reason    pgtype.Text      `json:"reason"`
+	CreatedAt pgtype.Timestamp `json:"createdAt"`
+}
+
+type RunRevision struct {
+	ID        pgtype.UUID      `json:"id"`
+	RunID     pgtype.UUID      `json:"runId"`
 	Data      []byte           `json:"data"`
+	Reason    string           `json:"reason"`
+	EditedBy  string           `json:"editedBy"`
+	CreatedAt pgtype.Timestamp `json:"createdAt"`
+}
+
+type RunSample struct {
+	RunID       pgtype.UUID      `json:"runId"`
+	SampleCount int32            `json:"sampleCount"`
+	Data        []byte           `json:"data"`
+	Metrics     []byte           `json:"metrics"`
+	Reported    []byte           `json:"reported"`
+	Verdict     pgtype.Text      `json:"verdict"`
+	CreatedAt   pgtype.Timestamp `json:"createdAt"`
+}
+
+type RunScore struct {
+	RunID   pgtype.UUID `json:"runId"`
+	Formula string      `json:"formula"`
+	Score   float64     `json:"score"`
+}
+
+type ScoringFormula struct {
+	Name        string           `json:"name"`
+	Expression  string           `json:"expression"`
+	Description pgtype.Text      `json:"description"`
+	CreatedAt   pgtype.Timestamp `json:"createdAt"`
+	UpdatedAt   pgtype.Timestamp `json:"updatedAt"`
+}
+
+type Season struct {
+	ID        pgtype.UUID      `json:"id"`
+	Name      string           `json:"name"`
+	StartsAt  pgtype.Timestamp `json:"startsAt"`
+	EndsAt    pgtype.Timestamp `json:"endsAt"`
+	ClosedAt  pgtype.Timestamp `json:"closedAt"`
 	CreatedAt pgtype.Timestamp `json:"createdAt"`
-	Image     string           `json:"image"`
+}
+
+type SeasonStanding struct {
+	SeasonID     pgtype.UUID      `json:"seasonId"`
+	Metric       string           `json:"metric"`
+	Rank         int32            `json:"rank"`
+	UserID       string           `json:"userId"`
+	UserName     string           `json:"userName"`
+	UserUsername string           `json:"userUsername"`
+	RunID        pgtype.UUID      `json:"runId"`
+	Score        float64          `json:"score"`
+	RecordedAt   pgtype.Timestamp `json:"recordedAt"`
 }
 
 type Session struct {
@@ -44,6 +215,65 @@ type Session struct {
 	ImpersonatedBy pgtype.Text      `json:"impersonatedBy"`
 }
 
+type Team struct {
+	ID          pgtype.UUID      `json:"id"`
+	Name        string           `json:"name"`
+	Description pgtype.Text      `json:"description"`
+	CreatedAt   pgtype.Timestamp `json:"createdAt"`
+}
+
+type TeamInvitation struct {
+	ID          pgtype.UUID      `json:"id"`
+	TeamID      pgtype.UUID      `json:"teamId"`
+	UserID      string           `json:"userId"`
+	InvitedBy   pgtype.Text      `json:"invitedBy"`
+	Status      string           `json:"status"`
+	CreatedAt   pgtype.Timestamp `json:"createdAt"`
+	RespondedAt pgtype.Timestamp `json:"respondedAt"`
+}
+
+type TeamMember struct {
+	TeamID   pgtype.UUID      `json:"teamId"`
+	UserID   string           `json:"userId"`
+	Role     string           `json:"role"`
+	JoinedAt pgtype.Timestamp `json:"joinedAt"`
+}
+
+type Tournament struct {
+	ID           pgtype.UUID      `json:"id"`
+	Name         string           `json:"name"`
+	Format       string           `json:"format"`
+	Metric       string           `json:"metric"`
+	Status       string           `json:"status"`
+	WinnerUserID pgtype.Text      `json:"winnerUserId"`
+	StartedAt    pgtype.Timestamp `json:"startedAt"`
+	FinishedAt   pgtype.Timestamp `json:"finishedAt"`
+	CreatedAt    pgtype.Timestamp `json:"createdAt"`
+}
+
+type TournamentMatch struct {
+	ID           pgtype.UUID      `json:"id"`
+	TournamentID pgtype.UUID      `json:"tournamentId"`
+	Bracket      string           `json:"bracket"`
+	Round        int32            `json:"round"`
+	Position     int32            `json:"position"`
+	PlayerA      pgtype.Text      `json:"playerA"`
+	PlayerB      pgtype.Text      `json:"playerB"`
+	WinnerUserID pgtype.Text      `json:"winnerUserId"`
+	Status       string           `json:"status"`
+	DuelID       pgtype.UUID      `json:"duelId"`
+	RunA         pgtype.UUID      `json:"runA"`
+	RunB         pgtype.UUID      `json:"runB"`
+	FinishedAt   pgtype.Timestamp `json:"finishedAt"`
+}
+
+type TournamentParticipant struct {
+	TournamentID pgtype.UUID      `json:"tournamentId"`
+	UserID       string           `json:"userId"`
+	Seed         pgtype.Int4      `json:"seed"`
+	CreatedAt    pgtype.Timestamp `json:"createdAt"`
+}
+
 type User struct {
 	ID              string           `json:"id"`
 	Name            string           `json:"name"`
@@ -60,6 +290,22 @@ type User struct {
 	BanExpires      pgtype.Timestamp `json:"banExpires"`
 }
 
+type UserAchievement struct {
+	UserID      string           `json:"userId"`
+	Achievement string           `json:"achievement"`
+	RunID       pgtype.UUID      `json:"runId"`
+	AchievedAt  pgtype.Timestamp `json:"achievedAt"`
+	CreatedAt   pgtype.Timestamp `json:"createdAt"`
+}
+
+type UserRating struct {
+	UserID       string           `json:"userId"`
+	Rating       float64          `json:"rating"`
+	Games        int32            `json:"games"`
+	LastPlayedAt pgtype.Timestamp `json:"lastPlayedAt"`
+	UpdatedAt    pgtype.Timestamp `json:"updatedAt"`
+}
+
 type Verification struct {
 	ID         string           `json:"id"`
 	Identifier string           `json:"identifier"`
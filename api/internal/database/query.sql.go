@@ -11,59 +11,3305 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-const deleteRun = `-- name: DeleteRun :exec
-DELETE FROM runs WHERE id = $1
+const acknowledgeDeviceConfig = `-- name: AcknowledgeDeviceConfig :one
+UPDATE device_configs
+SET applied_version = $2, applied_at = NOW()
+WHERE device_id = $1 AND version >= $2
+RETURNING device_id, version, config, updated_by, updated_at, applied_version, applied_at
 `
 
-func (q *Queries) DeleteRun(ctx context.Context, id pgtype.UUID) error {
-	_, err := q.db.Exec(ctx, deleteRun, id)
+type AcknowledgeDeviceConfigParams struct {
+	DeviceID       string      `json:"deviceId"`
+	AppliedVersion pgtype.Int4 `json:"appliedVersion"`
+}
+
+func (q *Queries) AcknowledgeDeviceConfig(ctx context.Context, arg AcknowledgeDeviceConfigParams) (DeviceConfig, error) {
+	row := q.db.QueryRow(ctx, acknowledgeDeviceConfig, arg.DeviceID, arg.AppliedVersion)
+	var i DeviceConfig
+	err := row.Scan(
+		&i.DeviceID,
+		&i.Version,
+		&i.Config,
+		&i.UpdatedBy,
+		&i.UpdatedAt,
+		&i.AppliedVersion,
+		&i.AppliedAt,
+	)
+	return i, err
+}
+
+const addTeamMember = `-- name: AddTeamMember :execrows
+INSERT INTO team_members (team_id, user_id, role, joined_at)
+VALUES ($1, $2, $3, NOW())
+ON CONFLICT (team_id, user_id) DO NOTHING
+`
+
+type AddTeamMemberParams struct {
+	TeamID pgtype.UUID `json:"teamId"`
+	UserID string      `json:"userId"`
+	Role   string      `json:"role"`
+}
+
+func (q *Queries) AddTeamMember(ctx context.Context, arg AddTeamMemberParams) (int64, error) {
+	result, err := q.db.Exec(ctx, addTeamMember, arg.TeamID, arg.UserID, arg.Role)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const addTournamentParticipant = `-- name: AddTournamentParticipant :execrows
+INSERT INTO tournament_participants (tournament_id, user_id, created_at)
+VALUES ($1, $2, NOW())
+ON CONFLICT (tournament_id, user_id) DO NOTHING
+`
+
+type AddTournamentParticipantParams struct {
+	TournamentID pgtype.UUID `json:"tournamentId"`
+	UserID       string      `json:"userId"`
+}
+
+func (q *Queries) AddTournamentParticipant(ctx context.Context, arg AddTournamentParticipantParams) (int64, error) {
+	result, err := q.db.Exec(ctx, addTournamentParticipant, arg.TournamentID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const assignRunsToDivisions = `-- name: AssignRunsToDivisions :execrows
+UPDATE runs r
+SET division_id = (
+    SELECT d.id
+    FROM divisions d
+    WHERE abs(d.volume - (r.data->>'volume')::float) <= d.tolerance
+    ORDER BY abs(d.volume - (r.data->>'volume')::float), d.volume
+    LIMIT 1
+)
+WHERE $1::uuid IS NULL OR r.id = $1
+`
+
+func (q *Queries) AssignRunsToDivisions(ctx context.Context, runID pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, assignRunsToDivisions, runID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const assignRunsToEvents = `-- name: AssignRunsToEvents :execrows
+UPDATE runs r
+SET event_id = (
+    SELECT e.id
+    FROM events e
+    WHERE e.starts_at <= r.created_at
+      AND (e.ends_at IS NULL OR r.created_at < e.ends_at)
+      AND (r.device_id IS NULL
+           OR cardinality(e.device_ids) = 0
+           OR r.device_id = ANY(e.device_ids))
+    ORDER BY e.starts_at DESC
+    LIMIT 1
+)
+WHERE r.event_id IS NULL OR r.event_id = $1
+`
+
+func (q *Queries) AssignRunsToEvents(ctx context.Context, eventID pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, assignRunsToEvents, eventID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const awardAchievement = `-- name: AwardAchievement :execrows
+INSERT INTO user_achievements (user_id, achievement, run_id, achieved_at, created_at)
+VALUES ($1, $2, $3, $4, NOW())
+ON CONFLICT (user_id, achievement) DO NOTHING
+`
+
+type AwardAchievementParams struct {
+	UserID      string           `json:"userId"`
+	Achievement string           `json:"achievement"`
+	RunID       pgtype.UUID      `json:"runId"`
+	AchievedAt  pgtype.Timestamp `json:"achievedAt"`
+}
+
+func (q *Queries) AwardAchievement(ctx context.Context, arg AwardAchievementParams) (int64, error) {
+	result, err := q.db.Exec(ctx, awardAchievement,
+		arg.UserID,
+		arg.Achievement,
+		arg.RunID,
+		arg.AchievedAt,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const claimIdempotencyKey = `-- name: ClaimIdempotencyKey :execrows
+INSERT INTO idempotency_keys (key, route, fingerprint, created_at)
+VALUES ($1, $2, $3, NOW())
+ON CONFLICT (key, route) DO UPDATE
+SET fingerprint = EXCLUDED.fingerprint,
+    status_code = NULL,
+    content_type = NULL,
+    response = NULL,
+    created_at = EXCLUDED.created_at
+WHERE idempotency_keys.created_at < $4
+`
+
+type ClaimIdempotencyKeyParams struct {
+	Key           string           `json:"key"`
+	Route         string           `json:"route"`
+	Fingerprint   string           `json:"fingerprint"`
+	ExpiredBefore pgtype.Timestamp `json:"expiredBefore"`
+}
+
+func (q *Queries) ClaimIdempotencyKey(ctx context.Context, arg ClaimIdempotencyKeyParams) (int64, error) {
+	result, err := q.db.Exec(ctx, claimIdempotencyKey,
+		arg.Key,
+		arg.Route,
+		arg.Fingerprint,
+		arg.ExpiredBefore,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const closeSeason = `-- name: CloseSeason :execrows
+UPDATE seasons
+SET closed_at = NOW()
+WHERE id = $1 AND closed_at IS NULL
+`
+
+func (q *Queries) CloseSeason(ctx context.Context, id pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, closeSeason, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const completeIdempotencyKey = `-- name: CompleteIdempotencyKey :exec
+UPDATE idempotency_keys
+SET status_code = $3, content_type = $4, response = $5
+WHERE key = $1 AND route = $2
+`
+
+type CompleteIdempotencyKeyParams struct {
+	Key         string      `json:"key"`
+	Route       string      `json:"route"`
+	StatusCode  pgtype.Int4 `json:"statusCode"`
+	ContentType pgtype.Text `json:"contentType"`
+	Response    []byte      `json:"response"`
+}
+
+func (q *Queries) CompleteIdempotencyKey(ctx context.Context, arg CompleteIdempotencyKeyParams) error {
+	_, err := q.db.Exec(ctx, completeIdempotencyKey,
+		arg.Key,
+		arg.Route,
+		arg.StatusCode,
+		arg.ContentType,
+		arg.Response,
+	)
+	return err
+}
+
+const countOpenRunReports = `-- name: CountOpenRunReports :one
+SELECT COUNT(*)
+FROM run_reports rp
+WHERE rp.run_id = $1
+  AND rp.created_at > COALESCE(
+      (SELECT MAX(rv.created_at) FROM run_reviews rv WHERE rv.run_id = $1),
+      '-infinity'::timestamp
+  )
+`
+
+func (q *Queries) CountOpenRunReports(ctx context.Context, runID pgtype.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, countOpenRunReports, runID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createDeviceConfigRevision = `-- name: CreateDeviceConfigRevision :exec
+INSERT INTO device_config_revisions (device_id, version, config, changed_by, created_at)
+VALUES ($1, $2, $3, $4, NOW())
+`
+
+type CreateDeviceConfigRevisionParams struct {
+	DeviceID  string `json:"deviceId"`
+	Version   int32  `json:"version"`
+	Config    []byte `json:"config"`
+	ChangedBy string `json:"changedBy"`
+}
+
+func (q *Queries) CreateDeviceConfigRevision(ctx context.Context, arg CreateDeviceConfigRevisionParams) error {
+	_, err := q.db.Exec(ctx, createDeviceConfigRevision,
+		arg.DeviceID,
+		arg.Version,
+		arg.Config,
+		arg.ChangedBy,
+	)
+	return err
+}
+
+const createDivision = `-- name: CreateDivision :one
+INSERT INTO divisions (name, volume, tolerance, handicap, created_at)
+VALUES ($1, $2, $3, $4, NOW())
+RETURNING id, name, volume, tolerance, handicap, created_at
+`
+
+type CreateDivisionParams struct {
+	Name      string  `json:"name"`
+	Volume    float64 `json:"volume"`
+	Tolerance float64 `json:"tolerance"`
+	Handicap  float64 `json:"handicap"`
+}
+
+func (q *Queries) CreateDivision(ctx context.Context, arg CreateDivisionParams) (Division, error) {
+	row := q.db.QueryRow(ctx, createDivision,
+		arg.Name,
+		arg.Volume,
+		arg.Tolerance,
+		arg.Handicap,
+	)
+	var i Division
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Volume,
+		&i.Tolerance,
+		&i.Handicap,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createDuel = `-- name: CreateDuel :one
+INSERT INTO duels (metric, device_a, device_b, status, started_at)
+VALUES ($1, $2, $3, 'open', NOW())
+RETURNING id, metric, device_a, device_b, run_a, run_b, winner_run_id, status, started_at, finished_at
+`
+
+type CreateDuelParams struct {
+	Metric  string `json:"metric"`
+	DeviceA string `json:"deviceA"`
+	DeviceB string `json:"deviceB"`
+}
+
+func (q *Queries) CreateDuel(ctx context.Context, arg CreateDuelParams) (Duel, error) {
+	row := q.db.QueryRow(ctx, createDuel, arg.Metric, arg.DeviceA, arg.DeviceB)
+	var i Duel
+	err := row.Scan(
+		&i.ID,
+		&i.Metric,
+		&i.DeviceA,
+		&i.DeviceB,
+		&i.RunA,
+		&i.RunB,
+		&i.WinnerRunID,
+		&i.Status,
+		&i.StartedAt,
+		&i.FinishedAt,
+	)
+	return i, err
+}
+
+const createEvent = `-- name: CreateEvent :one
+INSERT INTO events (name, venue, starts_at, ends_at, device_ids, created_at)
+VALUES ($1, $2, $3, $4, $5, NOW())
+RETURNING id, name, venue, starts_at, ends_at, device_ids, created_at
+`
+
+type CreateEventParams struct {
+	Name      string           `json:"name"`
+	Venue     pgtype.Text      `json:"venue"`
+	StartsAt  pgtype.Timestamp `json:"startsAt"`
+	EndsAt    pgtype.Timestamp `json:"endsAt"`
+	DeviceIds []string         `json:"deviceIds"`
+}
+
+func (q *Queries) CreateEvent(ctx context.Context, arg CreateEventParams) (Event, error) {
+	row := q.db.QueryRow(ctx, createEvent,
+		arg.Name,
+		arg.Venue,
+		arg.StartsAt,
+		arg.EndsAt,
+		arg.DeviceIds,
+	)
+	var i Event
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Venue,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.DeviceIds,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createInitialUserRating = `-- name: CreateInitialUserRating :exec
+INSERT INTO user_ratings (user_id, rating, games, last_played_at, updated_at)
+VALUES ($1, $2, 0, $3, NOW())
+ON CONFLICT (user_id) DO NOTHING
+`
+
+type CreateInitialUserRatingParams struct {
+	UserID       string           `json:"userId"`
+	Rating       float64          `json:"rating"`
+	LastPlayedAt pgtype.Timestamp `json:"lastPlayedAt"`
+}
+
+func (q *Queries) CreateInitialUserRating(ctx context.Context, arg CreateInitialUserRatingParams) error {
+	_, err := q.db.Exec(ctx, createInitialUserRating, arg.UserID, arg.Rating, arg.LastPlayedAt)
+	return err
+}
+
+const createRatingHistory = `-- name: CreateRatingHistory :exec
+INSERT INTO rating_history (user_id, opponent_id, source, source_id, score, rating_before, rating_after, played_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+`
+
+type CreateRatingHistoryParams struct {
+	UserID       string           `json:"userId"`
+	OpponentID   string           `json:"opponentId"`
+	Source       string           `json:"source"`
+	SourceID     pgtype.UUID      `json:"sourceId"`
+	Score        float64          `json:"score"`
+	RatingBefore float64          `json:"ratingBefore"`
+	RatingAfter  float64          `json:"ratingAfter"`
+	PlayedAt     pgtype.Timestamp `json:"playedAt"`
+}
+
+func (q *Queries) CreateRatingHistory(ctx context.Context, arg CreateRatingHistoryParams) error {
+	_, err := q.db.Exec(ctx, createRatingHistory,
+		arg.UserID,
+		arg.OpponentID,
+		arg.Source,
+		arg.SourceID,
+		arg.Score,
+		arg.RatingBefore,
+		arg.RatingAfter,
+		arg.PlayedAt,
+	)
+	return err
+}
+
+const createRunReport = `-- name: CreateRunReport :one
+INSERT INTO run_reports (run_id, user_id, reason, created_at)
+VALUES ($1, $2, $3, NOW())
+RETURNING id, run_id, user_id, reason, created_at
+`
+
+type CreateRunReportParams struct {
+	RunID  pgtype.UUID `json:"runId"`
+	UserID string      `json:"userId"`
+	Reason pgtype.Text `json:"reason"`
+}
+
+func (q *Queries) CreateRunReport(ctx context.Context, arg CreateRunReportParams) (RunReport, error) {
+	row := q.db.QueryRow(ctx, createRunReport, arg.RunID, arg.UserID, arg.Reason)
+	var i RunReport
+	err := row.Scan(
+		&i.ID,
+		&i.RunID,
+		&i.UserID,
+		&i.Reason,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createRunReview = `-- name: CreateRunReview :exec
+INSERT INTO run_reviews (run_id, status, reviewer, reason, created_at)
+VALUES ($1, $2, $3, $4, NOW())
+`
+
+type CreateRunReviewParams struct {
+	RunID    pgtype.UUID `json:"runId"`
+	Status   string      `json:"status"`
+	Reviewer string      `json:"reviewer"`
+	Reason   pgtype.Text `json:"reason"`
+}
+
+func (q *Queries) CreateRunReview(ctx context.Context, arg CreateRunReviewParams) error {
+	_, err := q.db.Exec(ctx, createRunReview,
+		arg.RunID,
+		arg.Status,
+		arg.Reviewer,
+		arg.Reason,
+	)
+	return err
+}
+
+const createRunRevision = `-- name: CreateRunRevision :one
+INSERT INTO run_revisions (run_id, data, reason, edited_by, created_at)
+VALUES ($1, $2, $3, $4, NOW())
+RETURNING id, run_id, data, reason, edited_by, created_at
+`
+
+type CreateRunRevisionParams struct {
+	RunID    pgtype.UUID `json:"runId"`
+	Data     []byte      `json:"data"`
+	Reason   string      `json:"reason"`
+	EditedBy string      `json:"editedBy"`
+}
+
+func (q *Queries) CreateRunRevision(ctx context.Context, arg CreateRunRevisionParams) (RunRevision, error) {
+	row := q.db.QueryRow(ctx, createRunRevision,
+		arg.RunID,
+		arg.Data,
+		arg.Reason,
+		arg.EditedBy,
+	)
+	var i RunRevision
+	err := row.Scan(
+		&i.ID,
+		&i.RunID,
+		&i.Data,
+		&i.Reason,
+		&i.EditedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createScoringFormula = `-- name: CreateScoringFormula :one
+INSERT INTO scoring_formulas (name, expression, description, created_at, updated_at)
+VALUES ($1, $2, $3, NOW(), NOW())
+RETURNING name, expression, description, created_at, updated_at
+`
+
+type CreateScoringFormulaParams struct {
+	Name        string      `json:"name"`
+	Expression  string      `json:"expression"`
+	Description pgtype.Text `json:"description"`
+}
+
+func (q *Queries) CreateScoringFormula(ctx context.Context, arg CreateScoringFormulaParams) (ScoringFormula, error) {
+	row := q.db.QueryRow(ctx, createScoringFormula, arg.Name, arg.Expression, arg.Description)
+	var i ScoringFormula
+	err := row.Scan(
+		&i.Name,
+		&i.Expression,
+		&i.Description,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createSeason = `-- name: CreateSeason :one
+INSERT INTO seasons (name, starts_at, ends_at, created_at)
+VALUES ($1, $2, $3, NOW())
+RETURNING id, name, starts_at, ends_at, closed_at, created_at
+`
+
+type CreateSeasonParams struct {
+	Name     string           `json:"name"`
+	StartsAt pgtype.Timestamp `json:"startsAt"`
+	EndsAt   pgtype.Timestamp `json:"endsAt"`
+}
+
+func (q *Queries) CreateSeason(ctx context.Context, arg CreateSeasonParams) (Season, error) {
+	row := q.db.QueryRow(ctx, createSeason, arg.Name, arg.StartsAt, arg.EndsAt)
+	var i Season
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.ClosedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createSeasonStanding = `-- name: CreateSeasonStanding :exec
+INSERT INTO season_standings (season_id, metric, rank, user_id, user_name, user_username, run_id, score, recorded_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+`
+
+type CreateSeasonStandingParams struct {
+	SeasonID     pgtype.UUID      `json:"seasonId"`
+	Metric       string           `json:"metric"`
+	Rank         int32            `json:"rank"`
+	UserID       string           `json:"userId"`
+	UserName     string           `json:"userName"`
+	UserUsername string           `json:"userUsername"`
+	RunID        pgtype.UUID      `json:"runId"`
+	Score        float64          `json:"score"`
+	RecordedAt   pgtype.Timestamp `json:"recordedAt"`
+}
+
+func (q *Queries) CreateSeasonStanding(ctx context.Context, arg CreateSeasonStandingParams) error {
+	_, err := q.db.Exec(ctx, createSeasonStanding,
+		arg.SeasonID,
+		arg.Metric,
+		arg.Rank,
+		arg.UserID,
+		arg.UserName,
+		arg.UserUsername,
+		arg.RunID,
+		arg.Score,
+		arg.RecordedAt,
+	)
+	return err
+}
+
+const createTeam = `-- name: CreateTeam :one
+INSERT INTO teams (name, description, created_at)
+VALUES ($1, $2, NOW())
+RETURNING id, name, description, created_at
+`
+
+type CreateTeamParams struct {
+	Name        string      `json:"name"`
+	Description pgtype.Text `json:"description"`
+}
+
+func (q *Queries) CreateTeam(ctx context.Context, arg CreateTeamParams) (Team, error) {
+	row := q.db.QueryRow(ctx, createTeam, arg.Name, arg.Description)
+	var i Team
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createTeamInvitation = `-- name: CreateTeamInvitation :one
+INSERT INTO team_invitations (team_id, user_id, invited_by, status, created_at)
+VALUES ($1, $2, $3, 'pending', NOW())
+RETURNING id, team_id, user_id, invited_by, status, created_at, responded_at
+`
+
+type CreateTeamInvitationParams struct {
+	TeamID    pgtype.UUID `json:"teamId"`
+	UserID    string      `json:"userId"`
+	InvitedBy pgtype.Text `json:"invitedBy"`
+}
+
+func (q *Queries) CreateTeamInvitation(ctx context.Context, arg CreateTeamInvitationParams) (TeamInvitation, error) {
+	row := q.db.QueryRow(ctx, createTeamInvitation, arg.TeamID, arg.UserID, arg.InvitedBy)
+	var i TeamInvitation
+	err := row.Scan(
+		&i.ID,
+		&i.TeamID,
+		&i.UserID,
+		&i.InvitedBy,
+		&i.Status,
+		&i.CreatedAt,
+		&i.RespondedAt,
+	)
+	return i, err
+}
+
+const createTournament = `-- name: CreateTournament :one
+INSERT INTO tournaments (name, format, metric, status, created_at)
+VALUES ($1, $2, $3, 'registration', NOW())
+RETURNING id, name, format, metric, status, winner_user_id, started_at, finished_at, created_at
+`
+
+type CreateTournamentParams struct {
+	Name   string `json:"name"`
+	Format string `json:"format"`
+	Metric string `json:"metric"`
+}
+
+func (q *Queries) CreateTournament(ctx context.Context, arg CreateTournamentParams) (Tournament, error) {
+	row := q.db.QueryRow(ctx, createTournament, arg.Name, arg.Format, arg.Metric)
+	var i Tournament
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Format,
+		&i.Metric,
+		&i.Status,
+		&i.WinnerUserID,
+		&i.StartedAt,
+		&i.FinishedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createTournamentMatch = `-- name: CreateTournamentMatch :exec
+INSERT INTO tournament_matches (tournament_id, bracket, round, position, player_a, player_b, status)
+VALUES ($1, $2, $3, $4, $5, $6, 'pending')
+`
+
+type CreateTournamentMatchParams struct {
+	TournamentID pgtype.UUID `json:"tournamentId"`
+	Bracket      string      `json:"bracket"`
+	Round        int32       `json:"round"`
+	Position     int32       `json:"position"`
+	PlayerA      pgtype.Text `json:"playerA"`
+	PlayerB      pgtype.Text `json:"playerB"`
+}
+
+func (q *Queries) CreateTournamentMatch(ctx context.Context, arg CreateTournamentMatchParams) error {
+	_, err := q.db.Exec(ctx, createTournamentMatch,
+		arg.TournamentID,
+		arg.Bracket,
+		arg.Round,
+		arg.Position,
+		arg.PlayerA,
+		arg.PlayerB,
+	)
+	return err
+}
+
+const deleteAllRatingHistory = `-- name: DeleteAllRatingHistory :exec
+DELETE FROM rating_history
+`
+
+func (q *Queries) DeleteAllRatingHistory(ctx context.Context) error {
+	_, err := q.db.Exec(ctx, deleteAllRatingHistory)
 	return err
 }
 
-const getAllRunsWithUsers = `-- name: GetAllRunsWithUsers :many
-SELECT 
-    r.id, 
-    r.user_id, 
-    r.data, 
-    r.image, 
-    r.created_at,
-    u.id as user_id_full,
-    u.name as user_name,
-    u.username as user_username
-FROM runs r
-LEFT JOIN "user" u ON r.user_id = u.id
-ORDER BY (r.data->>'rate')::float DESC
+const deleteAllUserRatings = `-- name: DeleteAllUserRatings :exec
+DELETE FROM user_ratings
+`
+
+func (q *Queries) DeleteAllUserRatings(ctx context.Context) error {
+	_, err := q.db.Exec(ctx, deleteAllUserRatings)
+	return err
+}
+
+const deleteDivision = `-- name: DeleteDivision :execrows
+DELETE FROM divisions
+WHERE id = $1
+`
+
+func (q *Queries) DeleteDivision(ctx context.Context, id pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteDivision, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteEvent = `-- name: DeleteEvent :execrows
+DELETE FROM events WHERE id = $1
+`
+
+func (q *Queries) DeleteEvent(ctx context.Context, id pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteEvent, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteFormulaScores = `-- name: DeleteFormulaScores :exec
+DELETE FROM run_scores
+WHERE formula = $1
+`
+
+func (q *Queries) DeleteFormulaScores(ctx context.Context, formula string) error {
+	_, err := q.db.Exec(ctx, deleteFormulaScores, formula)
+	return err
+}
+
+const deleteRun = `-- name: DeleteRun :execrows
+UPDATE runs
+SET deleted_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+`
+
+func (q *Queries) DeleteRun(ctx context.Context, id pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteRun, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteRunScore = `-- name: DeleteRunScore :exec
+DELETE FROM run_scores
+WHERE run_id = $1 AND formula = $2
+`
+
+type DeleteRunScoreParams struct {
+	RunID   pgtype.UUID `json:"runId"`
+	Formula string      `json:"formula"`
+}
+
+func (q *Queries) DeleteRunScore(ctx context.Context, arg DeleteRunScoreParams) error {
+	_, err := q.db.Exec(ctx, deleteRunScore, arg.RunID, arg.Formula)
+	return err
+}
+
+const deleteScoringFormula = `-- name: DeleteScoringFormula :execrows
+DELETE FROM scoring_formulas
+WHERE name = $1
+`
+
+func (q *Queries) DeleteScoringFormula(ctx context.Context, name string) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteScoringFormula, name)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteTeam = `-- name: DeleteTeam :execrows
+DELETE FROM teams
+WHERE id = $1
+`
+
+func (q *Queries) DeleteTeam(ctx context.Context, id pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteTeam, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const expireDuels = `-- name: ExpireDuels :many
+UPDATE duels
+SET status = 'expired', finished_at = NOW()
+WHERE status = 'open' AND started_at < $1
+RETURNING id, metric, device_a, device_b, run_a, run_b, winner_run_id, status, started_at, finished_at
+`
+
+func (q *Queries) ExpireDuels(ctx context.Context, startedAt pgtype.Timestamp) ([]Duel, error) {
+	rows, err := q.db.Query(ctx, expireDuels, startedAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Duel
+	for rows.Next() {
+		var i Duel
+		if err := rows.Scan(
+			&i.ID,
+			&i.Metric,
+			&i.DeviceA,
+			&i.DeviceB,
+			&i.RunA,
+			&i.RunB,
+			&i.WinnerRunID,
+			&i.Status,
+			&i.StartedAt,
+			&i.FinishedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const findActiveEventId = `-- name: FindActiveEventId :one
+SELECT id
+FROM events
+WHERE starts_at <= $1
+  AND (ends_at IS NULL OR $1 < ends_at)
+  AND ($2::text IS NULL
+       OR cardinality(device_ids) = 0
+       OR $2::text = ANY(device_ids))
+ORDER BY starts_at DESC
+LIMIT 1
+`
+
+type FindActiveEventIdParams struct {
+	At       pgtype.Timestamp `json:"at"`
+	DeviceID pgtype.Text      `json:"deviceId"`
+}
+
+func (q *Queries) FindActiveEventId(ctx context.Context, arg FindActiveEventIdParams) (pgtype.UUID, error) {
+	row := q.db.QueryRow(ctx, findActiveEventId, arg.At, arg.DeviceID)
+	var id pgtype.UUID
+	err := row.Scan(&id)
+	return id, err
+}
+
+const findDivisionId = `-- name: FindDivisionId :one
+SELECT id
+FROM divisions
+WHERE abs(volume - $1::float) <= tolerance
+ORDER BY abs(volume - $1::float), volume
+LIMIT 1
+`
+
+func (q *Queries) FindDivisionId(ctx context.Context, volume float64) (pgtype.UUID, error) {
+	row := q.db.QueryRow(ctx, findDivisionId, volume)
+	var id pgtype.UUID
+	err := row.Scan(&id)
+	return id, err
+}
+
+const findReadyTournamentMatches = `-- name: FindReadyTournamentMatches :many
+SELECT m.id, m.tournament_id, m.bracket, m.round, m.position, m.player_a, m.player_b, m.winner_user_id, m.status, m.duel_id, m.run_a, m.run_b, m.finished_at
+FROM tournament_matches m
+JOIN tournaments t ON m.tournament_id = t.id
+WHERE t.status = 'running'
+  AND m.status = 'ready'
+  AND ((m.player_a = $1 AND m.player_b = $2)
+       OR (m.player_a = $2 AND m.player_b = $1))
+`
+
+type FindReadyTournamentMatchesParams struct {
+	UserA pgtype.Text `json:"userA"`
+	UserB pgtype.Text `json:"userB"`
+}
+
+func (q *Queries) FindReadyTournamentMatches(ctx context.Context, arg FindReadyTournamentMatchesParams) ([]TournamentMatch, error) {
+	rows, err := q.db.Query(ctx, findReadyTournamentMatches, arg.UserA, arg.UserB)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TournamentMatch
+	for rows.Next() {
+		var i TournamentMatch
+		if err := rows.Scan(
+			&i.ID,
+			&i.TournamentID,
+			&i.Bracket,
+			&i.Round,
+			&i.Position,
+			&i.PlayerA,
+			&i.PlayerB,
+			&i.WinnerUserID,
+			&i.Status,
+			&i.DuelID,
+			&i.RunA,
+			&i.RunB,
+			&i.FinishedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const finishDuel = `-- name: FinishDuel :one
+UPDATE duels
+SET status = $2, winner_run_id = $3, finished_at = NOW()
+WHERE id = $1 AND status = 'open'
+RETURNING id, metric, device_a, device_b, run_a, run_b, winner_run_id, status, started_at, finished_at
+`
+
+type FinishDuelParams struct {
+	ID          pgtype.UUID `json:"id"`
+	Status      string      `json:"status"`
+	WinnerRunID pgtype.UUID `json:"winnerRunId"`
+}
+
+func (q *Queries) FinishDuel(ctx context.Context, arg FinishDuelParams) (Duel, error) {
+	row := q.db.QueryRow(ctx, finishDuel, arg.ID, arg.Status, arg.WinnerRunID)
+	var i Duel
+	err := row.Scan(
+		&i.ID,
+		&i.Metric,
+		&i.DeviceA,
+		&i.DeviceB,
+		&i.RunA,
+		&i.RunB,
+		&i.WinnerRunID,
+		&i.Status,
+		&i.StartedAt,
+		&i.FinishedAt,
+	)
+	return i, err
+}
+
+const finishTournament = `-- name: FinishTournament :exec
+UPDATE tournaments
+SET status = 'finished', winner_user_id = $2, finished_at = NOW()
+WHERE id = $1
+`
+
+type FinishTournamentParams struct {
+	ID           pgtype.UUID `json:"id"`
+	WinnerUserID pgtype.Text `json:"winnerUserId"`
+}
+
+func (q *Queries) FinishTournament(ctx context.Context, arg FinishTournamentParams) error {
+	_, err := q.db.Exec(ctx, finishTournament, arg.ID, arg.WinnerUserID)
+	return err
+}
+
+const getAllRunsWithUsers = `-- name: GetAllRunsWithUsers :many
+SELECT 
+    r.id, 
+    r.user_id, 
+    r.data, 
+    r.image, 
+    r.created_at,
+    u.id as user_id_full,
+    u.name as user_name,
+    u.username as user_username
+FROM runs r
+LEFT JOIN "user" u ON r.user_id = u.id
+WHERE r.deleted_at IS NULL AND r.review_status = 'approved'
+ORDER BY (r.data->>'rate')::float DESC
+`
+
+type GetAllRunsWithUsersRow struct {
+	ID           pgtype.UUID      `json:"id"`
+	UserID       pgtype.Text      `json:"userId"`
+	Data         []byte           `json:"data"`
+	Image        string           `json:"image"`
+	CreatedAt    pgtype.Timestamp `json:"createdAt"`
+	UserIDFull   pgtype.Text      `json:"userIdFull"`
+	UserName     pgtype.Text      `json:"userName"`
+	UserUsername pgtype.Text      `json:"userUsername"`
+}
+
+func (q *Queries) GetAllRunsWithUsers(ctx context.Context) ([]GetAllRunsWithUsersRow, error) {
+	rows, err := q.db.Query(ctx, getAllRunsWithUsers)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetAllRunsWithUsersRow
+	for rows.Next() {
+		var i GetAllRunsWithUsersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Data,
+			&i.Image,
+			&i.CreatedAt,
+			&i.UserIDFull,
+			&i.UserName,
+			&i.UserUsername,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getCurrentSeason = `-- name: GetCurrentSeason :one
+SELECT id, name, starts_at, ends_at, closed_at, created_at
+FROM seasons
+WHERE starts_at <= $1 AND $1 < ends_at
+ORDER BY starts_at DESC
+LIMIT 1
+`
+
+func (q *Queries) GetCurrentSeason(ctx context.Context, at pgtype.Timestamp) (Season, error) {
+	row := q.db.QueryRow(ctx, getCurrentSeason, at)
+	var i Season
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.ClosedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getDeletedRunsWithUsers = `-- name: GetDeletedRunsWithUsers :many
+SELECT
+    r.id,
+    r.user_id,
+    r.data,
+    r.image,
+    r.created_at,
+    r.deleted_at,
+    u.id as user_id_full,
+    u.name as user_name,
+    u.username as user_username
+FROM runs r
+LEFT JOIN "user" u ON r.user_id = u.id
+WHERE r.deleted_at IS NOT NULL
+ORDER BY r.deleted_at DESC
+`
+
+type GetDeletedRunsWithUsersRow struct {
+	ID           pgtype.UUID      `json:"id"`
+	UserID       pgtype.Text      `json:"userId"`
+	Data         []byte           `json:"data"`
+	Image        string           `json:"image"`
+	CreatedAt    pgtype.Timestamp `json:"createdAt"`
+	DeletedAt    pgtype.Timestamp `json:"deletedAt"`
+	UserIDFull   pgtype.Text      `json:"userIdFull"`
+	UserName     pgtype.Text      `json:"userName"`
+	UserUsername pgtype.Text      `json:"userUsername"`
+}
+
+func (q *Queries) GetDeletedRunsWithUsers(ctx context.Context) ([]GetDeletedRunsWithUsersRow, error) {
+	rows, err := q.db.Query(ctx, getDeletedRunsWithUsers)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetDeletedRunsWithUsersRow
+	for rows.Next() {
+		var i GetDeletedRunsWithUsersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Data,
+			&i.Image,
+			&i.CreatedAt,
+			&i.DeletedAt,
+			&i.UserIDFull,
+			&i.UserName,
+			&i.UserUsername,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDevice = `-- name: GetDevice :one
+SELECT id, name, clock_offset_ms, clock_boot_id, clock_synced_at, registered_at, status, status_changed_at, last_heartbeat_at, firmware_version, uptime_seconds, wifi_rssi, sensor_healthy, sensor_error, created_at
+FROM devices
+WHERE id = $1
+`
+
+func (q *Queries) GetDevice(ctx context.Context, id string) (Device, error) {
+	row := q.db.QueryRow(ctx, getDevice, id)
+	var i Device
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.ClockOffsetMs,
+		&i.ClockBootID,
+		&i.ClockSyncedAt,
+		&i.RegisteredAt,
+		&i.Status,
+		&i.StatusChangedAt,
+		&i.LastHeartbeatAt,
+		&i.FirmwareVersion,
+		&i.UptimeSeconds,
+		&i.WifiRssi,
+		&i.SensorHealthy,
+		&i.SensorError,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getDeviceConfig = `-- name: GetDeviceConfig :one
+SELECT device_id, version, config, updated_by, updated_at, applied_version, applied_at
+FROM device_configs
+WHERE device_id = $1
+`
+
+func (q *Queries) GetDeviceConfig(ctx context.Context, deviceID string) (DeviceConfig, error) {
+	row := q.db.QueryRow(ctx, getDeviceConfig, deviceID)
+	var i DeviceConfig
+	err := row.Scan(
+		&i.DeviceID,
+		&i.Version,
+		&i.Config,
+		&i.UpdatedBy,
+		&i.UpdatedAt,
+		&i.AppliedVersion,
+		&i.AppliedAt,
+	)
+	return i, err
+}
+
+const getDeviceConfigRevisions = `-- name: GetDeviceConfigRevisions :many
+SELECT id, device_id, version, config, changed_by, created_at
+FROM device_config_revisions
+WHERE device_id = $1
+ORDER BY version DESC
+`
+
+func (q *Queries) GetDeviceConfigRevisions(ctx context.Context, deviceID string) ([]DeviceConfigRevision, error) {
+	rows, err := q.db.Query(ctx, getDeviceConfigRevisions, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DeviceConfigRevision
+	for rows.Next() {
+		var i DeviceConfigRevision
+		if err := rows.Scan(
+			&i.ID,
+			&i.DeviceID,
+			&i.Version,
+			&i.Config,
+			&i.ChangedBy,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDeviceForUpdate = `-- name: GetDeviceForUpdate :one
+SELECT id, name, clock_offset_ms, clock_boot_id, clock_synced_at, registered_at, status, status_changed_at, last_heartbeat_at, firmware_version, uptime_seconds, wifi_rssi, sensor_healthy, sensor_error, created_at
+FROM devices
+WHERE id = $1
+FOR UPDATE
+`
+
+func (q *Queries) GetDeviceForUpdate(ctx context.Context, id string) (Device, error) {
+	row := q.db.QueryRow(ctx, getDeviceForUpdate, id)
+	var i Device
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.ClockOffsetMs,
+		&i.ClockBootID,
+		&i.ClockSyncedAt,
+		&i.RegisteredAt,
+		&i.Status,
+		&i.StatusChangedAt,
+		&i.LastHeartbeatAt,
+		&i.FirmwareVersion,
+		&i.UptimeSeconds,
+		&i.WifiRssi,
+		&i.SensorHealthy,
+		&i.SensorError,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getDivision = `-- name: GetDivision :one
+SELECT id, name, volume, tolerance, handicap, created_at
+FROM divisions
+WHERE id = $1
+`
+
+func (q *Queries) GetDivision(ctx context.Context, id pgtype.UUID) (Division, error) {
+	row := q.db.QueryRow(ctx, getDivision, id)
+	var i Division
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Volume,
+		&i.Tolerance,
+		&i.Handicap,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getDuel = `-- name: GetDuel :one
+SELECT id, metric, device_a, device_b, run_a, run_b, winner_run_id, status, started_at, finished_at
+FROM duels
+WHERE id = $1
+`
+
+func (q *Queries) GetDuel(ctx context.Context, id pgtype.UUID) (Duel, error) {
+	row := q.db.QueryRow(ctx, getDuel, id)
+	var i Duel
+	err := row.Scan(
+		&i.ID,
+		&i.Metric,
+		&i.DeviceA,
+		&i.DeviceB,
+		&i.RunA,
+		&i.RunB,
+		&i.WinnerRunID,
+		&i.Status,
+		&i.StartedAt,
+		&i.FinishedAt,
+	)
+	return i, err
+}
+
+const getEvent = `-- name: GetEvent :one
+SELECT id, name, venue, starts_at, ends_at, device_ids, created_at
+FROM events
+WHERE id = $1
+`
+
+func (q *Queries) GetEvent(ctx context.Context, id pgtype.UUID) (Event, error) {
+	row := q.db.QueryRow(ctx, getEvent, id)
+	var i Event
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Venue,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.DeviceIds,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getEventSummary = `-- name: GetEventSummary :one
+SELECT
+    COUNT(*) as run_count,
+    COUNT(DISTINCT user_id) as participant_count,
+    COALESCE(SUM((data->>'volume')::float), 0)::float as total_volume,
+    COALESCE(MAX((data->>'rate')::float), 0)::float as best_rate,
+    COALESCE(AVG((data->>'rate')::float), 0)::float as mean_rate,
+    COALESCE(MIN((data->>'duration')::float), 0)::float as fastest_duration,
+    MIN(created_at)::timestamp as first_run_at,
+    MAX(created_at)::timestamp as last_run_at
+FROM runs
+WHERE event_id = $1 AND deleted_at IS NULL AND review_status = 'approved'
+`
+
+type GetEventSummaryRow struct {
+	RunCount         int64            `json:"runCount"`
+	ParticipantCount int64            `json:"participantCount"`
+	TotalVolume      float64          `json:"totalVolume"`
+	BestRate         float64          `json:"bestRate"`
+	MeanRate         float64          `json:"meanRate"`
+	FastestDuration  float64          `json:"fastestDuration"`
+	FirstRunAt       pgtype.Timestamp `json:"firstRunAt"`
+	LastRunAt        pgtype.Timestamp `json:"lastRunAt"`
+}
+
+func (q *Queries) GetEventSummary(ctx context.Context, eventID pgtype.UUID) (GetEventSummaryRow, error) {
+	row := q.db.QueryRow(ctx, getEventSummary, eventID)
+	var i GetEventSummaryRow
+	err := row.Scan(
+		&i.RunCount,
+		&i.ParticipantCount,
+		&i.TotalVolume,
+		&i.BestRate,
+		&i.MeanRate,
+		&i.FastestDuration,
+		&i.FirstRunAt,
+		&i.LastRunAt,
+	)
+	return i, err
+}
+
+const getIdempotencyKey = `-- name: GetIdempotencyKey :one
+SELECT key, route, fingerprint, status_code, content_type, response, created_at
+FROM idempotency_keys
+WHERE key = $1 AND route = $2
+`
+
+type GetIdempotencyKeyParams struct {
+	Key   string `json:"key"`
+	Route string `json:"route"`
+}
+
+func (q *Queries) GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error) {
+	row := q.db.QueryRow(ctx, getIdempotencyKey, arg.Key, arg.Route)
+	var i IdempotencyKey
+	err := row.Scan(
+		&i.Key,
+		&i.Route,
+		&i.Fingerprint,
+		&i.StatusCode,
+		&i.ContentType,
+		&i.Response,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getOpenDuelForDevice = `-- name: GetOpenDuelForDevice :one
+SELECT id, metric, device_a, device_b, run_a, run_b, winner_run_id, status, started_at, finished_at
+FROM duels
+WHERE status = 'open' AND (device_a = $1 OR device_b = $1)
+ORDER BY started_at DESC
+LIMIT 1
+FOR UPDATE
+`
+
+func (q *Queries) GetOpenDuelForDevice(ctx context.Context, deviceID string) (Duel, error) {
+	row := q.db.QueryRow(ctx, getOpenDuelForDevice, deviceID)
+	var i Duel
+	err := row.Scan(
+		&i.ID,
+		&i.Metric,
+		&i.DeviceA,
+		&i.DeviceB,
+		&i.RunA,
+		&i.RunB,
+		&i.WinnerRunID,
+		&i.Status,
+		&i.StartedAt,
+		&i.FinishedAt,
+	)
+	return i, err
+}
+
+const getRatedGames = `-- name: GetRatedGames :many
+SELECT
+    'duel'::text as source,
+    d.id as source_id,
+    ra.user_id::text as player_a,
+    rb.user_id::text as player_b,
+    (CASE WHEN d.winner_run_id IS NULL THEN 0.5 WHEN d.winner_run_id = d.run_a THEN 1 ELSE 0 END)::float as score_a,
+    d.finished_at::timestamp as played_at
+FROM duels d
+JOIN runs ra ON d.run_a = ra.id
+JOIN runs rb ON d.run_b = rb.id
+WHERE d.status = 'finished'
+  AND ra.user_id IS NOT NULL
+  AND rb.user_id IS NOT NULL
+  AND ra.user_id <> rb.user_id
+UNION ALL
+SELECT
+    'match'::text as source,
+    m.id as source_id,
+    m.player_a::text as player_a,
+    m.player_b::text as player_b,
+    (CASE WHEN m.winner_user_id = m.player_a THEN 1 ELSE 0 END)::float as score_a,
+    m.finished_at::timestamp as played_at
+FROM tournament_matches m
+WHERE m.status = 'finished' AND m.duel_id IS NULL
+ORDER BY played_at, source_id
+`
+
+type GetRatedGamesRow struct {
+	Source   string           `json:"source"`
+	SourceID pgtype.UUID      `json:"sourceId"`
+	PlayerA  string           `json:"playerA"`
+	PlayerB  string           `json:"playerB"`
+	ScoreA   float64          `json:"scoreA"`
+	PlayedAt pgtype.Timestamp `json:"playedAt"`
+}
+
+func (q *Queries) GetRatedGames(ctx context.Context) ([]GetRatedGamesRow, error) {
+	rows, err := q.db.Query(ctx, getRatedGames)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetRatedGamesRow
+	for rows.Next() {
+		var i GetRatedGamesRow
+		if err := rows.Scan(
+			&i.Source,
+			&i.SourceID,
+			&i.PlayerA,
+			&i.PlayerB,
+			&i.ScoreA,
+			&i.PlayedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRatingHistory = `-- name: GetRatingHistory :many
+SELECT id, user_id, opponent_id, source, source_id, score, rating_before, rating_after, played_at
+FROM rating_history
+WHERE user_id = $1
+ORDER BY played_at DESC
+LIMIT $2
+`
+
+type GetRatingHistoryParams struct {
+	UserID string `json:"userId"`
+	Limit  int32  `json:"limit"`
+}
+
+func (q *Queries) GetRatingHistory(ctx context.Context, arg GetRatingHistoryParams) ([]RatingHistory, error) {
+	rows, err := q.db.Query(ctx, getRatingHistory, arg.UserID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []RatingHistory
+	for rows.Next() {
+		var i RatingHistory
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.OpponentID,
+			&i.Source,
+			&i.SourceID,
+			&i.Score,
+			&i.RatingBefore,
+			&i.RatingAfter,
+			&i.PlayedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRecentRunsForUser = `-- name: GetRecentRunsForUser :many
+SELECT id, user_id, data, created_at, image, deleted_at, personal_bests, records, device_id, event_id, division_id, review_status, anomaly_score, review_reasons
+FROM runs 
+WHERE user_id = $1 AND deleted_at IS NULL AND review_status = 'approved'
+ORDER BY created_at DESC
+LIMIT $2
+`
+
+type GetRecentRunsForUserParams struct {
+	UserID pgtype.Text `json:"userId"`
+	Limit  int32       `json:"limit"`
+}
+
+func (q *Queries) GetRecentRunsForUser(ctx context.Context, arg GetRecentRunsForUserParams) ([]Run, error) {
+	rows, err := q.db.Query(ctx, getRecentRunsForUser, arg.UserID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Run
+	for rows.Next() {
+		var i Run
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Data,
+			&i.CreatedAt,
+			&i.Image,
+			&i.DeletedAt,
+			&i.PersonalBests,
+			&i.Records,
+			&i.DeviceID,
+			&i.EventID,
+			&i.DivisionID,
+			&i.ReviewStatus,
+			&i.AnomalyScore,
+			&i.ReviewReasons,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRunById = `-- name: GetRunById :one
+SELECT id, user_id, data, created_at, image, deleted_at, personal_bests, records, device_id, event_id, division_id, review_status, anomaly_score, review_reasons
+FROM runs
+WHERE id = $1 AND deleted_at IS NULL
+`
+
+func (q *Queries) GetRunById(ctx context.Context, id pgtype.UUID) (Run, error) {
+	row := q.db.QueryRow(ctx, getRunById, id)
+	var i Run
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Data,
+		&i.CreatedAt,
+		&i.Image,
+		&i.DeletedAt,
+		&i.PersonalBests,
+		&i.Records,
+		&i.DeviceID,
+		&i.EventID,
+		&i.DivisionID,
+		&i.ReviewStatus,
+		&i.AnomalyScore,
+		&i.ReviewReasons,
+	)
+	return i, err
+}
+
+const getRunDataForScoring = `-- name: GetRunDataForScoring :many
+SELECT id, data
+FROM runs
+`
+
+type GetRunDataForScoringRow struct {
+	ID   pgtype.UUID `json:"id"`
+	Data []byte      `json:"data"`
+}
+
+func (q *Queries) GetRunDataForScoring(ctx context.Context) ([]GetRunDataForScoringRow, error) {
+	rows, err := q.db.Query(ctx, getRunDataForScoring)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetRunDataForScoringRow
+	for rows.Next() {
+		var i GetRunDataForScoringRow
+		if err := rows.Scan(&i.ID, &i.Data); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRunForUpdate = `-- name: GetRunForUpdate :one
+SELECT id, user_id, data, created_at, image, deleted_at, personal_bests, records, device_id, event_id, division_id, review_status, anomaly_score, review_reasons
+FROM runs
+WHERE id = $1 AND deleted_at IS NULL
+FOR UPDATE
+`
+
+func (q *Queries) GetRunForUpdate(ctx context.Context, id pgtype.UUID) (Run, error) {
+	row := q.db.QueryRow(ctx, getRunForUpdate, id)
+	var i Run
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Data,
+		&i.CreatedAt,
+		&i.Image,
+		&i.DeletedAt,
+		&i.PersonalBests,
+		&i.Records,
+		&i.DeviceID,
+		&i.EventID,
+		&i.DivisionID,
+		&i.ReviewStatus,
+		&i.AnomalyScore,
+		&i.ReviewReasons,
+	)
+	return i, err
+}
+
+const getRunHistoryStats = `-- name: GetRunHistoryStats :one
+SELECT
+    COUNT(*) as run_count,
+    COALESCE(AVG((data->>'rate')::float), 0)::float as mean_rate,
+    COALESCE(STDDEV_SAMP((data->>'rate')::float), 0)::float as stddev_rate,
+    COALESCE(AVG((data->>'duration')::float), 0)::float as mean_duration,
+    COALESCE(STDDEV_SAMP((data->>'duration')::float), 0)::float as stddev_duration,
+    COALESCE(AVG((data->>'volume')::float), 0)::float as mean_volume,
+    COALESCE(STDDEV_SAMP((data->>'volume')::float), 0)::float as stddev_volume
+FROM runs
+WHERE deleted_at IS NULL AND review_status = 'approved'
+`
+
+type GetRunHistoryStatsRow struct {
+	RunCount       int64   `json:"runCount"`
+	MeanRate       float64 `json:"meanRate"`
+	StddevRate     float64 `json:"stddevRate"`
+	MeanDuration   float64 `json:"meanDuration"`
+	StddevDuration float64 `json:"stddevDuration"`
+	MeanVolume     float64 `json:"meanVolume"`
+	StddevVolume   float64 `json:"stddevVolume"`
+}
+
+func (q *Queries) GetRunHistoryStats(ctx context.Context) (GetRunHistoryStatsRow, error) {
+	row := q.db.QueryRow(ctx, getRunHistoryStats)
+	var i GetRunHistoryStatsRow
+	err := row.Scan(
+		&i.RunCount,
+		&i.MeanRate,
+		&i.StddevRate,
+		&i.MeanDuration,
+		&i.StddevDuration,
+		&i.MeanVolume,
+		&i.StddevVolume,
+	)
+	return i, err
+}
+
+const getRunIdByClientId = `-- name: GetRunIdByClientId :one
+SELECT run_id
+FROM run_client_ids
+WHERE client_id = $1
+`
+
+func (q *Queries) GetRunIdByClientId(ctx context.Context, clientID string) (pgtype.UUID, error) {
+	row := q.db.QueryRow(ctx, getRunIdByClientId, clientID)
+	var runID pgtype.UUID
+	err := row.Scan(&runID)
+	return runID, err
+}
+
+const getRunReviews = `-- name: GetRunReviews :many
+SELECT id, run_id, status, reviewer, reason, created_at
+FROM run_reviews
+WHERE run_id = $1
+ORDER BY created_at DESC
+`
+
+func (q *Queries) GetRunReviews(ctx context.Context, runID pgtype.UUID) ([]RunReview, error) {
+	rows, err := q.db.Query(ctx, getRunReviews, runID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []RunReview
+	for rows.Next() {
+		var i RunReview
+		if err := rows.Scan(
+			&i.ID,
+			&i.RunID,
+			&i.Status,
+			&i.Reviewer,
+			&i.Reason,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRunRevisions = `-- name: GetRunRevisions :many
+SELECT id, run_id, data, reason, edited_by, created_at
+FROM run_revisions
+WHERE run_id = $1
+ORDER BY created_at DESC
+`
+
+func (q *Queries) GetRunRevisions(ctx context.Context, runID pgtype.UUID) ([]RunRevision, error) {
+	rows, err := q.db.Query(ctx, getRunRevisions, runID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []RunRevision
+	for rows.Next() {
+		var i RunRevision
+		if err := rows.Scan(
+			&i.ID,
+			&i.RunID,
+			&i.Data,
+			&i.Reason,
+			&i.EditedBy,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRunSamples = `-- name: GetRunSamples :one
+SELECT run_id, sample_count, data, metrics, reported, verdict, created_at
+FROM run_samples
+WHERE run_id = $1
+`
+
+func (q *Queries) GetRunSamples(ctx context.Context, runID pgtype.UUID) (RunSample, error) {
+	row := q.db.QueryRow(ctx, getRunSamples, runID)
+	var i RunSample
+	err := row.Scan(
+		&i.RunID,
+		&i.SampleCount,
+		&i.Data,
+		&i.Metrics,
+		&i.Reported,
+		&i.Verdict,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getRunStandings = `-- name: GetRunStandings :one
+SELECT
+    (SELECT COUNT(*) FROM runs o
+     WHERE o.deleted_at IS NULL AND o.review_status = 'approved'
+       AND (o.data->>'rate')::float > (r.data->>'rate')::float)::bigint + 1 as overall_rank,
+    (SELECT COUNT(*) FROM runs o
+     WHERE o.deleted_at IS NULL AND o.review_status = 'approved'
+       AND o.user_id = r.user_id
+       AND (o.data->>'rate')::float > (r.data->>'rate')::float)::bigint + 1 as user_rank
+FROM runs r
+WHERE r.id = $1 AND r.deleted_at IS NULL
+`
+
+type GetRunStandingsRow struct {
+	OverallRank int64 `json:"overallRank"`
+	UserRank    int64 `json:"userRank"`
+}
+
+func (q *Queries) GetRunStandings(ctx context.Context, id pgtype.UUID) (GetRunStandingsRow, error) {
+	row := q.db.QueryRow(ctx, getRunStandings, id)
+	var i GetRunStandingsRow
+	err := row.Scan(&i.OverallRank, &i.UserRank)
+	return i, err
+}
+
+const getRunTimesForUser = `-- name: GetRunTimesForUser :many
+SELECT created_at
+FROM runs
+WHERE user_id = $1 AND deleted_at IS NULL AND review_status = 'approved'
+ORDER BY created_at
+`
+
+func (q *Queries) GetRunTimesForUser(ctx context.Context, userID pgtype.Text) ([]pgtype.Timestamp, error) {
+	rows, err := q.db.Query(ctx, getRunTimesForUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []pgtype.Timestamp
+	for rows.Next() {
+		var createdAt pgtype.Timestamp
+		if err := rows.Scan(&createdAt); err != nil {
+			return nil, err
+		}
+		items = append(items, createdAt)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRunWithUserById = `-- name: GetRunWithUserById :one
+SELECT
+    r.id,
+    r.user_id,
+    r.data,
+    r.image,
+    r.created_at,
+    u.id as user_id_full,
+    u.name as user_name,
+    u.username as user_username,
+    r.personal_bests,
+    r.records
+FROM runs r
+LEFT JOIN "user" u ON r.user_id = u.id
+WHERE r.id = $1 AND r.deleted_at IS NULL AND r.review_status = 'approved'
+`
+
+type GetRunWithUserByIdRow struct {
+	ID            pgtype.UUID      `json:"id"`
+	UserID        pgtype.Text      `json:"userId"`
+	Data          []byte           `json:"data"`
+	Image         string           `json:"image"`
+	CreatedAt     pgtype.Timestamp `json:"createdAt"`
+	UserIDFull    pgtype.Text      `json:"userIdFull"`
+	UserName      pgtype.Text      `json:"userName"`
+	UserUsername  pgtype.Text      `json:"userUsername"`
+	PersonalBests []string         `json:"personalBests"`
+	Records       []string         `json:"records"`
+}
+
+func (q *Queries) GetRunWithUserById(ctx context.Context, id pgtype.UUID) (GetRunWithUserByIdRow, error) {
+	row := q.db.QueryRow(ctx, getRunWithUserById, id)
+	var i GetRunWithUserByIdRow
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Data,
+		&i.Image,
+		&i.CreatedAt,
+		&i.UserIDFull,
+		&i.UserName,
+		&i.UserUsername,
+		&i.PersonalBests,
+		&i.Records,
+	)
+	return i, err
+}
+
+const getRuns = `-- name: GetRuns :many
+SELECT id, user_id, data, created_at, image, deleted_at, personal_bests, records, device_id, event_id, division_id, review_status, anomaly_score, review_reasons FROM runs
+WHERE deleted_at IS NULL
+ORDER BY created_at DESC
+`
+
+func (q *Queries) GetRuns(ctx context.Context) ([]Run, error) {
+	rows, err := q.db.Query(ctx, getRuns)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Run
+	for rows.Next() {
+		var i Run
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Data,
+			&i.CreatedAt,
+			&i.Image,
+			&i.DeletedAt,
+			&i.PersonalBests,
+			&i.Records,
+			&i.DeviceID,
+			&i.EventID,
+			&i.DivisionID,
+			&i.ReviewStatus,
+			&i.AnomalyScore,
+			&i.ReviewReasons,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRunsByUserId = `-- name: GetRunsByUserId :many
+SELECT id, user_id, data, created_at, image, deleted_at, personal_bests, records, device_id, event_id, division_id, review_status, anomaly_score, review_reasons
+FROM runs 
+WHERE user_id = $1 AND deleted_at IS NULL AND review_status = 'approved'
+ORDER BY created_at DESC
+`
+
+func (q *Queries) GetRunsByUserId(ctx context.Context, userID pgtype.Text) ([]Run, error) {
+	rows, err := q.db.Query(ctx, getRunsByUserId, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Run
+	for rows.Next() {
+		var i Run
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Data,
+			&i.CreatedAt,
+			&i.Image,
+			&i.DeletedAt,
+			&i.PersonalBests,
+			&i.Records,
+			&i.DeviceID,
+			&i.EventID,
+			&i.DivisionID,
+			&i.ReviewStatus,
+			&i.AnomalyScore,
+			&i.ReviewReasons,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRunsByVerdict = `-- name: GetRunsByVerdict :many
+SELECT
+    r.id,
+    r.user_id,
+    r.data,
+    r.image,
+    r.created_at,
+    u.id as user_id_full,
+    u.name as user_name,
+    u.username as user_username,
+    s.metrics,
+    s.reported
+FROM run_samples s
+JOIN runs r ON s.run_id = r.id
+LEFT JOIN "user" u ON r.user_id = u.id
+WHERE s.verdict = $1 AND r.deleted_at IS NULL
+ORDER BY r.created_at DESC
+LIMIT $2
+`
+
+type GetRunsByVerdictParams struct {
+	Verdict pgtype.Text `json:"verdict"`
+	Limit   int32       `json:"limit"`
+}
+
+type GetRunsByVerdictRow struct {
+	ID           pgtype.UUID      `json:"id"`
+	UserID       pgtype.Text      `json:"userId"`
+	Data         []byte           `json:"data"`
+	Image        string           `json:"image"`
+	CreatedAt    pgtype.Timestamp `json:"createdAt"`
+	UserIDFull   pgtype.Text      `json:"userIdFull"`
+	UserName     pgtype.Text      `json:"userName"`
+	UserUsername pgtype.Text      `json:"userUsername"`
+	Metrics      []byte           `json:"metrics"`
+	Reported     []byte           `json:"reported"`
+}
+
+func (q *Queries) GetRunsByVerdict(ctx context.Context, arg GetRunsByVerdictParams) ([]GetRunsByVerdictRow, error) {
+	rows, err := q.db.Query(ctx, getRunsByVerdict, arg.Verdict, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetRunsByVerdictRow
+	for rows.Next() {
+		var i GetRunsByVerdictRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Data,
+			&i.Image,
+			&i.CreatedAt,
+			&i.UserIDFull,
+			&i.UserName,
+			&i.UserUsername,
+			&i.Metrics,
+			&i.Reported,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRunsPendingReview = `-- name: GetRunsPendingReview :many
+SELECT
+    r.id,
+    r.user_id,
+    r.data,
+    r.image,
+    r.created_at,
+    u.id as user_id_full,
+    u.name as user_name,
+    u.username as user_username,
+    r.anomaly_score,
+    r.review_reasons,
+    (SELECT COUNT(*) FROM run_reports rp
+     WHERE rp.run_id = r.id
+       AND rp.created_at > COALESCE(
+           (SELECT MAX(rv.created_at) FROM run_reviews rv WHERE rv.run_id = r.id),
+           '-infinity'::timestamp
+       )) as report_count
+FROM runs r
+LEFT JOIN "user" u ON r.user_id = u.id
+WHERE r.review_status = 'pending' AND r.deleted_at IS NULL
+ORDER BY r.anomaly_score DESC, r.created_at
+LIMIT $1
+`
+
+type GetRunsPendingReviewRow struct {
+	ID            pgtype.UUID      `json:"id"`
+	UserID        pgtype.Text      `json:"userId"`
+	Data          []byte           `json:"data"`
+	Image         string           `json:"image"`
+	CreatedAt     pgtype.Timestamp `json:"createdAt"`
+	UserIDFull    pgtype.Text      `json:"userIdFull"`
+	UserName      pgtype.Text      `json:"userName"`
+	UserUsername  pgtype.Text      `json:"userUsername"`
+	AnomalyScore  float64          `json:"anomalyScore"`
+	ReviewReasons []string         `json:"reviewReasons"`
+	ReportCount   int64            `json:"reportCount"`
+}
+
+func (q *Queries) GetRunsPendingReview(ctx context.Context, limit int32) ([]GetRunsPendingReviewRow, error) {
+	rows, err := q.db.Query(ctx, getRunsPendingReview, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetRunsPendingReviewRow
+	for rows.Next() {
+		var i GetRunsPendingReviewRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Data,
+			&i.Image,
+			&i.CreatedAt,
+			&i.UserIDFull,
+			&i.UserName,
+			&i.UserUsername,
+			&i.AnomalyScore,
+			&i.ReviewReasons,
+			&i.ReportCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRunsRecordedSince = `-- name: GetRunsRecordedSince :many
+SELECT id, user_id, data, created_at, image, deleted_at, personal_bests, records, device_id, event_id, division_id, review_status, anomaly_score, review_reasons
+FROM runs
+WHERE deleted_at IS NULL AND review_status = 'approved'
+  AND created_at >= $1 AND id <> $2
+  AND ($3::text IS NULL OR user_id = $3)
+ORDER BY created_at
+`
+
+type GetRunsRecordedSinceParams struct {
+	Since     pgtype.Timestamp `json:"since"`
+	ExcludeID pgtype.UUID      `json:"excludeId"`
+	UserID    pgtype.Text      `json:"userId"`
+}
+
+func (q *Queries) GetRunsRecordedSince(ctx context.Context, arg GetRunsRecordedSinceParams) ([]Run, error) {
+	rows, err := q.db.Query(ctx, getRunsRecordedSince, arg.Since, arg.ExcludeID, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Run
+	for rows.Next() {
+		var i Run
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Data,
+			&i.CreatedAt,
+			&i.Image,
+			&i.DeletedAt,
+			&i.PersonalBests,
+			&i.Records,
+			&i.DeviceID,
+			&i.EventID,
+			&i.DivisionID,
+			&i.ReviewStatus,
+			&i.AnomalyScore,
+			&i.ReviewReasons,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getScoringFormula = `-- name: GetScoringFormula :one
+SELECT name, expression, description, created_at, updated_at
+FROM scoring_formulas
+WHERE name = $1
+`
+
+func (q *Queries) GetScoringFormula(ctx context.Context, name string) (ScoringFormula, error) {
+	row := q.db.QueryRow(ctx, getScoringFormula, name)
+	var i ScoringFormula
+	err := row.Scan(
+		&i.Name,
+		&i.Expression,
+		&i.Description,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getSeason = `-- name: GetSeason :one
+SELECT id, name, starts_at, ends_at, closed_at, created_at
+FROM seasons
+WHERE id = $1
+`
+
+func (q *Queries) GetSeason(ctx context.Context, id pgtype.UUID) (Season, error) {
+	row := q.db.QueryRow(ctx, getSeason, id)
+	var i Season
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.ClosedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getSeasonStandings = `-- name: GetSeasonStandings :many
+SELECT season_id, metric, rank, user_id, user_name, user_username, run_id, score, recorded_at
+FROM season_standings
+WHERE season_id = $1 AND metric = $2
+ORDER BY rank, user_username
+`
+
+type GetSeasonStandingsParams struct {
+	SeasonID pgtype.UUID `json:"seasonId"`
+	Metric   string      `json:"metric"`
+}
+
+func (q *Queries) GetSeasonStandings(ctx context.Context, arg GetSeasonStandingsParams) ([]SeasonStanding, error) {
+	rows, err := q.db.Query(ctx, getSeasonStandings, arg.SeasonID, arg.Metric)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SeasonStanding
+	for rows.Next() {
+		var i SeasonStanding
+		if err := rows.Scan(
+			&i.SeasonID,
+			&i.Metric,
+			&i.Rank,
+			&i.UserID,
+			&i.UserName,
+			&i.UserUsername,
+			&i.RunID,
+			&i.Score,
+			&i.RecordedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSeasonsToClose = `-- name: GetSeasonsToClose :many
+SELECT id, name, starts_at, ends_at, closed_at, created_at
+FROM seasons
+WHERE closed_at IS NULL AND ends_at <= $1
+ORDER BY ends_at
+`
+
+func (q *Queries) GetSeasonsToClose(ctx context.Context, endsAt pgtype.Timestamp) ([]Season, error) {
+	rows, err := q.db.Query(ctx, getSeasonsToClose, endsAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Season
+	for rows.Next() {
+		var i Season
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.StartsAt,
+			&i.EndsAt,
+			&i.ClosedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTeam = `-- name: GetTeam :one
+SELECT id, name, description, created_at
+FROM teams
+WHERE id = $1
+`
+
+func (q *Queries) GetTeam(ctx context.Context, id pgtype.UUID) (Team, error) {
+	row := q.db.QueryRow(ctx, getTeam, id)
+	var i Team
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getTeamInvitationForUpdate = `-- name: GetTeamInvitationForUpdate :one
+SELECT id, team_id, user_id, invited_by, status, created_at, responded_at
+FROM team_invitations
+WHERE id = $1 AND team_id = $2
+FOR UPDATE
+`
+
+type GetTeamInvitationForUpdateParams struct {
+	ID     pgtype.UUID `json:"id"`
+	TeamID pgtype.UUID `json:"teamId"`
+}
+
+func (q *Queries) GetTeamInvitationForUpdate(ctx context.Context, arg GetTeamInvitationForUpdateParams) (TeamInvitation, error) {
+	row := q.db.QueryRow(ctx, getTeamInvitationForUpdate, arg.ID, arg.TeamID)
+	var i TeamInvitation
+	err := row.Scan(
+		&i.ID,
+		&i.TeamID,
+		&i.UserID,
+		&i.InvitedBy,
+		&i.Status,
+		&i.CreatedAt,
+		&i.RespondedAt,
+	)
+	return i, err
+}
+
+const getTeamInvitations = `-- name: GetTeamInvitations :many
+SELECT
+    i.id,
+    i.user_id,
+    i.invited_by,
+    i.created_at,
+    u.name as user_name,
+    u.username as user_username
+FROM team_invitations i
+JOIN "user" u ON i.user_id = u.id
+WHERE i.team_id = $1 AND i.status = 'pending'
+ORDER BY i.created_at
+`
+
+type GetTeamInvitationsRow struct {
+	ID           pgtype.UUID      `json:"id"`
+	UserID       string           `json:"userId"`
+	InvitedBy    pgtype.Text      `json:"invitedBy"`
+	CreatedAt    pgtype.Timestamp `json:"createdAt"`
+	UserName     string           `json:"userName"`
+	UserUsername string           `json:"userUsername"`
+}
+
+func (q *Queries) GetTeamInvitations(ctx context.Context, teamID pgtype.UUID) ([]GetTeamInvitationsRow, error) {
+	rows, err := q.db.Query(ctx, getTeamInvitations, teamID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetTeamInvitationsRow
+	for rows.Next() {
+		var i GetTeamInvitationsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.InvitedBy,
+			&i.CreatedAt,
+			&i.UserName,
+			&i.UserUsername,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTeamMember = `-- name: GetTeamMember :one
+SELECT team_id, user_id, role, joined_at
+FROM team_members
+WHERE team_id = $1 AND user_id = $2
+`
+
+type GetTeamMemberParams struct {
+	TeamID pgtype.UUID `json:"teamId"`
+	UserID string      `json:"userId"`
+}
+
+func (q *Queries) GetTeamMember(ctx context.Context, arg GetTeamMemberParams) (TeamMember, error) {
+	row := q.db.QueryRow(ctx, getTeamMember, arg.TeamID, arg.UserID)
+	var i TeamMember
+	err := row.Scan(
+		&i.TeamID,
+		&i.UserID,
+		&i.Role,
+		&i.JoinedAt,
+	)
+	return i, err
+}
+
+const getTeamMembers = `-- name: GetTeamMembers :many
+SELECT
+    m.user_id,
+    m.role,
+    m.joined_at,
+    u.name as user_name,
+    u.username as user_username
+FROM team_members m
+JOIN "user" u ON m.user_id = u.id
+WHERE m.team_id = $1
+ORDER BY m.role = 'captain' DESC, m.joined_at
+`
+
+type GetTeamMembersRow struct {
+	UserID       string           `json:"userId"`
+	Role         string           `json:"role"`
+	JoinedAt     pgtype.Timestamp `json:"joinedAt"`
+	UserName     string           `json:"userName"`
+	UserUsername string           `json:"userUsername"`
+}
+
+func (q *Queries) GetTeamMembers(ctx context.Context, teamID pgtype.UUID) ([]GetTeamMembersRow, error) {
+	rows, err := q.db.Query(ctx, getTeamMembers, teamID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetTeamMembersRow
+	for rows.Next() {
+		var i GetTeamMembersRow
+		if err := rows.Scan(
+			&i.UserID,
+			&i.Role,
+			&i.JoinedAt,
+			&i.UserName,
+			&i.UserUsername,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTeamStats = `-- name: GetTeamStats :one
+SELECT
+    COUNT(r.id) as runs,
+    COALESCE(SUM((r.data->>'volume')::float), 0)::float as total_volume,
+    COALESCE(MAX((r.data->>'rate')::float), 0)::float as best_rate,
+    COALESCE(AVG((r.data->>'rate')::float), 0)::float as average_rate,
+    MAX(r.created_at)::timestamp as last_run_at
+FROM team_members m
+JOIN runs r ON r.user_id = m.user_id
+WHERE m.team_id = $1
+  AND r.deleted_at IS NULL
+  AND r.review_status = 'approved'
+  AND r.created_at >= m.joined_at
+`
+
+type GetTeamStatsRow struct {
+	Runs        int64            `json:"runs"`
+	TotalVolume float64          `json:"totalVolume"`
+	BestRate    float64          `json:"bestRate"`
+	AverageRate float64          `json:"averageRate"`
+	LastRunAt   pgtype.Timestamp `json:"lastRunAt"`
+}
+
+func (q *Queries) GetTeamStats(ctx context.Context, teamID pgtype.UUID) (GetTeamStatsRow, error) {
+	row := q.db.QueryRow(ctx, getTeamStats, teamID)
+	var i GetTeamStatsRow
+	err := row.Scan(
+		&i.Runs,
+		&i.TotalVolume,
+		&i.BestRate,
+		&i.AverageRate,
+		&i.LastRunAt,
+	)
+	return i, err
+}
+
+const getTournament = `-- name: GetTournament :one
+SELECT id, name, format, metric, status, winner_user_id, started_at, finished_at, created_at
+FROM tournaments
+WHERE id = $1
+`
+
+func (q *Queries) GetTournament(ctx context.Context, id pgtype.UUID) (Tournament, error) {
+	row := q.db.QueryRow(ctx, getTournament, id)
+	var i Tournament
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Format,
+		&i.Metric,
+		&i.Status,
+		&i.WinnerUserID,
+		&i.StartedAt,
+		&i.FinishedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getTournamentForUpdate = `-- name: GetTournamentForUpdate :one
+SELECT id, name, format, metric, status, winner_user_id, started_at, finished_at, created_at
+FROM tournaments
+WHERE id = $1
+FOR UPDATE
+`
+
+func (q *Queries) GetTournamentForUpdate(ctx context.Context, id pgtype.UUID) (Tournament, error) {
+	row := q.db.QueryRow(ctx, getTournamentForUpdate, id)
+	var i Tournament
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Format,
+		&i.Metric,
+		&i.Status,
+		&i.WinnerUserID,
+		&i.StartedAt,
+		&i.FinishedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getTournamentMatch = `-- name: GetTournamentMatch :one
+SELECT id, tournament_id, bracket, round, position, player_a, player_b, winner_user_id, status, duel_id, run_a, run_b, finished_at
+FROM tournament_matches
+WHERE id = $1 AND tournament_id = $2
+`
+
+type GetTournamentMatchParams struct {
+	ID           pgtype.UUID `json:"id"`
+	TournamentID pgtype.UUID `json:"tournamentId"`
+}
+
+func (q *Queries) GetTournamentMatch(ctx context.Context, arg GetTournamentMatchParams) (TournamentMatch, error) {
+	row := q.db.QueryRow(ctx, getTournamentMatch, arg.ID, arg.TournamentID)
+	var i TournamentMatch
+	err := row.Scan(
+		&i.ID,
+		&i.TournamentID,
+		&i.Bracket,
+		&i.Round,
+		&i.Position,
+		&i.PlayerA,
+		&i.PlayerB,
+		&i.WinnerUserID,
+		&i.Status,
+		&i.DuelID,
+		&i.RunA,
+		&i.RunB,
+		&i.FinishedAt,
+	)
+	return i, err
+}
+
+const getTournamentMatches = `-- name: GetTournamentMatches :many
+SELECT id, tournament_id, bracket, round, position, player_a, player_b, winner_user_id, status, duel_id, run_a, run_b, finished_at
+FROM tournament_matches
+WHERE tournament_id = $1
+ORDER BY bracket DESC, round, position
+`
+
+func (q *Queries) GetTournamentMatches(ctx context.Context, tournamentID pgtype.UUID) ([]TournamentMatch, error) {
+	rows, err := q.db.Query(ctx, getTournamentMatches, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TournamentMatch
+	for rows.Next() {
+		var i TournamentMatch
+		if err := rows.Scan(
+			&i.ID,
+			&i.TournamentID,
+			&i.Bracket,
+			&i.Round,
+			&i.Position,
+			&i.PlayerA,
+			&i.PlayerB,
+			&i.WinnerUserID,
+			&i.Status,
+			&i.DuelID,
+			&i.RunA,
+			&i.RunB,
+			&i.FinishedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTournamentParticipants = `-- name: GetTournamentParticipants :many
+SELECT
+    p.user_id,
+    p.seed,
+    p.created_at,
+    u.name as user_name,
+    u.username as user_username
+FROM tournament_participants p
+JOIN "user" u ON p.user_id = u.id
+WHERE p.tournament_id = $1
+ORDER BY p.seed NULLS LAST, p.created_at
+`
+
+type GetTournamentParticipantsRow struct {
+	UserID       string           `json:"userId"`
+	Seed         pgtype.Int4      `json:"seed"`
+	CreatedAt    pgtype.Timestamp `json:"createdAt"`
+	UserName     string           `json:"userName"`
+	UserUsername string           `json:"userUsername"`
+}
+
+func (q *Queries) GetTournamentParticipants(ctx context.Context, tournamentID pgtype.UUID) ([]GetTournamentParticipantsRow, error) {
+	rows, err := q.db.Query(ctx, getTournamentParticipants, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetTournamentParticipantsRow
+	for rows.Next() {
+		var i GetTournamentParticipantsRow
+		if err := rows.Scan(
+			&i.UserID,
+			&i.Seed,
+			&i.CreatedAt,
+			&i.UserName,
+			&i.UserUsername,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserAchievements = `-- name: GetUserAchievements :many
+SELECT user_id, achievement, run_id, achieved_at, created_at
+FROM user_achievements
+WHERE user_id = $1
+ORDER BY achieved_at
+`
+
+func (q *Queries) GetUserAchievements(ctx context.Context, userID string) ([]UserAchievement, error) {
+	rows, err := q.db.Query(ctx, getUserAchievements, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []UserAchievement
+	for rows.Next() {
+		var i UserAchievement
+		if err := rows.Scan(
+			&i.UserID,
+			&i.Achievement,
+			&i.RunID,
+			&i.AchievedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserById = `-- name: GetUserById :one
+SELECT id, name, username 
+FROM "user" 
+WHERE id = $1
+`
+
+type GetUserByIdRow struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Username string `json:"username"`
+}
+
+func (q *Queries) GetUserById(ctx context.Context, id string) (GetUserByIdRow, error) {
+	row := q.db.QueryRow(ctx, getUserById, id)
+	var i GetUserByIdRow
+	err := row.Scan(&i.ID, &i.Name, &i.Username)
+	return i, err
+}
+
+const getUserChampionships = `-- name: GetUserChampionships :many
+SELECT
+    s.id as season_id,
+    s.name as season_name,
+    s.ends_at as season_ends_at,
+    ss.metric,
+    ss.score,
+    ss.run_id
+FROM season_standings ss
+JOIN seasons s ON ss.season_id = s.id
+WHERE ss.user_id = $1 AND ss.rank = 1
+ORDER BY s.ends_at DESC, ss.metric
+`
+
+type GetUserChampionshipsRow struct {
+	SeasonID     pgtype.UUID      `json:"seasonId"`
+	SeasonName   string           `json:"seasonName"`
+	SeasonEndsAt pgtype.Timestamp `json:"seasonEndsAt"`
+	Metric       string           `json:"metric"`
+	Score        float64          `json:"score"`
+	RunID        pgtype.UUID      `json:"runId"`
+}
+
+func (q *Queries) GetUserChampionships(ctx context.Context, userID string) ([]GetUserChampionshipsRow, error) {
+	rows, err := q.db.Query(ctx, getUserChampionships, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUserChampionshipsRow
+	for rows.Next() {
+		var i GetUserChampionshipsRow
+		if err := rows.Scan(
+			&i.SeasonID,
+			&i.SeasonName,
+			&i.SeasonEndsAt,
+			&i.Metric,
+			&i.Score,
+			&i.RunID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserDuels = `-- name: GetUserDuels :many
+SELECT
+    d.id, d.metric, d.device_a, d.device_b, d.run_a, d.run_b, d.winner_run_id, d.status, d.started_at, d.finished_at,
+    ra.user_id as user_a,
+    rb.user_id as user_b
+FROM duels d
+JOIN runs ra ON d.run_a = ra.id
+JOIN runs rb ON d.run_b = rb.id
+WHERE d.status = 'finished'
+  AND (ra.user_id = $1 OR rb.user_id = $1)
+  AND ($2::text IS NULL
+       OR ra.user_id = $2
+       OR rb.user_id = $2)
+ORDER BY d.finished_at DESC
+LIMIT $3
+`
+
+type GetUserDuelsParams struct {
+	UserID     pgtype.Text `json:"userId"`
+	OpponentID pgtype.Text `json:"opponentId"`
+	LimitCount int32       `json:"limitCount"`
+}
+
+type GetUserDuelsRow struct {
+	ID          pgtype.UUID      `json:"id"`
+	Metric      string           `json:"metric"`
+	DeviceA     string           `json:"deviceA"`
+	DeviceB     string           `json:"deviceB"`
+	RunA        pgtype.UUID      `json:"runA"`
+	RunB        pgtype.UUID      `json:"runB"`
+	WinnerRunID pgtype.UUID      `json:"winnerRunId"`
+	Status      string           `json:"status"`
+	StartedAt   pgtype.Timestamp `json:"startedAt"`
+	FinishedAt  pgtype.Timestamp `json:"finishedAt"`
+	UserA       pgtype.Text      `json:"userA"`
+	UserB       pgtype.Text      `json:"userB"`
+}
+
+func (q *Queries) GetUserDuels(ctx context.Context, arg GetUserDuelsParams) ([]GetUserDuelsRow, error) {
+	rows, err := q.db.Query(ctx, getUserDuels, arg.UserID, arg.OpponentID, arg.LimitCount)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUserDuelsRow
+	for rows.Next() {
+		var i GetUserDuelsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Metric,
+			&i.DeviceA,
+			&i.DeviceB,
+			&i.RunA,
+			&i.RunB,
+			&i.WinnerRunID,
+			&i.Status,
+			&i.StartedAt,
+			&i.FinishedAt,
+			&i.UserA,
+			&i.UserB,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserIdsWithRuns = `-- name: GetUserIdsWithRuns :many
+SELECT DISTINCT user_id
+FROM runs
+WHERE user_id IS NOT NULL AND deleted_at IS NULL
+`
+
+func (q *Queries) GetUserIdsWithRuns(ctx context.Context) ([]pgtype.Text, error) {
+	rows, err := q.db.Query(ctx, getUserIdsWithRuns)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []pgtype.Text
+	for rows.Next() {
+		var userID pgtype.Text
+		if err := rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		items = append(items, userID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserProfile = `-- name: GetUserProfile :one
+SELECT id, name, username, display_username, image, created_at
+FROM "user"
+WHERE id = $1
+`
+
+type GetUserProfileRow struct {
+	ID              string           `json:"id"`
+	Name            string           `json:"name"`
+	Username        string           `json:"username"`
+	DisplayUsername string           `json:"displayUsername"`
+	Image           pgtype.Text      `json:"image"`
+	CreatedAt       pgtype.Timestamp `json:"createdAt"`
+}
+
+func (q *Queries) GetUserProfile(ctx context.Context, id string) (GetUserProfileRow, error) {
+	row := q.db.QueryRow(ctx, getUserProfile, id)
+	var i GetUserProfileRow
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Username,
+		&i.DisplayUsername,
+		&i.Image,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getUserRank = `-- name: GetUserRank :one
+WITH best AS (
+    SELECT user_id, MAX((data->>'rate')::float) as best_rate
+    FROM runs
+    WHERE deleted_at IS NULL AND review_status = 'approved' AND user_id IS NOT NULL
+    GROUP BY user_id
+)
+SELECT (COUNT(*) + 1)::bigint as rank
+FROM best
+WHERE best_rate > (SELECT b.best_rate FROM best b WHERE b.user_id = $1)
+`
+
+func (q *Queries) GetUserRank(ctx context.Context, userID pgtype.Text) (int64, error) {
+	row := q.db.QueryRow(ctx, getUserRank, userID)
+	var rank int64
+	err := row.Scan(&rank)
+	return rank, err
+}
+
+const getUserRating = `-- name: GetUserRating :one
+SELECT user_id, rating, games, last_played_at, updated_at
+FROM user_ratings
+WHERE user_id = $1
+`
+
+func (q *Queries) GetUserRating(ctx context.Context, userID string) (UserRating, error) {
+	row := q.db.QueryRow(ctx, getUserRating, userID)
+	var i UserRating
+	err := row.Scan(
+		&i.UserID,
+		&i.Rating,
+		&i.Games,
+		&i.LastPlayedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getUserRatingForUpdate = `-- name: GetUserRatingForUpdate :one
+SELECT user_id, rating, games, last_played_at, updated_at
+FROM user_ratings
+WHERE user_id = $1
+FOR UPDATE
+`
+
+func (q *Queries) GetUserRatingForUpdate(ctx context.Context, userID string) (UserRating, error) {
+	row := q.db.QueryRow(ctx, getUserRatingForUpdate, userID)
+	var i UserRating
+	err := row.Scan(
+		&i.UserID,
+		&i.Rating,
+		&i.Games,
+		&i.LastPlayedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getUserRatings = `-- name: GetUserRatings :many
+SELECT
+    r.user_id,
+    r.rating,
+    r.games,
+    r.last_played_at,
+    u.name as user_name,
+    u.username as user_username
+FROM user_ratings r
+JOIN "user" u ON r.user_id = u.id
+`
+
+type GetUserRatingsRow struct {
+	UserID       string           `json:"userId"`
+	Rating       float64          `json:"rating"`
+	Games        int32            `json:"games"`
+	LastPlayedAt pgtype.Timestamp `json:"lastPlayedAt"`
+	UserName     string           `json:"userName"`
+	UserUsername string           `json:"userUsername"`
+}
+
+func (q *Queries) GetUserRatings(ctx context.Context) ([]GetUserRatingsRow, error) {
+	rows, err := q.db.Query(ctx, getUserRatings)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUserRatingsRow
+	for rows.Next() {
+		var i GetUserRatingsRow
+		if err := rows.Scan(
+			&i.UserID,
+			&i.Rating,
+			&i.Games,
+			&i.LastPlayedAt,
+			&i.UserName,
+			&i.UserUsername,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserRunStats = `-- name: GetUserRunStats :one
+SELECT
+    COUNT(*) as run_count,
+    COALESCE(MAX((data->>'rate')::float), 0)::float as best_rate,
+    COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY (data->>'rate')::float), 0)::float as median_rate,
+    COALESCE(AVG((data->>'rate')::float), 0)::float as mean_rate,
+    COALESCE(MIN((data->>'duration')::float), 0)::float as fastest_duration,
+    COALESCE(SUM((data->>'volume')::float), 0)::float as total_volume,
+    MIN(created_at)::timestamp as first_run_at,
+    MAX(created_at)::timestamp as last_run_at
+FROM runs
+WHERE user_id = $1 AND deleted_at IS NULL AND review_status = 'approved'
+`
+
+type GetUserRunStatsRow struct {
+	RunCount        int64            `json:"runCount"`
+	BestRate        float64          `json:"bestRate"`
+	MedianRate      float64          `json:"medianRate"`
+	MeanRate        float64          `json:"meanRate"`
+	FastestDuration float64          `json:"fastestDuration"`
+	TotalVolume     float64          `json:"totalVolume"`
+	FirstRunAt      pgtype.Timestamp `json:"firstRunAt"`
+	LastRunAt       pgtype.Timestamp `json:"lastRunAt"`
+}
+
+func (q *Queries) GetUserRunStats(ctx context.Context, userID pgtype.Text) (GetUserRunStatsRow, error) {
+	row := q.db.QueryRow(ctx, getUserRunStats, userID)
+	var i GetUserRunStatsRow
+	err := row.Scan(
+		&i.RunCount,
+		&i.BestRate,
+		&i.MedianRate,
+		&i.MeanRate,
+		&i.FastestDuration,
+		&i.TotalVolume,
+		&i.FirstRunAt,
+		&i.LastRunAt,
+	)
+	return i, err
+}
+
+const getUserTeamInvitations = `-- name: GetUserTeamInvitations :many
+SELECT
+    i.id,
+    i.team_id,
+    i.invited_by,
+    i.created_at,
+    t.name as team_name
+FROM team_invitations i
+JOIN teams t ON i.team_id = t.id
+WHERE i.user_id = $1 AND i.status = 'pending'
+ORDER BY i.created_at DESC
+`
+
+type GetUserTeamInvitationsRow struct {
+	ID        pgtype.UUID      `json:"id"`
+	TeamID    pgtype.UUID      `json:"teamId"`
+	InvitedBy pgtype.Text      `json:"invitedBy"`
+	CreatedAt pgtype.Timestamp `json:"createdAt"`
+	TeamName  string           `json:"teamName"`
+}
+
+func (q *Queries) GetUserTeamInvitations(ctx context.Context, userID string) ([]GetUserTeamInvitationsRow, error) {
+	rows, err := q.db.Query(ctx, getUserTeamInvitations, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUserTeamInvitationsRow
+	for rows.Next() {
+		var i GetUserTeamInvitationsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.TeamID,
+			&i.InvitedBy,
+			&i.CreatedAt,
+			&i.TeamName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserTeams = `-- name: GetUserTeams :many
+SELECT
+    t.id,
+    t.name,
+    t.description,
+    t.created_at,
+    m.role,
+    m.joined_at
+FROM team_members m
+JOIN teams t ON m.team_id = t.id
+WHERE m.user_id = $1
+ORDER BY m.joined_at
+`
+
+type GetUserTeamsRow struct {
+	ID          pgtype.UUID      `json:"id"`
+	Name        string           `json:"name"`
+	Description pgtype.Text      `json:"description"`
+	CreatedAt   pgtype.Timestamp `json:"createdAt"`
+	Role        string           `json:"role"`
+	JoinedAt    pgtype.Timestamp `json:"joinedAt"`
+}
+
+func (q *Queries) GetUserTeams(ctx context.Context, userID string) ([]GetUserTeamsRow, error) {
+	rows, err := q.db.Query(ctx, getUserTeams, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUserTeamsRow
+	for rows.Next() {
+		var i GetUserTeamsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.CreatedAt,
+			&i.Role,
+			&i.JoinedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const holdRunForReview = `-- name: HoldRunForReview :one
+UPDATE runs
+SET review_status = 'pending', review_reasons = array_append(review_reasons, $1::text)
+WHERE id = $2
+RETURNING id, user_id, data, created_at, image, deleted_at, personal_bests, records, device_id, event_id, division_id, review_status, anomaly_score, review_reasons
+`
+
+type HoldRunForReviewParams struct {
+	Reason string      `json:"reason"`
+	ID     pgtype.UUID `json:"id"`
+}
+
+func (q *Queries) HoldRunForReview(ctx context.Context, arg HoldRunForReviewParams) (Run, error) {
+	row := q.db.QueryRow(ctx, holdRunForReview, arg.Reason, arg.ID)
+	var i Run
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Data,
+		&i.CreatedAt,
+		&i.Image,
+		&i.DeletedAt,
+		&i.PersonalBests,
+		&i.Records,
+		&i.DeviceID,
+		&i.EventID,
+		&i.DivisionID,
+		&i.ReviewStatus,
+		&i.AnomalyScore,
+		&i.ReviewReasons,
+	)
+	return i, err
+}
+
+const listDevices = `-- name: ListDevices :many
+SELECT id, name, clock_offset_ms, clock_boot_id, clock_synced_at, registered_at, status, status_changed_at, last_heartbeat_at, firmware_version, uptime_seconds, wifi_rssi, sensor_healthy, sensor_error, created_at
+FROM devices
+ORDER BY registered_at IS NULL, id
+`
+
+func (q *Queries) ListDevices(ctx context.Context) ([]Device, error) {
+	rows, err := q.db.Query(ctx, listDevices)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Device
+	for rows.Next() {
+		var i Device
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.ClockOffsetMs,
+			&i.ClockBootID,
+			&i.ClockSyncedAt,
+			&i.RegisteredAt,
+			&i.Status,
+			&i.StatusChangedAt,
+			&i.LastHeartbeatAt,
+			&i.FirmwareVersion,
+			&i.UptimeSeconds,
+			&i.WifiRssi,
+			&i.SensorHealthy,
+			&i.SensorError,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDivisions = `-- name: ListDivisions :many
+SELECT id, name, volume, tolerance, handicap, created_at
+FROM divisions
+ORDER BY volume
+`
+
+func (q *Queries) ListDivisions(ctx context.Context) ([]Division, error) {
+	rows, err := q.db.Query(ctx, listDivisions)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Division
+	for rows.Next() {
+		var i Division
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Volume,
+			&i.Tolerance,
+			&i.Handicap,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDuels = `-- name: ListDuels :many
+SELECT id, metric, device_a, device_b, run_a, run_b, winner_run_id, status, started_at, finished_at
+FROM duels
+WHERE $1::text IS NULL OR status = $1
+ORDER BY started_at DESC
+LIMIT $2
+`
+
+type ListDuelsParams struct {
+	Status     pgtype.Text `json:"status"`
+	LimitCount int32       `json:"limitCount"`
+}
+
+func (q *Queries) ListDuels(ctx context.Context, arg ListDuelsParams) ([]Duel, error) {
+	rows, err := q.db.Query(ctx, listDuels, arg.Status, arg.LimitCount)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Duel
+	for rows.Next() {
+		var i Duel
+		if err := rows.Scan(
+			&i.ID,
+			&i.Metric,
+			&i.DeviceA,
+			&i.DeviceB,
+			&i.RunA,
+			&i.RunB,
+			&i.WinnerRunID,
+			&i.Status,
+			&i.StartedAt,
+			&i.FinishedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEvents = `-- name: ListEvents :many
+SELECT id, name, venue, starts_at, ends_at, device_ids, created_at
+FROM events
+ORDER BY starts_at DESC
 `
 
-type GetAllRunsWithUsersRow struct {
-	ID           pgtype.UUID      `json:"id"`
-	UserID       pgtype.Text      `json:"userId"`
-	Data         []byte           `json:"data"`
-	Image        string           `json:"image"`
-	CreatedAt    pgtype.Timestamp `json:"createdAt"`
-	UserIDFull   pgtype.Text      `json:"userIdFull"`
-	UserName     pgtype.Text      `json:"userName"`
-	UserUsername pgtype.Text      `json:"userUsername"`
+func (q *Queries) ListEvents(ctx context.Context) ([]Event, error) {
+	rows, err := q.db.Query(ctx, listEvents)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Event
+	for rows.Next() {
+		var i Event
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Venue,
+			&i.StartsAt,
+			&i.EndsAt,
+			&i.DeviceIds,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-func (q *Queries) GetAllRunsWithUsers(ctx context.Context) ([]GetAllRunsWithUsersRow, error) {
-	rows, err := q.db.Query(ctx, getAllRunsWithUsers)
+const listScoringFormulas = `-- name: ListScoringFormulas :many
+SELECT name, expression, description, created_at, updated_at
+FROM scoring_formulas
+ORDER BY name
+`
+
+func (q *Queries) ListScoringFormulas(ctx context.Context) ([]ScoringFormula, error) {
+	rows, err := q.db.Query(ctx, listScoringFormulas)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []GetAllRunsWithUsersRow
+	var items []ScoringFormula
 	for rows.Next() {
-		var i GetAllRunsWithUsersRow
+		var i ScoringFormula
+		if err := rows.Scan(
+			&i.Name,
+			&i.Expression,
+			&i.Description,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSeasons = `-- name: ListSeasons :many
+SELECT id, name, starts_at, ends_at, closed_at, created_at
+FROM seasons
+ORDER BY starts_at DESC
+`
+
+func (q *Queries) ListSeasons(ctx context.Context) ([]Season, error) {
+	rows, err := q.db.Query(ctx, listSeasons)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Season
+	for rows.Next() {
+		var i Season
 		if err := rows.Scan(
 			&i.ID,
-			&i.UserID,
-			&i.Data,
-			&i.Image,
+			&i.Name,
+			&i.StartsAt,
+			&i.EndsAt,
+			&i.ClosedAt,
 			&i.CreatedAt,
-			&i.UserIDFull,
-			&i.UserName,
-			&i.UserUsername,
 		); err != nil {
 			return nil, err
 		}
@@ -75,34 +3321,153 @@ func (q *Queries) GetAllRunsWithUsers(ctx context.Context) ([]GetAllRunsWithUser
 	return items, nil
 }
 
-const getRecentRunsForUser = `-- name: GetRecentRunsForUser :many
-SELECT id, user_id, data, created_at, image 
-FROM runs 
-WHERE user_id = $1
-ORDER BY created_at DESC
-LIMIT $2
+const listTeams = `-- name: ListTeams :many
+SELECT
+    t.id,
+    t.name,
+    t.description,
+    t.created_at,
+    COUNT(m.user_id) as member_count
+FROM teams t
+LEFT JOIN team_members m ON m.team_id = t.id
+GROUP BY t.id
+ORDER BY t.name
 `
 
-type GetRecentRunsForUserParams struct {
-	UserID pgtype.Text `json:"userId"`
-	Limit  int32       `json:"limit"`
+type ListTeamsRow struct {
+	ID          pgtype.UUID      `json:"id"`
+	Name        string           `json:"name"`
+	Description pgtype.Text      `json:"description"`
+	CreatedAt   pgtype.Timestamp `json:"createdAt"`
+	MemberCount int64            `json:"memberCount"`
 }
 
-func (q *Queries) GetRecentRunsForUser(ctx context.Context, arg GetRecentRunsForUserParams) ([]Run, error) {
-	rows, err := q.db.Query(ctx, getRecentRunsForUser, arg.UserID, arg.Limit)
+func (q *Queries) ListTeams(ctx context.Context) ([]ListTeamsRow, error) {
+	rows, err := q.db.Query(ctx, listTeams)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Run
+	var items []ListTeamsRow
 	for rows.Next() {
-		var i Run
+		var i ListTeamsRow
 		if err := rows.Scan(
 			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.CreatedAt,
+			&i.MemberCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTournaments = `-- name: ListTournaments :many
+SELECT id, name, format, metric, status, winner_user_id, started_at, finished_at, created_at
+FROM tournaments
+ORDER BY created_at DESC
+`
+
+func (q *Queries) ListTournaments(ctx context.Context) ([]Tournament, error) {
+	rows, err := q.db.Query(ctx, listTournaments)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Tournament
+	for rows.Next() {
+		var i Tournament
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Format,
+			&i.Metric,
+			&i.Status,
+			&i.WinnerUserID,
+			&i.StartedAt,
+			&i.FinishedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockTeamMembers = `-- name: LockTeamMembers :many
+SELECT team_id, user_id, role, joined_at
+FROM team_members
+WHERE team_id = $1
+FOR UPDATE
+`
+
+func (q *Queries) LockTeamMembers(ctx context.Context, teamID pgtype.UUID) ([]TeamMember, error) {
+	rows, err := q.db.Query(ctx, lockTeamMembers, teamID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TeamMember
+	for rows.Next() {
+		var i TeamMember
+		if err := rows.Scan(
+			&i.TeamID,
 			&i.UserID,
-			&i.Data,
+			&i.Role,
+			&i.JoinedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markDevicesOffline = `-- name: MarkDevicesOffline :many
+UPDATE devices
+SET status = 'offline', status_changed_at = NOW()
+WHERE status = 'online' AND last_heartbeat_at < $1
+RETURNING id, name, clock_offset_ms, clock_boot_id, clock_synced_at, registered_at, status, status_changed_at, last_heartbeat_at, firmware_version, uptime_seconds, wifi_rssi, sensor_healthy, sensor_error, created_at
+`
+
+func (q *Queries) MarkDevicesOffline(ctx context.Context, lastHeartbeatAt pgtype.Timestamp) ([]Device, error) {
+	rows, err := q.db.Query(ctx, markDevicesOffline, lastHeartbeatAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Device
+	for rows.Next() {
+		var i Device
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.ClockOffsetMs,
+			&i.ClockBootID,
+			&i.ClockSyncedAt,
+			&i.RegisteredAt,
+			&i.Status,
+			&i.StatusChangedAt,
+			&i.LastHeartbeatAt,
+			&i.FirmwareVersion,
+			&i.UptimeSeconds,
+			&i.WifiRssi,
+			&i.SensorHealthy,
+			&i.SensorError,
 			&i.CreatedAt,
-			&i.Image,
 		); err != nil {
 			return nil, err
 		}
@@ -114,103 +3479,408 @@ func (q *Queries) GetRecentRunsForUser(ctx context.Context, arg GetRecentRunsFor
 	return items, nil
 }
 
-const getRuns = `-- name: GetRuns :many
-SELECT id, user_id, data, created_at, image FROM runs
-ORDER BY created_at DESC
+const purgeDeletedRuns = `-- name: PurgeDeletedRuns :execrows
+DELETE FROM runs
+WHERE deleted_at IS NOT NULL AND deleted_at < $1
+`
+
+func (q *Queries) PurgeDeletedRuns(ctx context.Context, deletedAt pgtype.Timestamp) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeDeletedRuns, deletedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const purgeIdempotencyKeys = `-- name: PurgeIdempotencyKeys :execrows
+DELETE FROM idempotency_keys
+WHERE created_at < $1
+`
+
+func (q *Queries) PurgeIdempotencyKeys(ctx context.Context, createdAt pgtype.Timestamp) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeIdempotencyKeys, createdAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const recordDeviceHeartbeat = `-- name: RecordDeviceHeartbeat :one
+WITH previous AS (
+    SELECT id, status
+    FROM devices
+    WHERE id = $1 AND registered_at IS NOT NULL
+    FOR UPDATE
+)
+UPDATE devices d
+SET firmware_version = $2,
+    uptime_seconds = $3,
+    wifi_rssi = $4,
+    sensor_healthy = $5,
+    sensor_error = $6,
+    last_heartbeat_at = NOW(),
+    status = 'online',
+    status_changed_at = CASE WHEN previous.status = 'online' THEN d.status_changed_at ELSE NOW() END
+FROM previous
+WHERE d.id = previous.id
+RETURNING d.id, d.name, d.clock_offset_ms, d.clock_boot_id, d.clock_synced_at, d.registered_at, d.status, d.status_changed_at, d.last_heartbeat_at, d.firmware_version, d.uptime_seconds, d.wifi_rssi, d.sensor_healthy, d.sensor_error, d.created_at, previous.status as previous_status
+`
+
+type RecordDeviceHeartbeatParams struct {
+	ID              string      `json:"id"`
+	FirmwareVersion pgtype.Text `json:"firmwareVersion"`
+	UptimeSeconds   pgtype.Int8 `json:"uptimeSeconds"`
+	WifiRssi        pgtype.Int4 `json:"wifiRssi"`
+	SensorHealthy   pgtype.Bool `json:"sensorHealthy"`
+	SensorError     pgtype.Text `json:"sensorError"`
+}
+
+type RecordDeviceHeartbeatRow struct {
+	ID              string           `json:"id"`
+	Name            pgtype.Text      `json:"name"`
+	ClockOffsetMs   pgtype.Int8      `json:"clockOffsetMs"`
+	ClockBootID     pgtype.Text      `json:"clockBootId"`
+	ClockSyncedAt   pgtype.Timestamp `json:"clockSyncedAt"`
+	RegisteredAt    pgtype.Timestamp `json:"registeredAt"`
+	Status          string           `json:"status"`
+	StatusChangedAt pgtype.Timestamp `json:"statusChangedAt"`
+	LastHeartbeatAt pgtype.Timestamp `json:"lastHeartbeatAt"`
+	FirmwareVersion pgtype.Text      `json:"firmwareVersion"`
+	UptimeSeconds   pgtype.Int8      `json:"uptimeSeconds"`
+	WifiRssi        pgtype.Int4      `json:"wifiRssi"`
+	SensorHealthy   pgtype.Bool      `json:"sensorHealthy"`
+	SensorError     pgtype.Text      `json:"sensorError"`
+	CreatedAt       pgtype.Timestamp `json:"createdAt"`
+	PreviousStatus  string           `json:"previousStatus"`
+}
+
+func (q *Queries) RecordDeviceHeartbeat(ctx context.Context, arg RecordDeviceHeartbeatParams) (RecordDeviceHeartbeatRow, error) {
+	row := q.db.QueryRow(ctx, recordDeviceHeartbeat,
+		arg.ID,
+		arg.FirmwareVersion,
+		arg.UptimeSeconds,
+		arg.WifiRssi,
+		arg.SensorHealthy,
+		arg.SensorError,
+	)
+	var i RecordDeviceHeartbeatRow
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.ClockOffsetMs,
+		&i.ClockBootID,
+		&i.ClockSyncedAt,
+		&i.RegisteredAt,
+		&i.Status,
+		&i.StatusChangedAt,
+		&i.LastHeartbeatAt,
+		&i.FirmwareVersion,
+		&i.UptimeSeconds,
+		&i.WifiRssi,
+		&i.SensorHealthy,
+		&i.SensorError,
+		&i.CreatedAt,
+		&i.PreviousStatus,
+	)
+	return i, err
+}
+
+const recordTournamentMatchResult = `-- name: RecordTournamentMatchResult :execrows
+UPDATE tournament_matches
+SET winner_user_id = $2, duel_id = $3, run_a = $4, run_b = $5, status = 'finished', finished_at = NOW()
+WHERE id = $1 AND status = 'ready'
+`
+
+type RecordTournamentMatchResultParams struct {
+	ID           pgtype.UUID `json:"id"`
+	WinnerUserID pgtype.Text `json:"winnerUserId"`
+	DuelID       pgtype.UUID `json:"duelId"`
+	RunA         pgtype.UUID `json:"runA"`
+	RunB         pgtype.UUID `json:"runB"`
+}
+
+func (q *Queries) RecordTournamentMatchResult(ctx context.Context, arg RecordTournamentMatchResultParams) (int64, error) {
+	result, err := q.db.Exec(ctx, recordTournamentMatchResult,
+		arg.ID,
+		arg.WinnerUserID,
+		arg.DuelID,
+		arg.RunA,
+		arg.RunB,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const registerDevice = `-- name: RegisterDevice :one
+INSERT INTO devices (id, name, registered_at, created_at)
+VALUES ($1, $2, NOW(), NOW())
+ON CONFLICT (id) DO UPDATE
+SET name = EXCLUDED.name,
+    registered_at = COALESCE(devices.registered_at, EXCLUDED.registered_at)
+RETURNING id, name, clock_offset_ms, clock_boot_id, clock_synced_at, registered_at, status, status_changed_at, last_heartbeat_at, firmware_version, uptime_seconds, wifi_rssi, sensor_healthy, sensor_error, created_at
+`
+
+type RegisterDeviceParams struct {
+	ID   string      `json:"id"`
+	Name pgtype.Text `json:"name"`
+}
+
+func (q *Queries) RegisterDevice(ctx context.Context, arg RegisterDeviceParams) (Device, error) {
+	row := q.db.QueryRow(ctx, registerDevice, arg.ID, arg.Name)
+	var i Device
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.ClockOffsetMs,
+		&i.ClockBootID,
+		&i.ClockSyncedAt,
+		&i.RegisteredAt,
+		&i.Status,
+		&i.StatusChangedAt,
+		&i.LastHeartbeatAt,
+		&i.FirmwareVersion,
+		&i.UptimeSeconds,
+		&i.WifiRssi,
+		&i.SensorHealthy,
+		&i.SensorError,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const releaseIdempotencyKey = `-- name: ReleaseIdempotencyKey :exec
+DELETE FROM idempotency_keys
+WHERE key = $1 AND route = $2
+`
+
+type ReleaseIdempotencyKeyParams struct {
+	Key   string `json:"key"`
+	Route string `json:"route"`
+}
+
+func (q *Queries) ReleaseIdempotencyKey(ctx context.Context, arg ReleaseIdempotencyKeyParams) error {
+	_, err := q.db.Exec(ctx, releaseIdempotencyKey, arg.Key, arg.Route)
+	return err
+}
+
+const removeTeamMember = `-- name: RemoveTeamMember :execrows
+DELETE FROM team_members
+WHERE team_id = $1 AND user_id = $2
+`
+
+type RemoveTeamMemberParams struct {
+	TeamID pgtype.UUID `json:"teamId"`
+	UserID string      `json:"userId"`
+}
+
+func (q *Queries) RemoveTeamMember(ctx context.Context, arg RemoveTeamMemberParams) (int64, error) {
+	result, err := q.db.Exec(ctx, removeTeamMember, arg.TeamID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const removeTournamentParticipant = `-- name: RemoveTournamentParticipant :execrows
+DELETE FROM tournament_participants
+WHERE tournament_id = $1 AND user_id = $2
+`
+
+type RemoveTournamentParticipantParams struct {
+	TournamentID pgtype.UUID `json:"tournamentId"`
+	UserID       string      `json:"userId"`
+}
+
+func (q *Queries) RemoveTournamentParticipant(ctx context.Context, arg RemoveTournamentParticipantParams) (int64, error) {
+	result, err := q.db.Exec(ctx, removeTournamentParticipant, arg.TournamentID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const respondToTeamInvitation = `-- name: RespondToTeamInvitation :execrows
+UPDATE team_invitations
+SET status = $2, responded_at = NOW()
+WHERE id = $1 AND status = 'pending'
+`
+
+type RespondToTeamInvitationParams struct {
+	ID     pgtype.UUID `json:"id"`
+	Status string      `json:"status"`
+}
+
+func (q *Queries) RespondToTeamInvitation(ctx context.Context, arg RespondToTeamInvitationParams) (int64, error) {
+	result, err := q.db.Exec(ctx, respondToTeamInvitation, arg.ID, arg.Status)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const restoreRun = `-- name: RestoreRun :one
+UPDATE runs
+SET deleted_at = NULL
+WHERE id = $1 AND deleted_at IS NOT NULL
+RETURNING id, user_id, data, created_at, image, deleted_at, personal_bests, records, device_id, event_id, division_id, review_status, anomaly_score, review_reasons
+`
+
+func (q *Queries) RestoreRun(ctx context.Context, id pgtype.UUID) (Run, error) {
+	row := q.db.QueryRow(ctx, restoreRun, id)
+	var i Run
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Data,
+		&i.CreatedAt,
+		&i.Image,
+		&i.DeletedAt,
+		&i.PersonalBests,
+		&i.Records,
+		&i.DeviceID,
+		&i.EventID,
+		&i.DivisionID,
+		&i.ReviewStatus,
+		&i.AnomalyScore,
+		&i.ReviewReasons,
+	)
+	return i, err
+}
+
+const reviewRun = `-- name: ReviewRun :one
+UPDATE runs
+SET review_status = $1::text
+WHERE id = $2 AND review_status = 'pending' AND deleted_at IS NULL
+RETURNING id, user_id, data, created_at, image, deleted_at, personal_bests, records, device_id, event_id, division_id, review_status, anomaly_score, review_reasons
+`
+
+type ReviewRunParams struct {
+	Status string      `json:"status"`
+	ID     pgtype.UUID `json:"id"`
+}
+
+func (q *Queries) ReviewRun(ctx context.Context, arg ReviewRunParams) (Run, error) {
+	row := q.db.QueryRow(ctx, reviewRun, arg.Status, arg.ID)
+	var i Run
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Data,
+		&i.CreatedAt,
+		&i.Image,
+		&i.DeletedAt,
+		&i.PersonalBests,
+		&i.Records,
+		&i.DeviceID,
+		&i.EventID,
+		&i.DivisionID,
+		&i.ReviewStatus,
+		&i.AnomalyScore,
+		&i.ReviewReasons,
+	)
+	return i, err
+}
+
+const revokeAchievement = `-- name: RevokeAchievement :exec
+DELETE FROM user_achievements
+WHERE user_id = $1 AND achievement = $2
 `
 
-func (q *Queries) GetRuns(ctx context.Context) ([]Run, error) {
-	rows, err := q.db.Query(ctx, getRuns)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []Run
-	for rows.Next() {
-		var i Run
-		if err := rows.Scan(
-			&i.ID,
-			&i.UserID,
-			&i.Data,
-			&i.CreatedAt,
-			&i.Image,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
+type RevokeAchievementParams struct {
+	UserID      string `json:"userId"`
+	Achievement string `json:"achievement"`
 }
 
-const getRunsByUserId = `-- name: GetRunsByUserId :many
-SELECT id, user_id, data, created_at, image 
-FROM runs 
-WHERE user_id = $1
-ORDER BY created_at DESC
+func (q *Queries) RevokeAchievement(ctx context.Context, arg RevokeAchievementParams) error {
+	_, err := q.db.Exec(ctx, revokeAchievement, arg.UserID, arg.Achievement)
+	return err
+}
+
+const saveDeviceClock = `-- name: SaveDeviceClock :exec
+UPDATE devices
+SET clock_offset_ms = $2,
+    clock_boot_id = $3,
+    clock_synced_at = NOW()
+WHERE id = $1 AND registered_at IS NOT NULL
 `
 
-func (q *Queries) GetRunsByUserId(ctx context.Context, userID pgtype.Text) ([]Run, error) {
-	rows, err := q.db.Query(ctx, getRunsByUserId, userID)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []Run
-	for rows.Next() {
-		var i Run
-		if err := rows.Scan(
-			&i.ID,
-			&i.UserID,
-			&i.Data,
-			&i.CreatedAt,
-			&i.Image,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
+type SaveDeviceClockParams struct {
+	ID            string      `json:"id"`
+	ClockOffsetMs pgtype.Int8 `json:"clockOffsetMs"`
+	ClockBootID   pgtype.Text `json:"clockBootId"`
 }
 
-const getUserById = `-- name: GetUserById :one
-SELECT id, name, username 
-FROM "user" 
-WHERE id = $1
+func (q *Queries) SaveDeviceClock(ctx context.Context, arg SaveDeviceClockParams) error {
+	_, err := q.db.Exec(ctx, saveDeviceClock, arg.ID, arg.ClockOffsetMs, arg.ClockBootID)
+	return err
+}
+
+const saveDeviceConfig = `-- name: SaveDeviceConfig :one
+INSERT INTO device_configs (device_id, version, config, updated_by, updated_at)
+VALUES ($1, 1, $2, $3, NOW())
+ON CONFLICT (device_id) DO UPDATE
+SET version = device_configs.version + 1,
+    config = EXCLUDED.config,
+    updated_by = EXCLUDED.updated_by,
+    updated_at = EXCLUDED.updated_at
+RETURNING device_id, version, config, updated_by, updated_at, applied_version, applied_at
 `
 
-type GetUserByIdRow struct {
-	ID       string `json:"id"`
-	Name     string `json:"name"`
-	Username string `json:"username"`
+type SaveDeviceConfigParams struct {
+	DeviceID  string `json:"deviceId"`
+	Config    []byte `json:"config"`
+	UpdatedBy string `json:"updatedBy"`
 }
 
-func (q *Queries) GetUserById(ctx context.Context, id string) (GetUserByIdRow, error) {
-	row := q.db.QueryRow(ctx, getUserById, id)
-	var i GetUserByIdRow
-	err := row.Scan(&i.ID, &i.Name, &i.Username)
+func (q *Queries) SaveDeviceConfig(ctx context.Context, arg SaveDeviceConfigParams) (DeviceConfig, error) {
+	row := q.db.QueryRow(ctx, saveDeviceConfig, arg.DeviceID, arg.Config, arg.UpdatedBy)
+	var i DeviceConfig
+	err := row.Scan(
+		&i.DeviceID,
+		&i.Version,
+		&i.Config,
+		&i.UpdatedBy,
+		&i.UpdatedAt,
+		&i.AppliedVersion,
+		&i.AppliedAt,
+	)
 	return i, err
 }
 
 const saveRun = `-- name: SaveRun :one
-INSERT INTO runs (user_id, data, image, created_at)
-VALUES ($1, $2, $3, NOW())
-RETURNING id, user_id, data, created_at, image
+INSERT INTO runs (user_id, data, image, device_id, event_id, division_id, review_status, anomaly_score, review_reasons, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, COALESCE($10::timestamp, NOW()))
+RETURNING id, user_id, data, created_at, image, deleted_at, personal_bests, records, device_id, event_id, division_id, review_status, anomaly_score, review_reasons
 `
 
 type SaveRunParams struct {
-	UserID pgtype.Text `json:"userId"`
-	Data   []byte      `json:"data"`
-	Image  string      `json:"image"`
+	UserID        pgtype.Text      `json:"userId"`
+	Data          []byte           `json:"data"`
+	Image         string           `json:"image"`
+	DeviceID      pgtype.Text      `json:"deviceId"`
+	EventID       pgtype.UUID      `json:"eventId"`
+	DivisionID    pgtype.UUID      `json:"divisionId"`
+	ReviewStatus  string           `json:"reviewStatus"`
+	AnomalyScore  float64          `json:"anomalyScore"`
+	ReviewReasons []string         `json:"reviewReasons"`
+	CreatedAt     pgtype.Timestamp `json:"createdAt"`
 }
 
 func (q *Queries) SaveRun(ctx context.Context, arg SaveRunParams) (Run, error) {
-	row := q.db.QueryRow(ctx, saveRun, arg.UserID, arg.Data, arg.Image)
+	row := q.db.QueryRow(ctx, saveRun,
+		arg.UserID,
+		arg.Data,
+		arg.Image,
+		arg.DeviceID,
+		arg.EventID,
+		arg.DivisionID,
+		arg.ReviewStatus,
+		arg.AnomalyScore,
+		arg.ReviewReasons,
+		arg.CreatedAt,
+	)
 	var i Run
 	err := row.Scan(
 		&i.ID,
@@ -218,10 +3888,115 @@ func (q *Queries) SaveRun(ctx context.Context, arg SaveRunParams) (Run, error) {
 		&i.Data,
 		&i.CreatedAt,
 		&i.Image,
+		&i.DeletedAt,
+		&i.PersonalBests,
+		&i.Records,
+		&i.DeviceID,
+		&i.EventID,
+		&i.DivisionID,
+		&i.ReviewStatus,
+		&i.AnomalyScore,
+		&i.ReviewReasons,
 	)
 	return i, err
 }
 
+const saveRunClientId = `-- name: SaveRunClientId :exec
+INSERT INTO run_client_ids (client_id, run_id)
+VALUES ($1, $2)
+`
+
+type SaveRunClientIdParams struct {
+	ClientID string      `json:"clientId"`
+	RunID    pgtype.UUID `json:"runId"`
+}
+
+func (q *Queries) SaveRunClientId(ctx context.Context, arg SaveRunClientIdParams) error {
+	_, err := q.db.Exec(ctx, saveRunClientId, arg.ClientID, arg.RunID)
+	return err
+}
+
+const saveRunSamples = `-- name: SaveRunSamples :execrows
+INSERT INTO run_samples (run_id, sample_count, data, created_at)
+VALUES ($1, $2, $3, NOW())
+ON CONFLICT (run_id) DO NOTHING
+`
+
+type SaveRunSamplesParams struct {
+	RunID       pgtype.UUID `json:"runId"`
+	SampleCount int32       `json:"sampleCount"`
+	Data        []byte      `json:"data"`
+}
+
+func (q *Queries) SaveRunSamples(ctx context.Context, arg SaveRunSamplesParams) (int64, error) {
+	result, err := q.db.Exec(ctx, saveRunSamples, arg.RunID, arg.SampleCount, arg.Data)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const saveRunScore = `-- name: SaveRunScore :exec
+INSERT INTO run_scores (run_id, formula, score)
+VALUES ($1, $2, $3)
+ON CONFLICT (run_id, formula) DO UPDATE SET score = EXCLUDED.score
+`
+
+type SaveRunScoreParams struct {
+	RunID   pgtype.UUID `json:"runId"`
+	Formula string      `json:"formula"`
+	Score   float64     `json:"score"`
+}
+
+func (q *Queries) SaveRunScore(ctx context.Context, arg SaveRunScoreParams) error {
+	_, err := q.db.Exec(ctx, saveRunScore, arg.RunID, arg.Formula, arg.Score)
+	return err
+}
+
+const saveRunVerification = `-- name: SaveRunVerification :exec
+UPDATE run_samples
+SET metrics = $2, reported = $3, verdict = $4
+WHERE run_id = $1
+`
+
+type SaveRunVerificationParams struct {
+	RunID    pgtype.UUID `json:"runId"`
+	Metrics  []byte      `json:"metrics"`
+	Reported []byte      `json:"reported"`
+	Verdict  pgtype.Text `json:"verdict"`
+}
+
+func (q *Queries) SaveRunVerification(ctx context.Context, arg SaveRunVerificationParams) error {
+	_, err := q.db.Exec(ctx, saveRunVerification,
+		arg.RunID,
+		arg.Metrics,
+		arg.Reported,
+		arg.Verdict,
+	)
+	return err
+}
+
+const saveUserRating = `-- name: SaveUserRating :exec
+INSERT INTO user_ratings (user_id, rating, games, last_played_at, updated_at)
+VALUES ($1, $2, 1, $3, NOW())
+ON CONFLICT (user_id) DO UPDATE
+SET rating = EXCLUDED.rating,
+    games = user_ratings.games + 1,
+    last_played_at = EXCLUDED.last_played_at,
+    updated_at = NOW()
+`
+
+type SaveUserRatingParams struct {
+	UserID       string           `json:"userId"`
+	Rating       float64          `json:"rating"`
+	LastPlayedAt pgtype.Timestamp `json:"lastPlayedAt"`
+}
+
+func (q *Queries) SaveUserRating(ctx context.Context, arg SaveUserRatingParams) error {
+	_, err := q.db.Exec(ctx, saveUserRating, arg.UserID, arg.Rating, arg.LastPlayedAt)
+	return err
+}
+
 const searchUsersByName = `-- name: SearchUsersByName :many
 SELECT id, name, username, display_username
 FROM "user"
@@ -267,11 +4042,217 @@ func (q *Queries) SearchUsersByName(ctx context.Context, arg SearchUsersByNamePa
 	return items, nil
 }
 
+const setDuelRuns = `-- name: SetDuelRuns :one
+UPDATE duels
+SET run_a = $2, run_b = $3
+WHERE id = $1
+RETURNING id, metric, device_a, device_b, run_a, run_b, winner_run_id, status, started_at, finished_at
+`
+
+type SetDuelRunsParams struct {
+	ID   pgtype.UUID `json:"id"`
+	RunA pgtype.UUID `json:"runA"`
+	RunB pgtype.UUID `json:"runB"`
+}
+
+func (q *Queries) SetDuelRuns(ctx context.Context, arg SetDuelRunsParams) (Duel, error) {
+	row := q.db.QueryRow(ctx, setDuelRuns, arg.ID, arg.RunA, arg.RunB)
+	var i Duel
+	err := row.Scan(
+		&i.ID,
+		&i.Metric,
+		&i.DeviceA,
+		&i.DeviceB,
+		&i.RunA,
+		&i.RunB,
+		&i.WinnerRunID,
+		&i.Status,
+		&i.StartedAt,
+		&i.FinishedAt,
+	)
+	return i, err
+}
+
+const setTeamMemberRole = `-- name: SetTeamMemberRole :execrows
+UPDATE team_members
+SET role = $3
+WHERE team_id = $1 AND user_id = $2
+`
+
+type SetTeamMemberRoleParams struct {
+	TeamID pgtype.UUID `json:"teamId"`
+	UserID string      `json:"userId"`
+	Role   string      `json:"role"`
+}
+
+func (q *Queries) SetTeamMemberRole(ctx context.Context, arg SetTeamMemberRoleParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setTeamMemberRole, arg.TeamID, arg.UserID, arg.Role)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const setTournamentSeed = `-- name: SetTournamentSeed :exec
+UPDATE tournament_participants
+SET seed = $3
+WHERE tournament_id = $1 AND user_id = $2
+`
+
+type SetTournamentSeedParams struct {
+	TournamentID pgtype.UUID `json:"tournamentId"`
+	UserID       string      `json:"userId"`
+	Seed         pgtype.Int4 `json:"seed"`
+}
+
+func (q *Queries) SetTournamentSeed(ctx context.Context, arg SetTournamentSeedParams) error {
+	_, err := q.db.Exec(ctx, setTournamentSeed, arg.TournamentID, arg.UserID, arg.Seed)
+	return err
+}
+
+const startTournament = `-- name: StartTournament :execrows
+UPDATE tournaments
+SET status = 'running', started_at = NOW()
+WHERE id = $1 AND status = 'registration'
+`
+
+func (q *Queries) StartTournament(ctx context.Context, id pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, startTournament, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const updateDivision = `-- name: UpdateDivision :one
+UPDATE divisions
+SET name = $2, volume = $3, tolerance = $4, handicap = $5
+WHERE id = $1
+RETURNING id, name, volume, tolerance, handicap, created_at
+`
+
+type UpdateDivisionParams struct {
+	ID        pgtype.UUID `json:"id"`
+	Name      string      `json:"name"`
+	Volume    float64     `json:"volume"`
+	Tolerance float64     `json:"tolerance"`
+	Handicap  float64     `json:"handicap"`
+}
+
+func (q *Queries) UpdateDivision(ctx context.Context, arg UpdateDivisionParams) (Division, error) {
+	row := q.db.QueryRow(ctx, updateDivision,
+		arg.ID,
+		arg.Name,
+		arg.Volume,
+		arg.Tolerance,
+		arg.Handicap,
+	)
+	var i Division
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Volume,
+		&i.Tolerance,
+		&i.Handicap,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const updateEvent = `-- name: UpdateEvent :one
+UPDATE events
+SET name = $2, venue = $3, starts_at = $4, ends_at = $5, device_ids = $6
+WHERE id = $1
+RETURNING id, name, venue, starts_at, ends_at, device_ids, created_at
+`
+
+type UpdateEventParams struct {
+	ID        pgtype.UUID      `json:"id"`
+	Name      string           `json:"name"`
+	Venue     pgtype.Text      `json:"venue"`
+	StartsAt  pgtype.Timestamp `json:"startsAt"`
+	EndsAt    pgtype.Timestamp `json:"endsAt"`
+	DeviceIds []string         `json:"deviceIds"`
+}
+
+func (q *Queries) UpdateEvent(ctx context.Context, arg UpdateEventParams) (Event, error) {
+	row := q.db.QueryRow(ctx, updateEvent,
+		arg.ID,
+		arg.Name,
+		arg.Venue,
+		arg.StartsAt,
+		arg.EndsAt,
+		arg.DeviceIds,
+	)
+	var i Event
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Venue,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.DeviceIds,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const updateRunData = `-- name: UpdateRunData :one
+UPDATE runs
+SET data = $2
+WHERE id = $1
+RETURNING id, user_id, data, created_at, image, deleted_at, personal_bests, records, device_id, event_id, division_id, review_status, anomaly_score, review_reasons
+`
+
+type UpdateRunDataParams struct {
+	ID   pgtype.UUID `json:"id"`
+	Data []byte      `json:"data"`
+}
+
+func (q *Queries) UpdateRunData(ctx context.Context, arg UpdateRunDataParams) (Run, error) {
+	row := q.db.QueryRow(ctx, updateRunData, arg.ID, arg.Data)
+	var i Run
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Data,
+		&i.CreatedAt,
+		&i.Image,
+		&i.DeletedAt,
+		&i.PersonalBests,
+		&i.Records,
+		&i.DeviceID,
+		&i.EventID,
+		&i.DivisionID,
+		&i.ReviewStatus,
+		&i.AnomalyScore,
+		&i.ReviewReasons,
+	)
+	return i, err
+}
+
+const updateRunRecords = `-- name: UpdateRunRecords :exec
+UPDATE runs
+SET personal_bests = $2, records = $3
+WHERE id = $1
+`
+
+type UpdateRunRecordsParams struct {
+	ID            pgtype.UUID `json:"id"`
+	PersonalBests []string    `json:"personalBests"`
+	Records       []string    `json:"records"`
+}
+
+func (q *Queries) UpdateRunRecords(ctx context.Context, arg UpdateRunRecordsParams) error {
+	_, err := q.db.Exec(ctx, updateRunRecords, arg.ID, arg.PersonalBests, arg.Records)
+	return err
+}
+
 const updateRunWithUser = `-- name: UpdateRunWithUser :one
 UPDATE runs 
 SET user_id = $2
-WHERE id = $1
-RETURNING id, user_id, data, created_at, image
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, user_id, data, created_at, image, deleted_at, personal_bests, records, device_id, event_id, division_id, review_status, anomaly_score, review_reasons
 `
 
 type UpdateRunWithUserParams struct {
@@ -288,6 +4269,124 @@ func (q *Queries) UpdateRunWithUser(ctx context.Context, arg UpdateRunWithUserPa
 		&i.Data,
 		&i.CreatedAt,
 		&i.Image,
+		&i.DeletedAt,
+		&i.PersonalBests,
+		&i.Records,
+		&i.DeviceID,
+		&i.EventID,
+		&i.DivisionID,
+		&i.ReviewStatus,
+		&i.AnomalyScore,
+		&i.ReviewReasons,
+	)
+	return i, err
+}
+
+const updateScoringFormula = `-- name: UpdateScoringFormula :one
+UPDATE scoring_formulas
+SET expression = $2, description = $3, updated_at = NOW()
+WHERE name = $1
+RETURNING name, expression, description, created_at, updated_at
+`
+
+type UpdateScoringFormulaParams struct {
+	Name        string      `json:"name"`
+	Expression  string      `json:"expression"`
+	Description pgtype.Text `json:"description"`
+}
+
+func (q *Queries) UpdateScoringFormula(ctx context.Context, arg UpdateScoringFormulaParams) (ScoringFormula, error) {
+	row := q.db.QueryRow(ctx, updateScoringFormula, arg.Name, arg.Expression, arg.Description)
+	var i ScoringFormula
+	err := row.Scan(
+		&i.Name,
+		&i.Expression,
+		&i.Description,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const updateSeason = `-- name: UpdateSeason :one
+UPDATE seasons
+SET name = $2, starts_at = $3, ends_at = $4
+WHERE id = $1 AND closed_at IS NULL
+RETURNING id, name, starts_at, ends_at, closed_at, created_at
+`
+
+type UpdateSeasonParams struct {
+	ID       pgtype.UUID      `json:"id"`
+	Name     string           `json:"name"`
+	StartsAt pgtype.Timestamp `json:"startsAt"`
+	EndsAt   pgtype.Timestamp `json:"endsAt"`
+}
+
+func (q *Queries) UpdateSeason(ctx context.Context, arg UpdateSeasonParams) (Season, error) {
+	row := q.db.QueryRow(ctx, updateSeason,
+		arg.ID,
+		arg.Name,
+		arg.StartsAt,
+		arg.EndsAt,
+	)
+	var i Season
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.ClosedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const updateTeam = `-- name: UpdateTeam :one
+UPDATE teams
+SET name = $2, description = $3
+WHERE id = $1
+RETURNING id, name, description, created_at
+`
+
+type UpdateTeamParams struct {
+	ID          pgtype.UUID `json:"id"`
+	Name        string      `json:"name"`
+	Description pgtype.Text `json:"description"`
+}
+
+func (q *Queries) UpdateTeam(ctx context.Context, arg UpdateTeamParams) (Team, error) {
+	row := q.db.QueryRow(ctx, updateTeam, arg.ID, arg.Name, arg.Description)
+	var i Team
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.CreatedAt,
 	)
 	return i, err
 }
+
+const updateTournamentMatchState = `-- name: UpdateTournamentMatchState :exec
+UPDATE tournament_matches
+SET player_a = $2, player_b = $3, winner_user_id = $4, status = $5
+WHERE id = $1
+`
+
+type UpdateTournamentMatchStateParams struct {
+	ID           pgtype.UUID `json:"id"`
+	PlayerA      pgtype.Text `json:"playerA"`
+	PlayerB      pgtype.Text `json:"playerB"`
+	WinnerUserID pgtype.Text `json:"winnerUserId"`
+	Status       string      `json:"status"`
+}
+
+func (q *Queries) UpdateTournamentMatchState(ctx context.Context, arg UpdateTournamentMatchStateParams) error {
+	_, err := q.db.Exec(ctx, updateTournamentMatchState,
+		arg.ID,
+		arg.PlayerA,
+		arg.PlayerB,
+		arg.WinnerUserID,
+		arg.Status,
+	)
+	return err
+}
@@ -0,0 +1,152 @@
+// Package plausibility checks reported run figures against physics and
+// history before they reach the leaderboards.
+//
+// Impossible runs, such as a flow rate no funnel can reach, are rejected
+// outright. Runs that are possible but suspicious, because their figures do
+// not add up or because they beat everything seen before by a wide margin,
+// are held for review instead.
+package plausibility
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultMaxFlowRate      = 120
+	defaultVolumeTolerance  = 0.15
+	defaultOutlierThreshold = 4
+	// minHistory is the number of runs needed before outliers are scored;
+	// with fewer runs the spread says too little.
+	minHistory = 30
+)
+
+// Limits are the bounds runs are checked against. Flow rates are in litres
+// per minute like the reported rate.
+type Limits struct {
+	MaxFlowRate float64
+	// DeviceMaxFlowRate overrides MaxFlowRate for devices with a different
+	// funnel.
+	DeviceMaxFlowRate map[string]float64
+	// VolumeTolerance is the relative difference allowed between the
+	// reported volume and rate × duration.
+	VolumeTolerance float64
+	// OutlierThreshold is the number of standard deviations above the
+	// historical mean at which a run becomes suspicious.
+	OutlierThreshold float64
+}
+
+// LimitsFromEnv reads FUNNEL_MAX_FLOW_RATE, FUNNEL_MAX_FLOW_RATES (a list of
+// device=rate pairs separated by commas), RUN_VOLUME_TOLERANCE and
+// RUN_OUTLIER_THRESHOLD, falling back to the defaults.
+func LimitsFromEnv() Limits {
+	limits := Limits{
+		MaxFlowRate:       defaultMaxFlowRate,
+		DeviceMaxFlowRate: map[string]float64{},
+		VolumeTolerance:   defaultVolumeTolerance,
+		OutlierThreshold:  defaultOutlierThreshold,
+	}
+	if rate, err := strconv.ParseFloat(os.Getenv("FUNNEL_MAX_FLOW_RATE"), 64); err == nil && rate > 0 {
+		limits.MaxFlowRate = rate
+	}
+	for _, pair := range strings.Split(os.Getenv("FUNNEL_MAX_FLOW_RATES"), ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		device, value, _ := strings.Cut(pair, "=")
+		rate, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || rate <= 0 {
+			log.Printf("Ignoring invalid FUNNEL_MAX_FLOW_RATES entry %q", pair)
+			continue
+		}
+		limits.DeviceMaxFlowRate[strings.TrimSpace(device)] = rate
+	}
+	if tolerance, err := strconv.ParseFloat(os.Getenv("RUN_VOLUME_TOLERANCE"), 64); err == nil && tolerance > 0 {
+		limits.VolumeTolerance = tolerance
+	}
+	if threshold, err := strconv.ParseFloat(os.Getenv("RUN_OUTLIER_THRESHOLD"), 64); err == nil && threshold > 0 {
+		limits.OutlierThreshold = threshold
+	}
+	return limits
+}
+
+// MaxFlowRateFor returns the highest flow rate the device's funnel allows.
+func (l Limits) MaxFlowRateFor(deviceID string) float64 {
+	if rate, ok := l.DeviceMaxFlowRate[deviceID]; ok {
+		return rate
+	}
+	return l.MaxFlowRate
+}
+
+// Figures are the reported values of a run: seconds, litres per minute and
+// litres.
+type Figures struct {
+	Duration float64
+	Rate     float64
+	Volume   float64
+}
+
+// History summarises the runs a new run is compared with.
+type History struct {
+	Count          int64
+	MeanRate       float64
+	StddevRate     float64
+	MeanDuration   float64
+	StddevDuration float64
+	MeanVolume     float64
+	StddevVolume   float64
+}
+
+// Result is the outcome of a check. A run with Impossible reasons must be
+// rejected, one with Suspicious reasons needs review.
+type Result struct {
+	Impossible []string
+	Suspicious []string
+	// AnomalyScore is how many standard deviations the run's best figure
+	// beats the historical mean by; 0 when there is too little history.
+	AnomalyScore float64
+}
+
+// Check runs all plausibility checks on a run recorded by deviceID.
+func (l Limits) Check(figures Figures, deviceID string, history History) Result {
+	result := Result{Impossible: []string{}, Suspicious: []string{}}
+
+	maxRate := l.MaxFlowRateFor(deviceID)
+	if figures.Rate > maxRate {
+		result.Impossible = append(result.Impossible, fmt.Sprintf("rate %.2f L/min exceeds the funnel maximum of %.2f L/min", figures.Rate, maxRate))
+	}
+	if implied := figures.Volume / figures.Duration * 60; implied > maxRate {
+		result.Impossible = append(result.Impossible, fmt.Sprintf("volume and duration imply %.2f L/min, above the funnel maximum of %.2f L/min", implied, maxRate))
+	}
+
+	expected := figures.Rate * figures.Duration / 60
+	if math.Abs(figures.Volume-expected) > l.VolumeTolerance*expected {
+		result.Suspicious = append(result.Suspicious, fmt.Sprintf("volume %.2f L does not match rate × duration = %.2f L", figures.Volume, expected))
+	}
+
+	if history.Count >= minHistory {
+		// Only deviations in the better direction matter: a high rate or
+		// volume, or a short duration.
+		result.AnomalyScore = math.Max(0, max(
+			zScore(figures.Rate, history.MeanRate, history.StddevRate),
+			zScore(figures.Volume, history.MeanVolume, history.StddevVolume),
+			-zScore(figures.Duration, history.MeanDuration, history.StddevDuration),
+		))
+		if result.AnomalyScore > l.OutlierThreshold {
+			result.Suspicious = append(result.Suspicious, fmt.Sprintf("%.1f standard deviations better than the historical mean", result.AnomalyScore))
+		}
+	}
+
+	return result
+}
+
+func zScore(value, mean, stddev float64) float64 {
+	if stddev == 0 {
+		return 0
+	}
+	return (value - mean) / stddev
+}
@@ -0,0 +1,62 @@
+package plausibility
+
+import (
+	"math"
+	"testing"
+)
+
+var testLimits = Limits{
+	MaxFlowRate:       120,
+	DeviceMaxFlowRate: map[string]float64{"wide": 200},
+	VolumeTolerance:   0.15,
+	OutlierThreshold:  4,
+}
+
+var testHistory = History{
+	Count:          100,
+	MeanRate:       6,
+	StddevRate:     1,
+	MeanDuration:   10,
+	StddevDuration: 2,
+	MeanVolume:     1,
+	StddevVolume:   0.2,
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name           string
+		figures        Figures
+		deviceID       string
+		history        History
+		wantImpossible int
+		wantSuspicious int
+		wantScore      float64
+	}{
+		{"plausible", Figures{Duration: 10, Rate: 6, Volume: 1}, "", History{}, 0, 0, 0},
+		{"rate above funnel maximum", Figures{Duration: 10, Rate: 130, Volume: 130.0 / 6}, "", History{}, 2, 0, 0},
+		{"device with a wider funnel", Figures{Duration: 10, Rate: 130, Volume: 130.0 / 6}, "wide", History{}, 0, 0, 0},
+		{"volume implies impossible rate", Figures{Duration: 1, Rate: 100, Volume: 2.5}, "", History{}, 1, 1, 0},
+		{"volume within tolerance", Figures{Duration: 10, Rate: 6, Volume: 1.1}, "", History{}, 0, 0, 0},
+		{"volume does not add up", Figures{Duration: 10, Rate: 6, Volume: 1.5}, "", History{}, 0, 1, 0},
+		{"too little history", Figures{Duration: 10, Rate: 12, Volume: 2}, "", History{Count: 29, MeanRate: 6, StddevRate: 1}, 0, 0, 0},
+		{"outlier rate", Figures{Duration: 10, Rate: 12, Volume: 2}, "", testHistory, 0, 1, 6},
+		{"fast but within threshold", Figures{Duration: 2, Rate: 6, Volume: 0.2}, "", testHistory, 0, 0, 4},
+		{"worse than the mean", Figures{Duration: 20, Rate: 3, Volume: 1}, "", testHistory, 0, 0, 0},
+		{"no spread", Figures{Duration: 10, Rate: 12, Volume: 2}, "", History{Count: 100, MeanRate: 6, MeanDuration: 10, MeanVolume: 1}, 0, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := testLimits.Check(tt.figures, tt.deviceID, tt.history)
+			if len(got.Impossible) != tt.wantImpossible {
+				t.Errorf("Impossible = %q, want %d reasons", got.Impossible, tt.wantImpossible)
+			}
+			if len(got.Suspicious) != tt.wantSuspicious {
+				t.Errorf("Suspicious = %q, want %d reasons", got.Suspicious, tt.wantSuspicious)
+			}
+			if math.Abs(got.AnomalyScore-tt.wantScore) > 1e-9 {
+				t.Errorf("AnomalyScore = %v, want %v", got.AnomalyScore, tt.wantScore)
+			}
+		})
+	}
+}
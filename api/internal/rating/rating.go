@@ -0,0 +1,198 @@
+// Package rating maintains Elo-style skill ratings from duels and
+// tournament matches.
+//
+// Ratings of inactive users decay towards the initial rating: after a grace
+// period the distance to the initial rating halves every half-life. Decay is
+// applied lazily whenever a rating is read or updated, so it never needs a
+// background job.
+package rating
+
+import (
+	"context"
+	"errors"
+	"math"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/tt-trichter/app/api/internal/database"
+)
+
+const (
+	SourceDuel  = "duel"
+	SourceMatch = "match"
+)
+
+const (
+	defaultInitial    = 1500
+	defaultKFactor    = 32
+	defaultDecayAfter = 30 * 24 * time.Hour
+	defaultHalfLife   = 90 * 24 * time.Hour
+)
+
+type Config struct {
+	Initial    float64
+	KFactor    float64
+	DecayAfter time.Duration
+	HalfLife   time.Duration
+}
+
+// ConfigFromEnv reads RATING_K_FACTOR, RATING_DECAY_AFTER and
+// RATING_DECAY_HALF_LIFE, falling back to the defaults.
+func ConfigFromEnv() Config {
+	config := Config{
+		Initial:    defaultInitial,
+		KFactor:    defaultKFactor,
+		DecayAfter: defaultDecayAfter,
+		HalfLife:   defaultHalfLife,
+	}
+	if k, err := strconv.ParseFloat(os.Getenv("RATING_K_FACTOR"), 64); err == nil && k > 0 {
+		config.KFactor = k
+	}
+	if after, err := time.ParseDuration(os.Getenv("RATING_DECAY_AFTER")); err == nil && after >= 0 {
+		config.DecayAfter = after
+	}
+	if halfLife, err := time.ParseDuration(os.Getenv("RATING_DECAY_HALF_LIFE")); err == nil && halfLife > 0 {
+		config.HalfLife = halfLife
+	}
+	return config
+}
+
+// Decayed returns the rating as of at for a user who last played at
+// lastPlayed.
+func (c Config) Decayed(rating float64, lastPlayed, at time.Time) float64 {
+	inactive := at.Sub(lastPlayed) - c.DecayAfter
+	if inactive <= 0 {
+		return rating
+	}
+	factor := math.Pow(0.5, float64(inactive)/float64(c.HalfLife))
+	return c.Initial + (rating-c.Initial)*factor
+}
+
+// Expected is the probability that a player rated a beats one rated b.
+func (c Config) Expected(a, b float64) float64 {
+	return 1 / (1 + math.Pow(10, (b-a)/400))
+}
+
+// Update returns both new ratings after a game in which the first player
+// scored scoreA: 1 for a win, 0.5 for a draw and 0 for a loss.
+func (c Config) Update(a, b, scoreA float64) (float64, float64) {
+	delta := c.KFactor * (scoreA - c.Expected(a, b))
+	return a + delta, b - delta
+}
+
+// Game is one rated result between two users.
+type Game struct {
+	Source   string
+	SourceID pgtype.UUID
+	PlayerA  string
+	PlayerB  string
+	ScoreA   float64
+	PlayedAt time.Time
+}
+
+// Change is how a game moved one player's rating.
+type Change struct {
+	UserID string
+	Before float64
+	After  float64
+}
+
+// Apply rates a game and records it in both players' history. Run it inside
+// a transaction; the players' rows are locked while they are updated.
+//
+// Players without a rating get an initial row first so there is a row to
+// lock, and both are locked in user ID order so that concurrent games
+// between the same players cannot deadlock.
+func (c Config) Apply(ctx context.Context, queries *database.Queries, game Game) ([2]Change, error) {
+	var changes [2]Change
+	if game.PlayerA == game.PlayerB {
+		return changes, errors.New("a player cannot be rated against themselves")
+	}
+
+	playedAt := pgtype.Timestamp{Time: game.PlayedAt.UTC(), Valid: true}
+	players := [2]string{game.PlayerA, game.PlayerB}
+	order := []int{0, 1}
+	if players[1] < players[0] {
+		order = []int{1, 0}
+	}
+
+	before := [2]float64{}
+	for _, i := range order {
+		if err := queries.CreateInitialUserRating(ctx, database.CreateInitialUserRatingParams{
+			UserID:       players[i],
+			Rating:       c.Initial,
+			LastPlayedAt: playedAt,
+		}); err != nil {
+			return changes, err
+		}
+		current, err := queries.GetUserRatingForUpdate(ctx, players[i])
+		if err != nil {
+			return changes, err
+		}
+		before[i] = c.Decayed(current.Rating, current.LastPlayedAt.Time, game.PlayedAt)
+	}
+
+	afterA, afterB := c.Update(before[0], before[1], game.ScoreA)
+	changes[0] = Change{UserID: game.PlayerA, Before: before[0], After: afterA}
+	changes[1] = Change{UserID: game.PlayerB, Before: before[1], After: afterB}
+
+	scores := [2]float64{game.ScoreA, 1 - game.ScoreA}
+	opponents := [2]string{game.PlayerB, game.PlayerA}
+	for i, change := range changes {
+		if err := queries.SaveUserRating(ctx, database.SaveUserRatingParams{
+			UserID:       change.UserID,
+			Rating:       change.After,
+			LastPlayedAt: playedAt,
+		}); err != nil {
+			return changes, err
+		}
+		if err := queries.CreateRatingHistory(ctx, database.CreateRatingHistoryParams{
+			UserID:       change.UserID,
+			OpponentID:   opponents[i],
+			Source:       game.Source,
+			SourceID:     game.SourceID,
+			Score:        scores[i],
+			RatingBefore: change.Before,
+			RatingAfter:  change.After,
+			PlayedAt:     playedAt,
+		}); err != nil {
+			return changes, err
+		}
+	}
+
+	return changes, nil
+}
+
+// Replay discards all ratings and recomputes them from every rated duel and
+// match in the order they were played. Run it inside a transaction so
+// readers never see a half-replayed state. It returns the number of games.
+func (c Config) Replay(ctx context.Context, queries *database.Queries) (int, error) {
+	if err := queries.DeleteAllRatingHistory(ctx); err != nil {
+		return 0, err
+	}
+	if err := queries.DeleteAllUserRatings(ctx); err != nil {
+		return 0, err
+	}
+
+	games, err := queries.GetRatedGames(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, game := range games {
+		if _, err := c.Apply(ctx, queries, Game{
+			Source:   game.Source,
+			SourceID: game.SourceID,
+			PlayerA:  game.PlayerA,
+			PlayerB:  game.PlayerB,
+			ScoreA:   game.ScoreA,
+			PlayedAt: game.PlayedAt.Time,
+		}); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(games), nil
+}
@@ -0,0 +1,73 @@
+package rating
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+var testConfig = Config{
+	Initial:    1500,
+	KFactor:    32,
+	DecayAfter: 30 * 24 * time.Hour,
+	HalfLife:   90 * 24 * time.Hour,
+}
+
+func TestUpdate(t *testing.T) {
+	tests := []struct {
+		name   string
+		a, b   float64
+		scoreA float64
+		wantA  float64
+		wantB  float64
+	}{
+		{"equal win", 1500, 1500, 1, 1516, 1484},
+		{"equal draw", 1500, 1500, 0.5, 1500, 1500},
+		{"equal loss", 1500, 1500, 0, 1484, 1516},
+		{"favourite wins", 1900, 1500, 1, 1900 + 32.0/11, 1500 - 32.0/11},
+		{"underdog wins", 1500, 1900, 1, 1500 + 320.0/11, 1900 - 320.0/11},
+		{"favourite draws", 1900, 1500, 0.5, 1900 - 32*(10.0/11-0.5), 1500 + 32*(10.0/11-0.5)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotA, gotB := testConfig.Update(tt.a, tt.b, tt.scoreA)
+			if math.Abs(gotA-tt.wantA) > 1e-9 || math.Abs(gotB-tt.wantB) > 1e-9 {
+				t.Errorf("Update(%v, %v, %v) = %v, %v, want %v, %v", tt.a, tt.b, tt.scoreA, gotA, gotB, tt.wantA, tt.wantB)
+			}
+			if math.Abs(gotA+gotB-tt.a-tt.b) > 1e-9 {
+				t.Errorf("Update(%v, %v, %v) changed the rating sum to %v", tt.a, tt.b, tt.scoreA, gotA+gotB)
+			}
+		})
+	}
+}
+
+func TestDecayed(t *testing.T) {
+	day := 24 * time.Hour
+	lastPlayed := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		rating   float64
+		inactive time.Duration
+		want     float64
+	}{
+		{"just played", 1700, 0, 1700},
+		{"within grace period", 1700, 10 * day, 1700},
+		{"end of grace period", 1700, 30 * day, 1700},
+		{"one half-life", 1700, 120 * day, 1600},
+		{"two half-lives", 1700, 210 * day, 1550},
+		{"below initial rises", 1300, 120 * day, 1400},
+		{"initial stays", 1500, 400 * day, 1500},
+		{"played after reading", 1700, -5 * day, 1700},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := testConfig.Decayed(tt.rating, lastPlayed, lastPlayed.Add(tt.inactive))
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Decayed(%v) after %v = %v, want %v", tt.rating, tt.inactive, got, tt.want)
+			}
+		})
+	}
+}
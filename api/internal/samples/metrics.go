@@ -0,0 +1,98 @@
+package samples
+
+import "math"
+
+// StallThreshold is the flow in L/min below which the drink counts as
+// stalled, or not yet started.
+const StallThreshold = 0.5
+
+// MinStall is the shortest interruption in milliseconds reported as a stall;
+// shorter dips are sensor noise.
+const MinStall = 250
+
+// Stall is an interruption of the flow, in milliseconds since the start of
+// the run.
+type Stall struct {
+	Start uint32 `json:"start"`
+	End   uint32 `json:"end"`
+}
+
+// Metrics are the run figures derived from a flow curve, in the units the
+// device reports: seconds, litres and litres per minute. Duration spans from
+// the first to the last sample with flow; time to peak counts from the first.
+type Metrics struct {
+	Duration    float64 `json:"duration"`
+	Volume      float64 `json:"volume"`
+	AverageFlow float64 `json:"averageFlow"`
+	PeakFlow    float64 `json:"peakFlow"`
+	TimeToPeak  float64 `json:"timeToPeak"`
+	Stalls      []Stall `json:"stalls"`
+	StallTime   float64 `json:"stallTime"`
+}
+
+// Derive computes the metrics of a validated curve. Volume integrates the
+// whole curve with the trapezoidal rule, so trickles outside the active span
+// still count.
+func Derive(curve []Sample) Metrics {
+	metrics := Metrics{Stalls: []Stall{}}
+
+	for i := 1; i < len(curve); i++ {
+		dt := float64(curve[i].T - curve[i-1].T)
+		metrics.Volume += (float64(curve[i].Flow) + float64(curve[i-1].Flow)) / 2 * dt / 60000
+	}
+
+	first, last := -1, -1
+	for i, sample := range curve {
+		if float64(sample.Flow) >= StallThreshold {
+			if first < 0 {
+				first = i
+			}
+			last = i
+		}
+	}
+	if first < 0 {
+		return metrics
+	}
+
+	metrics.Duration = float64(curve[last].T-curve[first].T) / 1000
+	if metrics.Duration > 0 {
+		metrics.AverageFlow = metrics.Volume / metrics.Duration * 60
+	}
+
+	peak := first
+	stallStart := -1
+	for i := first; i <= last; i++ {
+		flow := float64(curve[i].Flow)
+		if flow > float64(curve[peak].Flow) {
+			peak = i
+		}
+
+		switch {
+		case flow < StallThreshold && stallStart < 0:
+			stallStart = i
+		case flow >= StallThreshold && stallStart >= 0:
+			stall := Stall{Start: curve[stallStart].T, End: curve[i].T}
+			if stall.End-stall.Start >= MinStall {
+				metrics.Stalls = append(metrics.Stalls, stall)
+				metrics.StallTime += float64(stall.End-stall.Start) / 1000
+			}
+			stallStart = -1
+		}
+	}
+	metrics.PeakFlow = float64(curve[peak].Flow)
+	metrics.TimeToPeak = float64(curve[peak].T-curve[first].T) / 1000
+
+	return metrics
+}
+
+// Deviation is the relative difference of a reported value from the derived
+// one. A value reported for nothing measured deviates infinitely.
+func Deviation(reported, derived float64) float64 {
+	if derived == 0 {
+		if reported == 0 {
+			return 0
+		}
+		return math.Inf(1)
+	}
+	return math.Abs(reported-derived) / derived
+}
@@ -0,0 +1,71 @@
+package samples
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestDerive(t *testing.T) {
+	tests := []struct {
+		name  string
+		curve []Sample
+		want  Metrics
+	}{
+		{
+			name:  "steady flow",
+			curve: []Sample{{0, 0}, {1000, 6}, {2000, 6}, {3000, 0}},
+			want:  Metrics{Duration: 1, Volume: 0.2, AverageFlow: 12, PeakFlow: 6, Stalls: []Stall{}},
+		},
+		{
+			name:  "trickle only",
+			curve: []Sample{{0, 0}, {100, 0.2}},
+			want:  Metrics{Volume: 0.1 * 100 / 60000, Stalls: []Stall{}},
+		},
+		{
+			name:  "single sample",
+			curve: []Sample{{0, 4}},
+			want:  Metrics{PeakFlow: 4, Stalls: []Stall{}},
+		},
+		{
+			name:  "peak after start",
+			curve: []Sample{{0, 1}, {1000, 2}, {2000, 8}, {3000, 4}},
+			want:  Metrics{Duration: 3, Volume: 12.5 / 60, AverageFlow: 12.5 / 60 / 3 * 60, PeakFlow: 8, TimeToPeak: 2, Stalls: []Stall{}},
+		},
+		{
+			name:  "stall",
+			curve: []Sample{{0, 6}, {500, 0}, {1000, 6}},
+			want:  Metrics{Duration: 1, Volume: 0.05, AverageFlow: 3, PeakFlow: 6, Stalls: []Stall{{500, 1000}}, StallTime: 0.5},
+		},
+		{
+			name:  "dip too short for a stall",
+			curve: []Sample{{0, 6}, {100, 0}, {200, 6}},
+			want:  Metrics{Duration: 0.2, Volume: 0.01, AverageFlow: 3, PeakFlow: 6, Stalls: []Stall{}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Derive(tt.curve)
+			figures := []struct {
+				name      string
+				got, want float64
+			}{
+				{"Duration", got.Duration, tt.want.Duration},
+				{"Volume", got.Volume, tt.want.Volume},
+				{"AverageFlow", got.AverageFlow, tt.want.AverageFlow},
+				{"PeakFlow", got.PeakFlow, tt.want.PeakFlow},
+				{"TimeToPeak", got.TimeToPeak, tt.want.TimeToPeak},
+				{"StallTime", got.StallTime, tt.want.StallTime},
+			}
+			for _, figure := range figures {
+				if math.Abs(figure.got-figure.want) > 1e-9 {
+					t.Errorf("%s = %v, want %v", figure.name, figure.got, figure.want)
+				}
+			}
+			if !slices.Equal(got.Stalls, tt.want.Stalls) {
+				t.Errorf("Stalls = %v, want %v", got.Stalls, tt.want.Stalls)
+			}
+		})
+	}
+}
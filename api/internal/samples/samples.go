@@ -0,0 +1,213 @@
+// Package samples handles the raw flow curve a device measures during a run.
+//
+// A sample is the flow at a point in time, given in milliseconds since the
+// start of the run. Curves are stored in a compact encoding: a version byte,
+// the sample count and then, per sample, the time delta to the previous
+// sample as a uvarint followed by the flow as a little-endian float32. At the
+// usual sensor rates that is about five bytes per sample.
+package samples
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// MaxSamples bounds a single curve, enough for several minutes at 100 Hz.
+const MaxSamples = 50000
+
+// RecordSize is the size of one sample in the upload binary format: the
+// time as little-endian uint32 followed by the flow as little-endian float32.
+const RecordSize = 8
+
+const encodingVersion = 1
+
+type Sample struct {
+	T    uint32  `json:"t"`
+	Flow float32 `json:"flow"`
+}
+
+// Validate checks that a curve is non-empty, not too long, strictly ordered
+// in time and has finite, non-negative flow values.
+func Validate(curve []Sample) error {
+	if len(curve) == 0 {
+		return errors.New("at least one sample is required")
+	}
+	if len(curve) > MaxSamples {
+		return fmt.Errorf("at most %d samples are allowed", MaxSamples)
+	}
+	for i, sample := range curve {
+		if i > 0 && sample.T <= curve[i-1].T {
+			return fmt.Errorf("sample %d: times must be strictly increasing", i)
+		}
+		flow := float64(sample.Flow)
+		if math.IsNaN(flow) || math.IsInf(flow, 0) || flow < 0 {
+			return fmt.Errorf("sample %d: flow must be a non-negative number", i)
+		}
+	}
+	return nil
+}
+
+// Encode packs a validated curve into the storage encoding.
+func Encode(curve []Sample) []byte {
+	buf := make([]byte, 0, 1+binary.MaxVarintLen64+len(curve)*(binary.MaxVarintLen32+4))
+	buf = append(buf, encodingVersion)
+	buf = binary.AppendUvarint(buf, uint64(len(curve)))
+	var previous uint32
+	for _, sample := range curve {
+		buf = binary.AppendUvarint(buf, uint64(sample.T-previous))
+		buf = binary.LittleEndian.AppendUint32(buf, math.Float32bits(sample.Flow))
+		previous = sample.T
+	}
+	return buf
+}
+
+// Decode unpacks a curve stored by Encode.
+func Decode(data []byte) ([]Sample, error) {
+	if len(data) == 0 || data[0] != encodingVersion {
+		return nil, errors.New("unsupported sample encoding")
+	}
+	data = data[1:]
+
+	count, n := binary.Uvarint(data)
+	if n <= 0 || count > MaxSamples {
+		return nil, errors.New("corrupt sample count")
+	}
+	data = data[n:]
+
+	curve := make([]Sample, 0, count)
+	var t uint64
+	for i := uint64(0); i < count; i++ {
+		delta, n := binary.Uvarint(data)
+		if n <= 0 || len(data) < n+4 {
+			return nil, errors.New("truncated sample data")
+		}
+		t += delta
+		if t > math.MaxUint32 {
+			return nil, errors.New("corrupt sample time")
+		}
+		curve = append(curve, Sample{
+			T:    uint32(t),
+			Flow: math.Float32frombits(binary.LittleEndian.Uint32(data[n:])),
+		})
+		data = data[n+4:]
+	}
+	return curve, nil
+}
+
+// ReadBinary reads fixed-size records as described by RecordSize.
+func ReadBinary(r io.Reader) ([]Sample, error) {
+	var curve []Sample
+	record := make([]byte, RecordSize)
+	reader := bufio.NewReader(r)
+	for {
+		_, err := io.ReadFull(reader, record)
+		if err == io.EOF {
+			return curve, nil
+		}
+		if err == io.ErrUnexpectedEOF {
+			return nil, fmt.Errorf("body length must be a multiple of %d bytes", RecordSize)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(curve) == MaxSamples {
+			return nil, fmt.Errorf("at most %d samples are allowed", MaxSamples)
+		}
+		curve = append(curve, Sample{
+			T:    binary.LittleEndian.Uint32(record[0:4]),
+			Flow: math.Float32frombits(binary.LittleEndian.Uint32(record[4:8])),
+		})
+	}
+}
+
+// ReadCSV reads "t,flow" lines. A header line starting with a letter is
+// skipped.
+func ReadCSV(r io.Reader) ([]Sample, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 2
+	reader.TrimLeadingSpace = true
+	reader.ReuseRecord = true
+
+	var curve []Sample
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return curve, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if line == 1 && isHeader(record[0]) {
+			continue
+		}
+		if len(curve) == MaxSamples {
+			return nil, fmt.Errorf("at most %d samples are allowed", MaxSamples)
+		}
+
+		t, err := strconv.ParseUint(strings.TrimSpace(record[0]), 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: t must be milliseconds since the start of the run", line)
+		}
+		flow, err := strconv.ParseFloat(strings.TrimSpace(record[1]), 32)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: flow must be a number", line)
+		}
+		curve = append(curve, Sample{T: uint32(t), Flow: float32(flow)})
+	}
+}
+
+func isHeader(field string) bool {
+	field = strings.TrimSpace(field)
+	return field != "" && (field[0] < '0' || field[0] > '9')
+}
+
+// Downsample reduces a curve to at most points samples using the
+// largest-triangle-three-buckets algorithm, which keeps the peaks and dips a
+// plot needs. The first and last samples are always kept, so points below 3
+// leave the curve unchanged.
+func Downsample(curve []Sample, points int) []Sample {
+	if points >= len(curve) || points < 3 {
+		return curve
+	}
+
+	result := make([]Sample, 0, points)
+	result = append(result, curve[0])
+
+	bucketSize := float64(len(curve)-2) / float64(points-2)
+	selected := 0
+	for bucket := 0; bucket < points-2; bucket++ {
+		start := int(float64(bucket)*bucketSize) + 1
+		end := int(float64(bucket+1)*bucketSize) + 1
+
+		// The next bucket is represented by its average point; the last
+		// bucket looks ahead to the final sample.
+		nextStart, nextEnd := end, min(int(float64(bucket+2)*bucketSize)+1, len(curve))
+		var avgT, avgFlow float64
+		for _, sample := range curve[nextStart:nextEnd] {
+			avgT += float64(sample.T)
+			avgFlow += float64(sample.Flow)
+		}
+		avgT /= float64(nextEnd - nextStart)
+		avgFlow /= float64(nextEnd - nextStart)
+
+		a := curve[selected]
+		maxArea := -1.0
+		for i := start; i < end; i++ {
+			area := math.Abs((float64(a.T)-avgT)*(float64(curve[i].Flow)-float64(a.Flow)) -
+				(float64(a.T)-float64(curve[i].T))*(avgFlow-float64(a.Flow)))
+			if area > maxArea {
+				maxArea, selected = area, i
+			}
+		}
+		result = append(result, curve[selected])
+	}
+
+	return append(result, curve[len(curve)-1])
+}
@@ -0,0 +1,120 @@
+package samples
+
+import (
+	"encoding/binary"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	tests := []struct {
+		name     string
+		curve    []Sample
+		wantSize int
+	}{
+		{"single sample", []Sample{{T: 0, Flow: 0}}, 1 + 1 + 5},
+		{"small deltas", []Sample{{T: 0, Flow: 0}, {T: 10, Flow: 1.5}, {T: 20, Flow: 6.25}}, 1 + 1 + 3*5},
+		{"large deltas", []Sample{{T: 5, Flow: 0.5}, {T: 70000, Flow: 12}, {T: math.MaxUint32, Flow: 0}}, 1 + 1 + 5 + 7 + 9},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := Encode(tt.curve)
+			if len(data) != tt.wantSize {
+				t.Errorf("len(Encode()) = %d, want %d", len(data), tt.wantSize)
+			}
+			got, err := Decode(data)
+			if err != nil {
+				t.Fatalf("Decode(Encode()) failed: %v", err)
+			}
+			if !slices.Equal(got, tt.curve) {
+				t.Errorf("Decode(Encode()) = %v, want %v", got, tt.curve)
+			}
+		})
+	}
+}
+
+func TestDecodeRejects(t *testing.T) {
+	valid := Encode([]Sample{{T: 0, Flow: 1}, {T: 10, Flow: 2}})
+	overflow := []byte{encodingVersion, 2}
+	overflow = binary.AppendUvarint(overflow, math.MaxUint32)
+	overflow = binary.LittleEndian.AppendUint32(overflow, 0)
+	overflow = binary.AppendUvarint(overflow, 1)
+	overflow = binary.LittleEndian.AppendUint32(overflow, 0)
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr string
+	}{
+		{"empty", nil, "unsupported sample encoding"},
+		{"unknown version", append([]byte{2}, valid[1:]...), "unsupported sample encoding"},
+		{"missing count", []byte{encodingVersion}, "corrupt sample count"},
+		{"too many samples", binary.AppendUvarint([]byte{encodingVersion}, MaxSamples+1), "corrupt sample count"},
+		{"truncated", valid[:len(valid)-1], "truncated sample data"},
+		{"time overflow", overflow, "corrupt sample time"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			curve, err := Decode(tt.data)
+			if err == nil {
+				t.Fatalf("Decode() = %v, want an error containing %q", curve, tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Decode() error = %q, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDownsample(t *testing.T) {
+	flat := func(n int) []Sample {
+		curve := make([]Sample, n)
+		for i := range curve {
+			curve[i] = Sample{T: uint32(i * 10), Flow: 1}
+		}
+		return curve
+	}
+	spiked := flat(100)
+	spiked[37].Flow = 9
+	dipped := flat(100)
+	dipped[62].Flow = 0
+
+	tests := []struct {
+		name     string
+		curve    []Sample
+		points   int
+		wantLen  int
+		wantKept []Sample
+	}{
+		{"fewer samples than points", flat(5), 10, 5, nil},
+		{"as many samples as points", flat(5), 5, 5, nil},
+		{"too few points", flat(100), 2, 100, nil},
+		{"keeps first and last", flat(100), 10, 10, []Sample{{T: 0, Flow: 1}, {T: 990, Flow: 1}}},
+		{"keeps peak", spiked, 10, 10, []Sample{{T: 370, Flow: 9}}},
+		{"keeps dip", dipped, 10, 10, []Sample{{T: 620, Flow: 0}}},
+		{"three points", spiked, 3, 3, []Sample{{T: 0, Flow: 1}, {T: 370, Flow: 9}, {T: 990, Flow: 1}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Downsample(tt.curve, tt.points)
+			if len(got) != tt.wantLen {
+				t.Fatalf("len(Downsample(%d)) = %d, want %d", tt.points, len(got), tt.wantLen)
+			}
+			for i := 1; i < len(got); i++ {
+				if got[i].T <= got[i-1].T {
+					t.Fatalf("Downsample() is not ordered in time: %v", got)
+				}
+			}
+			for _, sample := range tt.wantKept {
+				if !slices.Contains(got, sample) {
+					t.Errorf("Downsample() = %v, want it to keep %v", got, sample)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,361 @@
+// Package scoring implements the small expression language admins use to
+// define ranking formulas over run measurements, e.g.
+//
+//	rate / sqrt(volume)
+//	volume / duration * 100
+//
+// Expressions support numbers, the variables duration, rate and volume, the
+// operators + - * / ^ with the usual precedence, parentheses and a fixed set
+// of functions. There are no side effects, loops or user-defined names, so a
+// validated expression is always safe to evaluate.
+package scoring
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	maxExpressionLength = 500
+	maxExpressionDepth  = 32
+)
+
+// Variables are the run fields an expression may refer to.
+var Variables = []string{"duration", "rate", "volume"}
+
+type function struct {
+	minArgs int
+	maxArgs int
+	apply   func(args []float64) float64
+}
+
+var functions = map[string]function{
+	"abs":  {1, 1, func(a []float64) float64 { return math.Abs(a[0]) }},
+	"sqrt": {1, 1, func(a []float64) float64 { return math.Sqrt(a[0]) }},
+	"log":  {1, 1, func(a []float64) float64 { return math.Log(a[0]) }},
+	"pow":  {2, 2, func(a []float64) float64 { return math.Pow(a[0], a[1]) }},
+	"min": {2, 8, func(a []float64) float64 {
+		result := a[0]
+		for _, v := range a[1:] {
+			result = math.Min(result, v)
+		}
+		return result
+	}},
+	"max": {2, 8, func(a []float64) float64 {
+		result := a[0]
+		for _, v := range a[1:] {
+			result = math.Max(result, v)
+		}
+		return result
+	}},
+}
+
+// Expression is a parsed, validated formula.
+type Expression struct {
+	source string
+	root   node
+}
+
+// Parse validates source and returns the compiled expression.
+func Parse(source string) (*Expression, error) {
+	if strings.TrimSpace(source) == "" {
+		return nil, errors.New("expression is empty")
+	}
+	if len(source) > maxExpressionLength {
+		return nil, fmt.Errorf("expression is longer than %d characters", maxExpressionLength)
+	}
+
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	root, err := p.parseExpression(0)
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", p.peek().text, p.peek().pos)
+	}
+
+	return &Expression{source: source, root: root}, nil
+}
+
+func (e *Expression) String() string {
+	return e.source
+}
+
+// Evaluate computes the expression for one run. It fails if the result is
+// not a finite number, e.g. after a division by zero.
+func (e *Expression) Evaluate(vars map[string]float64) (float64, error) {
+	result := e.root.eval(vars)
+	if math.IsNaN(result) || math.IsInf(result, 0) {
+		return 0, errors.New("expression does not evaluate to a finite number")
+	}
+	return result, nil
+}
+
+type node interface {
+	eval(vars map[string]float64) float64
+}
+
+type numberNode float64
+
+func (n numberNode) eval(map[string]float64) float64 { return float64(n) }
+
+type variableNode string
+
+func (n variableNode) eval(vars map[string]float64) float64 { return vars[string(n)] }
+
+type unaryNode struct {
+	operand node
+}
+
+func (n unaryNode) eval(vars map[string]float64) float64 { return -n.operand.eval(vars) }
+
+type binaryNode struct {
+	op          byte
+	left, right node
+}
+
+func (n binaryNode) eval(vars map[string]float64) float64 {
+	left, right := n.left.eval(vars), n.right.eval(vars)
+	switch n.op {
+	case '+':
+		return left + right
+	case '-':
+		return left - right
+	case '*':
+		return left * right
+	case '/':
+		return left / right
+	default:
+		return math.Pow(left, right)
+	}
+}
+
+type callNode struct {
+	fn   function
+	args []node
+}
+
+func (n callNode) eval(vars map[string]float64) float64 {
+	values := make([]float64, len(n.args))
+	for i, arg := range n.args {
+		values[i] = arg.eval(vars)
+	}
+	return n.fn.apply(values)
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNumber
+	tokenIdent
+	tokenOperator
+	tokenLParen
+	tokenRParen
+	tokenComma
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func tokenize(source string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(source); {
+		ch := rune(source[i])
+		switch {
+		case unicode.IsSpace(ch):
+			i++
+		case unicode.IsDigit(ch) || ch == '.':
+			start := i
+			for i < len(source) && (unicode.IsDigit(rune(source[i])) || source[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{tokenNumber, source[start:i], start})
+		case unicode.IsLetter(ch) || ch == '_':
+			start := i
+			for i < len(source) && (unicode.IsLetter(rune(source[i])) || unicode.IsDigit(rune(source[i])) || source[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, token{tokenIdent, source[start:i], start})
+		case strings.ContainsRune("+-*/^", ch):
+			tokens = append(tokens, token{tokenOperator, string(ch), i})
+			i++
+		case ch == '(':
+			tokens = append(tokens, token{tokenLParen, "(", i})
+			i++
+		case ch == ')':
+			tokens = append(tokens, token{tokenRParen, ")", i})
+			i++
+		case ch == ',':
+			tokens = append(tokens, token{tokenComma, ",", i})
+			i++
+		default:
+			return nil, fmt.Errorf("unexpected character %q at position %d", ch, i)
+		}
+	}
+	return append(tokens, token{tokenEOF, "end of expression", len(source)}), nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func precedence(op string) int {
+	switch op {
+	case "+", "-":
+		return 1
+	case "*", "/":
+		return 2
+	default:
+		return 3
+	}
+}
+
+// parseExpression is a precedence-climbing parser. ^ is right associative,
+// everything else left associative.
+func (p *parser) parseExpression(depth int) (node, error) {
+	return p.parseBinary(1, depth)
+}
+
+func (p *parser) parseBinary(minPrecedence, depth int) (node, error) {
+	left, err := p.parseUnary(depth)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		t := p.peek()
+		if t.kind != tokenOperator || precedence(t.text) < minPrecedence {
+			return left, nil
+		}
+		p.next()
+
+		nextPrecedence := precedence(t.text) + 1
+		if t.text == "^" {
+			nextPrecedence = precedence(t.text)
+		}
+		right, err := p.parseBinary(nextPrecedence, depth)
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: t.text[0], left: left, right: right}
+	}
+}
+
+func (p *parser) parseUnary(depth int) (node, error) {
+	if depth > maxExpressionDepth {
+		return nil, errors.New("expression is nested too deeply")
+	}
+
+	t := p.peek()
+	if t.kind == tokenOperator && (t.text == "-" || t.text == "+") {
+		p.next()
+		// Bind looser than ^ so -2^2 is -(2^2).
+		operand, err := p.parseBinary(precedence("^"), depth+1)
+		if err != nil {
+			return nil, err
+		}
+		if t.text == "+" {
+			return operand, nil
+		}
+		return unaryNode{operand: operand}, nil
+	}
+
+	return p.parsePrimary(depth)
+}
+
+func (p *parser) parsePrimary(depth int) (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenNumber:
+		value, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at position %d", t.text, t.pos)
+		}
+		return numberNode(value), nil
+
+	case tokenIdent:
+		if p.peek().kind == tokenLParen {
+			return p.parseCall(t, depth)
+		}
+		for _, variable := range Variables {
+			if t.text == variable {
+				return variableNode(t.text), nil
+			}
+		}
+		return nil, fmt.Errorf("unknown variable %q at position %d, expected one of %s", t.text, t.pos, strings.Join(Variables, ", "))
+
+	case tokenLParen:
+		inner, err := p.parseBinary(1, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenRParen {
+			return nil, fmt.Errorf("expected ) at position %d", closing.pos)
+		}
+		return inner, nil
+
+	default:
+		return nil, fmt.Errorf("unexpected %q at position %d", t.text, t.pos)
+	}
+}
+
+func (p *parser) parseCall(name token, depth int) (node, error) {
+	fn, ok := functions[name.text]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %q at position %d", name.text, name.pos)
+	}
+	p.next() // (
+
+	var args []node
+	if p.peek().kind != tokenRParen {
+		for {
+			arg, err := p.parseBinary(1, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if p.peek().kind != tokenComma {
+				break
+			}
+			p.next()
+		}
+	}
+	if closing := p.next(); closing.kind != tokenRParen {
+		return nil, fmt.Errorf("expected ) at position %d", closing.pos)
+	}
+
+	if len(args) < fn.minArgs || len(args) > fn.maxArgs {
+		if fn.minArgs == fn.maxArgs {
+			return nil, fmt.Errorf("%s takes %d argument(s), got %d", name.text, fn.minArgs, len(args))
+		}
+		return nil, fmt.Errorf("%s takes %d to %d arguments, got %d", name.text, fn.minArgs, fn.maxArgs, len(args))
+	}
+
+	return callNode{fn: fn, args: args}, nil
+}
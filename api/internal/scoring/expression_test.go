@@ -0,0 +1,141 @@
+package scoring
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+var testVars = map[string]float64{"duration": 2, "rate": 6, "volume": 4}
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		source string
+		want   float64
+	}{
+		{"42", 42},
+		{"1.5", 1.5},
+		{".5", 0.5},
+		{"1 + 2 * 3", 7},
+		{"(1 + 2) * 3", 9},
+		{"10 - 4 - 3", 3},
+		{"8 / 4 / 2", 1},
+		{"2 * 3 ^ 2", 18},
+		{"2 ^ 3 ^ 2", 512},
+		{"(2 ^ 3) ^ 2", 64},
+		{"-2", -2},
+		{"+2", 2},
+		{"--2", 2},
+		{"-2 ^ 2", -4},
+		{"(-2) ^ 2", 4},
+		{"2 * -3", -6},
+		{"1 - -1", 2},
+		{"-rate + volume", -2},
+		{"rate / sqrt(volume)", 3},
+		{"volume / duration * 100", 200},
+		{"abs(duration - rate)", 4},
+		{"log(1)", 0},
+		{"pow(2, 10)", 1024},
+		{"min(rate, volume)", 4},
+		{"max(duration, rate, volume)", 6},
+		{"max(1, 2, 3, 4, 5, 6, 7, 8)", 8},
+		{"-max(rate, volume) * 2", -12},
+		{"  rate\t*\nvolume  ", 24},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			expr, err := Parse(tt.source)
+			if err != nil {
+				t.Fatalf("Parse(%q) failed: %v", tt.source, err)
+			}
+			got, err := expr.Evaluate(testVars)
+			if err != nil {
+				t.Fatalf("Evaluate(%q) failed: %v", tt.source, err)
+			}
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Evaluate(%q) = %v, want %v", tt.source, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluateNotFinite(t *testing.T) {
+	tests := []struct {
+		source string
+		vars   map[string]float64
+	}{
+		{"rate / 0", testVars},
+		{"0 / 0", testVars},
+		{"volume / duration", map[string]float64{"duration": 0, "rate": 6, "volume": 4}},
+		{"log(0)", testVars},
+		{"sqrt(-1)", testVars},
+		{"10 ^ 400", testVars},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			expr, err := Parse(tt.source)
+			if err != nil {
+				t.Fatalf("Parse(%q) failed: %v", tt.source, err)
+			}
+			if got, err := expr.Evaluate(tt.vars); err == nil {
+				t.Errorf("Evaluate(%q) = %v, want an error", tt.source, got)
+			}
+		})
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		wantErr string
+	}{
+		{"empty", "", "expression is empty"},
+		{"blank", "   ", "expression is empty"},
+		{"too long", strings.Repeat("1+", 250) + "1", "longer than 500 characters"},
+		{"unknown variable", "speed * 2", `unknown variable "speed"`},
+		{"unknown function", "floor(rate)", `unknown function "floor"`},
+		{"variable called", "rate(2)", `unknown function "rate"`},
+		{"bad character", "rate % 2", "unexpected character '%'"},
+		{"bad number", "1..2", `invalid number "1..2"`},
+		{"missing operand", "1 +", `unexpected "end of expression"`},
+		{"leading operator", "* 2", `unexpected "*"`},
+		{"missing operator", "1 2", `unexpected "2"`},
+		{"adjacent variables", "rate volume", `unexpected "volume"`},
+		{"unclosed paren", "(1 + 2", "expected )"},
+		{"extra paren", "1 + 2)", `unexpected ")"`},
+		{"empty parens", "()", `unexpected ")"`},
+		{"trailing comma", "pow(1,)", `unexpected ")"`},
+		{"unclosed call", "sqrt(4", "expected )"},
+		{"too few arguments", "min(1)", "min takes 2 to 8 arguments, got 1"},
+		{"no arguments", "max()", "max takes 2 to 8 arguments, got 0"},
+		{"too many arguments", "abs(1, 2)", "abs takes 1 argument(s), got 2"},
+		{"nested too deeply", strings.Repeat("(", 40) + "1" + strings.Repeat(")", 40), "nested too deeply"},
+		{"negated too deeply", strings.Repeat("-", 40) + "1", "nested too deeply"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := Parse(tt.source)
+			if err == nil {
+				t.Fatalf("Parse(%q) = %v, want an error containing %q", tt.source, expr, tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Parse(%q) error = %q, want it to contain %q", tt.source, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestString(t *testing.T) {
+	source := "rate / sqrt(volume)"
+	expr, err := Parse(source)
+	if err != nil {
+		t.Fatalf("Parse(%q) failed: %v", source, err)
+	}
+	if got := expr.String(); got != source {
+		t.Errorf("String() = %q, want %q", got, source)
+	}
+}
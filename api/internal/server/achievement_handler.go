@@ -0,0 +1,89 @@
+package server
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type AchievementDao struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Unlocked    bool       `json:"unlocked"`
+	UnlockedAt  *time.Time `json:"unlockedAt"`
+	RunID       *string    `json:"runId"`
+}
+
+type AchievementUnlockedDao struct {
+	User        UserInfo       `json:"user"`
+	Achievement AchievementDao `json:"achievement"`
+}
+
+func newAchievementDao(rule achievementRule, runID pgtype.UUID, at time.Time) AchievementDao {
+	dao := AchievementDao{
+		ID:          rule.ID,
+		Name:        rule.Name,
+		Description: rule.Description,
+		Unlocked:    true,
+		UnlockedAt:  &at,
+	}
+	if runID.Valid {
+		id := runID.String()
+		dao.RunID = &id
+	}
+	return dao
+}
+
+func (s *Server) getUserAchievementsHandler(c *gin.Context) {
+	userID := c.Param("id")
+
+	if _, err := s.db.Queries().GetUserById(c.Request.Context(), userID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, APIResponse{
+				Success: false,
+				Error:   "User not found",
+			})
+			return
+		}
+		log.Printf("Error getting user: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to fetch achievements",
+		})
+		return
+	}
+
+	awards, err := s.db.Queries().GetUserAchievements(c.Request.Context(), userID)
+	if err != nil {
+		log.Printf("Error getting achievements: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to fetch achievements",
+		})
+		return
+	}
+
+	response := make([]AchievementDao, 0, len(achievementRules))
+	for _, rule := range achievementRules {
+		dao := AchievementDao{
+			ID:          rule.ID,
+			Name:        rule.Name,
+			Description: rule.Description,
+		}
+		for _, award := range awards {
+			if award.Achievement == rule.ID {
+				dao = newAchievementDao(rule, award.RunID, award.AchievedAt.Time)
+				break
+			}
+		}
+		response = append(response, dao)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,287 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/tt-trichter/app/api/internal/database"
+)
+
+const EventAchievementUnlocked = "achievement-unlocked"
+
+// achievementRule describes a badge declaratively. Every criterion that is set
+// must hold at the same run for the badge to unlock; run criteria are checked
+// against that run, history criteria against everything up to and including it.
+type achievementRule struct {
+	ID          string
+	Name        string
+	Description string
+
+	// History criteria.
+	MinRuns   int
+	MinNights int
+
+	// Run criteria.
+	MinVolume   float64
+	MaxDuration float64
+	MinRate     float64
+	Record      bool
+}
+
+var achievementRules = []achievementRule{
+	{
+		ID:          "first-run",
+		Name:        "First Run",
+		Description: "Record your first run",
+		MinRuns:     1,
+	},
+	{
+		ID:          "ten-runs",
+		Name:        "Regular",
+		Description: "Record 10 runs",
+		MinRuns:     10,
+	},
+	{
+		ID:          "fifty-runs",
+		Name:        "Veteran",
+		Description: "Record 50 runs",
+		MinRuns:     50,
+	},
+	{
+		ID:          "sub-2-second-litre",
+		Name:        "Sub-2-Second Litre",
+		Description: "Finish a litre in under two seconds",
+		MinVolume:   1,
+		MaxDuration: 2,
+	},
+	{
+		ID:          "three-nights",
+		Name:        "Hat Trick",
+		Description: "Run on three nights in a row",
+		MinNights:   3,
+	},
+	{
+		ID:          "record-breaker",
+		Name:        "Record Breaker",
+		Description: "Beat an all-time record",
+		Record:      true,
+	},
+}
+
+type achievementRun struct {
+	ID        pgtype.UUID
+	CreatedAt time.Time
+	Duration  float64
+	Rate      float64
+	Volume    float64
+	Records   []string
+}
+
+type achievementProgress struct {
+	runs   int
+	nights int
+}
+
+func (r achievementRule) satisfied(progress achievementProgress, run achievementRun) bool {
+	if progress.runs < r.MinRuns || progress.nights < r.MinNights {
+		return false
+	}
+	if r.MinVolume > 0 && run.Volume < r.MinVolume {
+		return false
+	}
+	if r.MaxDuration > 0 && run.Duration >= r.MaxDuration {
+		return false
+	}
+	if r.MinRate > 0 && run.Rate < r.MinRate {
+		return false
+	}
+	if r.Record && len(run.Records) == 0 {
+		return false
+	}
+	return true
+}
+
+type achievementUnlock struct {
+	Rule  achievementRule
+	RunID pgtype.UUID
+	At    time.Time
+}
+
+// evaluateAchievements replays a user's history, oldest run first, and
+// returns the run at which each rule was first satisfied.
+func (s *Server) evaluateAchievements(runs []achievementRun) []achievementUnlock {
+	var unlocks []achievementUnlock
+	unlocked := make(map[string]bool)
+
+	var progress achievementProgress
+	var streak nightStreak
+	for _, run := range runs {
+		streak.add(s.nights.night(run.CreatedAt))
+		progress.runs++
+		progress.nights = streak.length
+
+		for _, rule := range achievementRules {
+			if unlocked[rule.ID] || !rule.satisfied(progress, run) {
+				continue
+			}
+			unlocked[rule.ID] = true
+			unlocks = append(unlocks, achievementUnlock{Rule: rule, RunID: run.ID, At: run.CreatedAt})
+		}
+	}
+
+	return unlocks
+}
+
+// awardAchievements evaluates a user's history and stores any new badges.
+// Newly unlocked badges are announced unless announce is false, as during
+// the startup backfill.
+func (s *Server) awardAchievements(ctx context.Context, userID pgtype.Text, announce bool) {
+	if !userID.Valid {
+		return
+	}
+
+	runs, err := s.achievementRuns(ctx, userID)
+	if err != nil {
+		log.Printf("Error getting runs for achievements: %v", err)
+		return
+	}
+
+	s.storeUnlocks(ctx, userID, s.evaluateAchievements(runs), announce, nil)
+}
+
+// reevaluateAchievements brings a user's badges in line with their history
+// after a run left it. Badges the history no longer earns are revoked, and
+// badges now earned at a different run are moved to it. Only badges the user
+// did not hold before are announced.
+func (s *Server) reevaluateAchievements(ctx context.Context, userID pgtype.Text) {
+	if !userID.Valid {
+		return
+	}
+
+	runs, err := s.achievementRuns(ctx, userID)
+	if err != nil {
+		log.Printf("Error getting runs for achievements: %v", err)
+		return
+	}
+	stored, err := s.db.Queries().GetUserAchievements(ctx, userID.String)
+	if err != nil {
+		log.Printf("Error getting achievements of user %s: %v", userID.String, err)
+		return
+	}
+
+	unlocks := s.evaluateAchievements(runs)
+	earnedAt := make(map[string]pgtype.UUID, len(unlocks))
+	for _, unlock := range unlocks {
+		earnedAt[unlock.Rule.ID] = unlock.RunID
+	}
+
+	held := make(map[string]bool, len(stored))
+	for _, achievement := range stored {
+		held[achievement.Achievement] = true
+		runID, earned := earnedAt[achievement.Achievement]
+		if earned && runID == achievement.RunID {
+			continue
+		}
+		if err := s.db.Queries().RevokeAchievement(ctx, database.RevokeAchievementParams{
+			UserID:      userID.String,
+			Achievement: achievement.Achievement,
+		}); err != nil {
+			log.Printf("Error revoking achievement %s: %v", achievement.Achievement, err)
+			continue
+		}
+		if !earned {
+			log.Printf("User %s lost achievement %s", userID.String, achievement.Achievement)
+		}
+	}
+
+	s.storeUnlocks(ctx, userID, unlocks, true, held)
+}
+
+// achievementRuns loads a user's runs oldest first for evaluateAchievements.
+func (s *Server) achievementRuns(ctx context.Context, userID pgtype.Text) ([]achievementRun, error) {
+	stored, err := s.db.Queries().GetRunsByUserId(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	runs := make([]achievementRun, 0, len(stored))
+	for _, run := range stored {
+		var data RunData
+		if err := json.Unmarshal(run.Data, &data); err != nil {
+			log.Printf("Error unmarshaling run data: %v", err)
+			continue
+		}
+		runs = append(runs, achievementRun{
+			ID:        run.ID,
+			CreatedAt: run.CreatedAt.Time,
+			Duration:  float64(data.Duration),
+			Rate:      float64(data.Rate),
+			Volume:    float64(data.Volume),
+			Records:   run.Records,
+		})
+	}
+	slices.SortFunc(runs, func(a, b achievementRun) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+
+	return runs, nil
+}
+
+// storeUnlocks awards the unlocked badges a user does not have yet and, when
+// announce is set, announces those that are not in held.
+func (s *Server) storeUnlocks(ctx context.Context, userID pgtype.Text, unlocks []achievementUnlock, announce bool, held map[string]bool) {
+	var user *UserInfo
+	for _, unlock := range unlocks {
+		awarded, err := s.db.Queries().AwardAchievement(ctx, database.AwardAchievementParams{
+			UserID:      userID.String,
+			Achievement: unlock.Rule.ID,
+			RunID:       unlock.RunID,
+			AchievedAt:  pgtype.Timestamp{Time: unlock.At, Valid: true},
+		})
+		if err != nil {
+			log.Printf("Error awarding achievement %s: %v", unlock.Rule.ID, err)
+			continue
+		}
+		if awarded == 0 || !announce || held[unlock.Rule.ID] {
+			continue
+		}
+
+		if user == nil {
+			found, err := s.db.Queries().GetUserById(ctx, userID.String)
+			if err != nil {
+				log.Printf("Error getting user %s: %v", userID.String, err)
+				continue
+			}
+			user = &UserInfo{ID: found.ID, Name: found.Name, Username: found.Username}
+		}
+
+		log.Printf("User %s unlocked achievement %s", userID.String, unlock.Rule.ID)
+		s.events.Publish(EventAchievementUnlocked, AchievementUnlockedDao{
+			User:        *user,
+			Achievement: newAchievementDao(unlock.Rule, unlock.RunID, unlock.At),
+		})
+	}
+}
+
+// backfillAchievements awards badges earned by history recorded before the
+// achievements existed. Awards are idempotent, so running it on every start
+// only fills gaps.
+func (s *Server) backfillAchievements() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	userIDs, err := s.db.Queries().GetUserIdsWithRuns(ctx)
+	if err != nil {
+		log.Printf("Error listing users for achievement backfill: %v", err)
+		return
+	}
+
+	for _, userID := range userIDs {
+		s.awardAchievements(ctx, userID, false)
+	}
+	log.Printf("Backfilled achievements for %d users", len(userIDs))
+}
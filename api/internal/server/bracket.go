@@ -0,0 +1,289 @@
+package server
+
+import "fmt"
+
+const (
+	tournamentFormatSingle = "single"
+	tournamentFormatDouble = "double"
+)
+
+const (
+	bracketWinners = "winners"
+	bracketLosers  = "losers"
+	bracketFinal   = "final"
+)
+
+const (
+	matchStatusPending  = "pending"
+	matchStatusReady    = "ready"
+	matchStatusFinished = "finished"
+	// A walkover advances the only player of a match whose other slot will
+	// never be filled, e.g. a bye; a void match had no players at all.
+	matchStatusWalkover = "walkover"
+	matchStatusVoid     = "void"
+)
+
+type matchKey struct {
+	Bracket  string
+	Round    int
+	Position int
+}
+
+type slotKind int
+
+const (
+	slotSeed slotKind = iota
+	slotWinner
+	slotLoser
+)
+
+// slotSource says where a match slot gets its player from: a seed for the
+// first round, otherwise the winner or loser of an earlier match.
+type slotSource struct {
+	kind slotKind
+	from matchKey
+}
+
+// slotTarget is the match slot a result feeds into.
+type slotTarget struct {
+	Match matchKey
+	Slot  int
+}
+
+// bracketLayout describes the shape of a bracket for size seeds, size being
+// a power of two. Double elimination adds a losers bracket and a single
+// grand final without bracket reset.
+type bracketLayout struct {
+	format string
+	size   int
+	rounds int
+}
+
+func newBracketLayout(format string, participants int) (bracketLayout, error) {
+	switch format {
+	case tournamentFormatSingle:
+		if participants < 2 {
+			return bracketLayout{}, fmt.Errorf("single elimination needs at least 2 participants")
+		}
+	case tournamentFormatDouble:
+		if participants < 3 {
+			return bracketLayout{}, fmt.Errorf("double elimination needs at least 3 participants")
+		}
+	default:
+		return bracketLayout{}, fmt.Errorf("format must be single or double")
+	}
+
+	layout := bracketLayout{format: format, size: 1}
+	for layout.size < participants {
+		layout.size *= 2
+		layout.rounds++
+	}
+	return layout, nil
+}
+
+// roundMatches returns the number of matches in a round.
+func (l bracketLayout) roundMatches(bracket string, round int) int {
+	switch bracket {
+	case bracketWinners:
+		return l.size >> round
+	case bracketLosers:
+		return l.size >> ((round+1)/2 + 1)
+	default:
+		return 1
+	}
+}
+
+func (l bracketLayout) losersRounds() int {
+	if l.format != tournamentFormatDouble {
+		return 0
+	}
+	return 2 * (l.rounds - 1)
+}
+
+// matches lists every match in an order where each match comes after all
+// matches it depends on.
+func (l bracketLayout) matches() []matchKey {
+	var keys []matchKey
+	for round := 1; round <= l.rounds; round++ {
+		for position := 0; position < l.roundMatches(bracketWinners, round); position++ {
+			keys = append(keys, matchKey{bracketWinners, round, position})
+		}
+	}
+	for round := 1; round <= l.losersRounds(); round++ {
+		for position := 0; position < l.roundMatches(bracketLosers, round); position++ {
+			keys = append(keys, matchKey{bracketLosers, round, position})
+		}
+	}
+	if l.format == tournamentFormatDouble {
+		keys = append(keys, matchKey{bracketFinal, 1, 0})
+	}
+	return keys
+}
+
+// finalMatch is the match whose winner wins the tournament.
+func (l bracketLayout) finalMatch() matchKey {
+	if l.format == tournamentFormatDouble {
+		return matchKey{bracketFinal, 1, 0}
+	}
+	return matchKey{bracketWinners, l.rounds, 0}
+}
+
+func (l bracketLayout) sources(key matchKey) [2]slotSource {
+	winner := func(bracket string, round, position int) slotSource {
+		return slotSource{slotWinner, matchKey{bracket, round, position}}
+	}
+	loser := func(bracket string, round, position int) slotSource {
+		return slotSource{slotLoser, matchKey{bracket, round, position}}
+	}
+
+	p := key.Position
+	switch {
+	case key.Bracket == bracketWinners && key.Round == 1:
+		return [2]slotSource{{kind: slotSeed}, {kind: slotSeed}}
+	case key.Bracket == bracketWinners:
+		return [2]slotSource{winner(bracketWinners, key.Round-1, 2*p), winner(bracketWinners, key.Round-1, 2*p+1)}
+	case key.Bracket == bracketLosers && key.Round == 1:
+		return [2]slotSource{loser(bracketWinners, 1, 2*p), loser(bracketWinners, 1, 2*p+1)}
+	case key.Bracket == bracketLosers && key.Round%2 == 0:
+		// Drop-downs from the winners bracket enter in reverse order so
+		// players who just met do not face each other again right away.
+		count := l.roundMatches(bracketLosers, key.Round)
+		return [2]slotSource{winner(bracketLosers, key.Round-1, p), loser(bracketWinners, key.Round/2+1, count-1-p)}
+	case key.Bracket == bracketLosers:
+		return [2]slotSource{winner(bracketLosers, key.Round-1, 2*p), winner(bracketLosers, key.Round-1, 2*p+1)}
+	default:
+		return [2]slotSource{winner(bracketWinners, l.rounds, 0), winner(bracketLosers, l.losersRounds(), 0)}
+	}
+}
+
+// targets maps every match to where its winner and loser go next.
+func (l bracketLayout) targets() (winners, losers map[matchKey]slotTarget) {
+	winners = make(map[matchKey]slotTarget)
+	losers = make(map[matchKey]slotTarget)
+	for _, key := range l.matches() {
+		for slot, source := range l.sources(key) {
+			switch source.kind {
+			case slotWinner:
+				winners[source.from] = slotTarget{Match: key, Slot: slot}
+			case slotLoser:
+				losers[source.from] = slotTarget{Match: key, Slot: slot}
+			}
+		}
+	}
+	return winners, losers
+}
+
+// seedOrder returns the seeds of the first round in bracket order, so that
+// seed 1 and 2 can only meet in the final: 1, 8, 4, 5, 2, 7, 3, 6 for 8.
+func seedOrder(size int) []int {
+	order := []int{1}
+	for len(order) < size {
+		n := len(order) * 2
+		next := make([]int, 0, n)
+		for _, seed := range order {
+			next = append(next, seed, n+1-seed)
+		}
+		order = next
+	}
+	return order
+}
+
+// firstRound pairs the seeded players; seeds beyond the field are byes,
+// represented by an empty player.
+func (l bracketLayout) firstRound(seeded []string) map[matchKey][2]string {
+	order := seedOrder(l.size)
+	player := func(seed int) string {
+		if seed > len(seeded) {
+			return ""
+		}
+		return seeded[seed-1]
+	}
+
+	pairs := make(map[matchKey][2]string)
+	for position := 0; position < l.roundMatches(bracketWinners, 1); position++ {
+		pairs[matchKey{bracketWinners, 1, position}] = [2]string{
+			player(order[2*position]),
+			player(order[2*position+1]),
+		}
+	}
+	return pairs
+}
+
+// bracketMatch is the mutable state of one match. Empty players are open or
+// dead slots, depending on whether their source has been decided.
+type bracketMatch struct {
+	PlayerA string
+	PlayerB string
+	Winner  string
+	Status  string
+}
+
+func (m bracketMatch) decided() bool {
+	switch m.Status {
+	case matchStatusFinished, matchStatusWalkover, matchStatusVoid:
+		return true
+	}
+	return false
+}
+
+func (m bracketMatch) loser() string {
+	if m.Status != matchStatusFinished {
+		return ""
+	}
+	if m.Winner == m.PlayerA {
+		return m.PlayerB
+	}
+	return m.PlayerA
+}
+
+// resolve moves decided results forward through the bracket and settles
+// matches that cannot be played. It returns the keys of changed matches.
+func (l bracketLayout) resolve(state map[matchKey]*bracketMatch) []matchKey {
+	var changed []matchKey
+	for _, key := range l.matches() {
+		match := state[key]
+		if match == nil || match.decided() {
+			continue
+		}
+		before := *match
+
+		var players [2]string
+		resolved := [2]bool{true, true}
+		for slot, source := range l.sources(key) {
+			switch source.kind {
+			case slotSeed:
+				players = [2]string{match.PlayerA, match.PlayerB}
+			case slotWinner, slotLoser:
+				from := state[source.from]
+				if from == nil || !from.decided() {
+					resolved[slot] = false
+					continue
+				}
+				if source.kind == slotWinner {
+					players[slot] = from.Winner
+				} else {
+					players[slot] = from.loser()
+				}
+			}
+		}
+
+		match.PlayerA, match.PlayerB = players[0], players[1]
+		switch {
+		case !resolved[0] || !resolved[1]:
+			match.Status = matchStatusPending
+		case players[0] != "" && players[1] != "":
+			match.Status = matchStatusReady
+		case players[0] != "":
+			match.Status, match.Winner = matchStatusWalkover, players[0]
+		case players[1] != "":
+			match.Status, match.Winner = matchStatusWalkover, players[1]
+		default:
+			match.Status = matchStatusVoid
+		}
+
+		if *match != before {
+			changed = append(changed, key)
+		}
+	}
+	return changed
+}
@@ -0,0 +1,278 @@
+package server
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestNewBracketLayout(t *testing.T) {
+	tests := []struct {
+		format       string
+		participants int
+		wantSize     int
+		wantRounds   int
+		wantMatches  int
+		wantErr      bool
+	}{
+		{tournamentFormatSingle, 2, 2, 1, 1, false},
+		{tournamentFormatSingle, 3, 4, 2, 3, false},
+		{tournamentFormatSingle, 5, 8, 3, 7, false},
+		{tournamentFormatSingle, 8, 8, 3, 7, false},
+		{tournamentFormatDouble, 3, 4, 2, 6, false},
+		{tournamentFormatDouble, 4, 4, 2, 6, false},
+		{tournamentFormatDouble, 5, 8, 3, 14, false},
+		{tournamentFormatSingle, 1, 0, 0, 0, true},
+		{tournamentFormatDouble, 2, 0, 0, 0, true},
+		{"swiss", 8, 0, 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s/%d", tt.format, tt.participants), func(t *testing.T) {
+			layout, err := newBracketLayout(tt.format, tt.participants)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("newBracketLayout(%q, %d) = %+v, want an error", tt.format, tt.participants, layout)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("newBracketLayout(%q, %d) failed: %v", tt.format, tt.participants, err)
+			}
+			if layout.size != tt.wantSize || layout.rounds != tt.wantRounds {
+				t.Errorf("size, rounds = %d, %d, want %d, %d", layout.size, layout.rounds, tt.wantSize, tt.wantRounds)
+			}
+			if got := len(layout.matches()); got != tt.wantMatches {
+				t.Errorf("len(matches()) = %d, want %d", got, tt.wantMatches)
+			}
+		})
+	}
+}
+
+func TestSeedOrder(t *testing.T) {
+	tests := []struct {
+		size int
+		want []int
+	}{
+		{1, []int{1}},
+		{2, []int{1, 2}},
+		{4, []int{1, 4, 2, 3}},
+		{8, []int{1, 8, 4, 5, 2, 7, 3, 6}},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.size), func(t *testing.T) {
+			if got := seedOrder(tt.size); !slices.Equal(got, tt.want) {
+				t.Errorf("seedOrder(%d) = %v, want %v", tt.size, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFirstRound(t *testing.T) {
+	tests := []struct {
+		name    string
+		players int
+		want    [][2]string
+	}{
+		{"full", 4, [][2]string{{"p1", "p4"}, {"p2", "p3"}}},
+		{"one bye", 3, [][2]string{{"p1", ""}, {"p2", "p3"}}},
+		{"three byes", 5, [][2]string{{"p1", ""}, {"p4", "p5"}, {"p2", ""}, {"p3", ""}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			layout, err := newBracketLayout(tournamentFormatSingle, tt.players)
+			if err != nil {
+				t.Fatalf("newBracketLayout failed: %v", err)
+			}
+			pairs := layout.firstRound(testPlayers(tt.players))
+			if len(pairs) != len(tt.want) {
+				t.Fatalf("firstRound() has %d matches, want %d", len(pairs), len(tt.want))
+			}
+			for position, want := range tt.want {
+				key := matchKey{bracketWinners, 1, position}
+				if got := pairs[key]; got != want {
+					t.Errorf("firstRound()[%v] = %q, want %q", key, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestTargets(t *testing.T) {
+	single, _ := newBracketLayout(tournamentFormatSingle, 8)
+	double, _ := newBracketLayout(tournamentFormatDouble, 8)
+
+	tests := []struct {
+		name   string
+		layout bracketLayout
+		from   matchKey
+		loser  bool
+		want   slotTarget
+		wantOK bool
+	}{
+		{"single winner", single, matchKey{bracketWinners, 1, 3}, false, slotTarget{matchKey{bracketWinners, 2, 1}, 1}, true},
+		{"single final", single, matchKey{bracketWinners, 3, 0}, false, slotTarget{}, false},
+		{"single loser", single, matchKey{bracketWinners, 1, 0}, true, slotTarget{}, false},
+		{"first round losers pair up", double, matchKey{bracketWinners, 1, 1}, true, slotTarget{matchKey{bracketLosers, 1, 0}, 1}, true},
+		{"second round loser drops reversed", double, matchKey{bracketWinners, 2, 0}, true, slotTarget{matchKey{bracketLosers, 2, 1}, 1}, true},
+		{"second round loser drops reversed 2", double, matchKey{bracketWinners, 2, 1}, true, slotTarget{matchKey{bracketLosers, 2, 0}, 1}, true},
+		{"winners final loser", double, matchKey{bracketWinners, 3, 0}, true, slotTarget{matchKey{bracketLosers, 4, 0}, 1}, true},
+		{"losers winner meets drop-down", double, matchKey{bracketLosers, 1, 1}, false, slotTarget{matchKey{bracketLosers, 2, 1}, 0}, true},
+		{"losers winners pair up", double, matchKey{bracketLosers, 2, 1}, false, slotTarget{matchKey{bracketLosers, 3, 0}, 1}, true},
+		{"winners final to grand final", double, matchKey{bracketWinners, 3, 0}, false, slotTarget{matchKey{bracketFinal, 1, 0}, 0}, true},
+		{"losers final to grand final", double, matchKey{bracketLosers, 4, 0}, false, slotTarget{matchKey{bracketFinal, 1, 0}, 1}, true},
+		{"losers bracket loser is out", double, matchKey{bracketLosers, 1, 0}, true, slotTarget{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			winners, losers := tt.layout.targets()
+			targets := winners
+			if tt.loser {
+				targets = losers
+			}
+			got, ok := targets[tt.from]
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("target of %v = %v, %v, want %v, %v", tt.from, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	type result struct {
+		match  matchKey
+		winner string
+	}
+
+	tests := []struct {
+		name    string
+		format  string
+		players int
+		results []result
+		want    map[matchKey]bracketMatch
+	}{
+		{
+			name:    "single elimination without byes",
+			format:  tournamentFormatSingle,
+			players: 2,
+			want: map[matchKey]bracketMatch{
+				{bracketWinners, 1, 0}: {PlayerA: "p1", PlayerB: "p2", Status: matchStatusReady},
+			},
+		},
+		{
+			name:    "bye is a walkover",
+			format:  tournamentFormatSingle,
+			players: 3,
+			want: map[matchKey]bracketMatch{
+				{bracketWinners, 1, 0}: {PlayerA: "p1", Winner: "p1", Status: matchStatusWalkover},
+				{bracketWinners, 1, 1}: {PlayerA: "p2", PlayerB: "p3", Status: matchStatusReady},
+				{bracketWinners, 2, 0}: {PlayerA: "p1", Status: matchStatusPending},
+			},
+		},
+		{
+			name:    "winner advances",
+			format:  tournamentFormatSingle,
+			players: 3,
+			results: []result{{matchKey{bracketWinners, 1, 1}, "p3"}},
+			want: map[matchKey]bracketMatch{
+				{bracketWinners, 2, 0}: {PlayerA: "p1", PlayerB: "p3", Status: matchStatusReady},
+			},
+		},
+		{
+			name:    "bye leaves a walkover in the losers bracket",
+			format:  tournamentFormatDouble,
+			players: 3,
+			results: []result{{matchKey{bracketWinners, 1, 1}, "p2"}},
+			want: map[matchKey]bracketMatch{
+				{bracketLosers, 1, 0}:  {PlayerB: "p3", Winner: "p3", Status: matchStatusWalkover},
+				{bracketWinners, 2, 0}: {PlayerA: "p1", PlayerB: "p2", Status: matchStatusReady},
+				{bracketLosers, 2, 0}:  {PlayerA: "p3", Status: matchStatusPending},
+			},
+		},
+		{
+			name:    "losers drop down and meet the grand final",
+			format:  tournamentFormatDouble,
+			players: 3,
+			results: []result{
+				{matchKey{bracketWinners, 1, 1}, "p2"},
+				{matchKey{bracketWinners, 2, 0}, "p1"},
+				{matchKey{bracketLosers, 2, 0}, "p2"},
+			},
+			want: map[matchKey]bracketMatch{
+				{bracketLosers, 2, 0}: {PlayerA: "p3", PlayerB: "p2", Winner: "p2", Status: matchStatusFinished},
+				{bracketFinal, 1, 0}:  {PlayerA: "p1", PlayerB: "p2", Status: matchStatusReady},
+			},
+		},
+		{
+			name:    "two byes void a losers match",
+			format:  tournamentFormatDouble,
+			players: 5,
+			want: map[matchKey]bracketMatch{
+				{bracketWinners, 1, 2}: {PlayerA: "p2", Winner: "p2", Status: matchStatusWalkover},
+				{bracketWinners, 1, 3}: {PlayerA: "p3", Winner: "p3", Status: matchStatusWalkover},
+				{bracketLosers, 1, 1}:  {Status: matchStatusVoid},
+				{bracketWinners, 2, 1}: {PlayerA: "p2", PlayerB: "p3", Status: matchStatusReady},
+				{bracketLosers, 1, 0}:  {Status: matchStatusPending},
+			},
+		},
+		{
+			name:    "void match passes on no one",
+			format:  tournamentFormatDouble,
+			players: 5,
+			results: []result{
+				{matchKey{bracketWinners, 1, 1}, "p4"},
+				{matchKey{bracketWinners, 2, 0}, "p1"},
+				{matchKey{bracketWinners, 2, 1}, "p3"},
+			},
+			want: map[matchKey]bracketMatch{
+				{bracketLosers, 1, 0}: {PlayerB: "p5", Winner: "p5", Status: matchStatusWalkover},
+				{bracketLosers, 2, 0}: {PlayerA: "p5", PlayerB: "p2", Status: matchStatusReady},
+				{bracketLosers, 2, 1}: {PlayerB: "p4", Winner: "p4", Status: matchStatusWalkover},
+				{bracketLosers, 3, 0}: {PlayerB: "p4", Status: matchStatusPending},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			layout, err := newBracketLayout(tt.format, tt.players)
+			if err != nil {
+				t.Fatalf("newBracketLayout failed: %v", err)
+			}
+			state := make(map[matchKey]*bracketMatch)
+			for _, key := range layout.matches() {
+				state[key] = &bracketMatch{Status: matchStatusPending}
+			}
+			for key, pair := range layout.firstRound(testPlayers(tt.players)) {
+				state[key].PlayerA, state[key].PlayerB = pair[0], pair[1]
+			}
+			layout.resolve(state)
+
+			for _, result := range tt.results {
+				match := state[result.match]
+				if match.Status != matchStatusReady {
+					t.Fatalf("match %v is %s, want it ready to record a result", result.match, match.Status)
+				}
+				match.Winner, match.Status = result.winner, matchStatusFinished
+				layout.resolve(state)
+			}
+
+			for key, want := range tt.want {
+				if got := *state[key]; got != want {
+					t.Errorf("match %v = %+v, want %+v", key, got, want)
+				}
+			}
+		})
+	}
+}
+
+func testPlayers(n int) []string {
+	players := make([]string, n)
+	for i := range players {
+		players[i] = fmt.Sprintf("p%d", i+1)
+	}
+	return players
+}
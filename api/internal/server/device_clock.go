@@ -0,0 +1,113 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/tt-trichter/app/api/internal/database"
+)
+
+// Devices identify themselves and report their clock, in milliseconds since
+// the Unix epoch, on every request. Devices have no real-time clock and start
+// counting from zero after every reboot, so they also send an ID that is new
+// for every boot; an offset is only valid for the boot it was measured in.
+const (
+	deviceIDHeader    = "X-Device-Id"
+	deviceClockHeader = "X-Device-Clock"
+	deviceBootHeader  = "X-Device-Boot"
+)
+
+const defaultMaxRunBackdate = 7 * 24 * time.Hour
+
+// trackDeviceClock stores how far the clock of the device sending the
+// request is off from the server clock, together with the boot it was
+// measured in. Requests without the headers, such as those of the web app,
+// are left alone, and so are devices that are not registered: the headers
+// are not authenticated, so they must not create devices.
+func (s *Server) trackDeviceClock() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		deviceID, clock, bootID := c.GetHeader(deviceIDHeader), c.GetHeader(deviceClockHeader), c.GetHeader(deviceBootHeader)
+		if deviceID == "" || clock == "" || bootID == "" {
+			c.Next()
+			return
+		}
+
+		deviceMillis, err := strconv.ParseInt(clock, 10, 64)
+		if err != nil {
+			log.Printf("Ignoring invalid %s %q from device %s", deviceClockHeader, clock, deviceID)
+			c.Next()
+			return
+		}
+
+		offset := time.Now().UnixMilli() - deviceMillis
+		if err := s.db.Queries().SaveDeviceClock(c.Request.Context(), database.SaveDeviceClockParams{
+			ID:            deviceID,
+			ClockOffsetMs: pgtype.Int8{Int64: offset, Valid: true},
+			ClockBootID:   pgtype.Text{String: bootID, Valid: true},
+		}); err != nil {
+			log.Printf("Error saving clock of device %s: %v", deviceID, err)
+		}
+
+		c.Next()
+	}
+}
+
+// deviceClock is the last clock offset measured for a device and the boot
+// it was measured in.
+type deviceClock struct {
+	offset pgtype.Int8
+	bootID pgtype.Text
+}
+
+// deviceClocks looks up and caches the clock offsets of devices while a
+// request corrects timestamps.
+type deviceClocks struct {
+	queries *database.Queries
+	clocks  map[string]deviceClock
+}
+
+func (s *Server) newDeviceClocks() *deviceClocks {
+	return &deviceClocks{queries: s.db.Queries(), clocks: map[string]deviceClock{}}
+}
+
+func (d *deviceClocks) clock(ctx context.Context, deviceID string) deviceClock {
+	if clock, ok := d.clocks[deviceID]; ok {
+		return clock
+	}
+	var clock deviceClock
+	device, err := d.queries.GetDevice(ctx, deviceID)
+	if err == nil {
+		clock = deviceClock{offset: device.ClockOffsetMs, bootID: device.ClockBootID}
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		log.Printf("Error getting device %s: %v", deviceID, err)
+	}
+	d.clocks[deviceID] = clock
+	return clock
+}
+
+// correct moves a timestamp taken by a device during the given boot onto the
+// server clock. It reports whether the result can be trusted. A timestamp
+// without a device is taken as is. One taken during a boot for which no
+// offset was measured cannot be trusted, as the device clock restarted
+// since; neither can one that still lies in the future or further back than
+// maxBackdate.
+func (d *deviceClocks) correct(ctx context.Context, deviceID, bootID string, recordedAt time.Time, maxBackdate time.Duration) (time.Time, bool) {
+	corrected := recordedAt
+	if deviceID != "" {
+		clock := d.clock(ctx, deviceID)
+		if !clock.offset.Valid || bootID == "" || clock.bootID.String != bootID {
+			return corrected, false
+		}
+		corrected = recordedAt.Add(time.Duration(clock.offset.Int64) * time.Millisecond)
+	}
+
+	now := time.Now()
+	trusted := !corrected.After(now.Add(maxFutureRecordedAt)) && now.Sub(corrected) <= maxBackdate
+	return corrected, trusted
+}
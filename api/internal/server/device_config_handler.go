@@ -0,0 +1,309 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/tt-trichter/app/api/internal/database"
+)
+
+// DeviceConfigDco is the configuration a funnel applies at runtime. Fields
+// whose zero value is valid are pointers so that leaving them out is an
+// error rather than turning the setting off.
+type DeviceConfigDco struct {
+	// PulsesPerLitre calibrates the flow sensor.
+	PulsesPerLitre float64 `json:"pulsesPerLitre" binding:"required,gt=0"`
+	// MinRunVolume is the volume in litres below which a pour is not a run.
+	MinRunVolume       *float64 `json:"minRunVolume" binding:"required,gte=0"`
+	IdleTimeoutSeconds int      `json:"idleTimeoutSeconds" binding:"required,min=1,max=3600"`
+	// DisplayBrightness is in percent.
+	DisplayBrightness *int `json:"displayBrightness" binding:"required,min=0,max=100"`
+}
+
+// defaultDeviceConfig is served as version 0 until an admin configures the
+// device; it matches the firmware defaults.
+var defaultDeviceConfig = func() DeviceConfigDco {
+	minRunVolume, displayBrightness := 0.1, 80
+	return DeviceConfigDco{
+		PulsesPerLitre:     450,
+		MinRunVolume:       &minRunVolume,
+		IdleTimeoutSeconds: 3,
+		DisplayBrightness:  &displayBrightness,
+	}
+}()
+
+// DeviceConfigDao is what devices poll. It only changes with the version,
+// so its ETag does too.
+type DeviceConfigDao struct {
+	Version int32           `json:"version"`
+	Config  DeviceConfigDco `json:"config"`
+}
+
+// DeviceConfigStatusDao is the admin view of a device's configuration.
+type DeviceConfigStatusDao struct {
+	DeviceConfigDao
+	UpdatedBy      *string    `json:"updatedBy"`
+	UpdatedAt      *time.Time `json:"updatedAt"`
+	AppliedVersion *int32     `json:"appliedVersion"`
+	AppliedAt      *time.Time `json:"appliedAt"`
+}
+
+type DeviceConfigRevisionDao struct {
+	Version   int32           `json:"version"`
+	Config    DeviceConfigDco `json:"config"`
+	ChangedBy string          `json:"changedBy"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+type DeviceConfigAckDco struct {
+	Version *int32 `json:"version" binding:"required,min=0"`
+}
+
+// lookupDeviceConfig loads the configuration of a registered device, falling
+// back to the defaults at version 0. It writes the error response itself.
+func (s *Server) lookupDeviceConfig(c *gin.Context) (DeviceConfigStatusDao, bool) {
+	device, ok := s.lookupDevice(c)
+	if !ok {
+		return DeviceConfigStatusDao{}, false
+	}
+	if !device.RegisteredAt.Valid {
+		c.JSON(http.StatusNotFound, APIResponse{
+			Success: false,
+			Error:   "Device not registered",
+		})
+		return DeviceConfigStatusDao{}, false
+	}
+
+	stored, err := s.db.Queries().GetDeviceConfig(c.Request.Context(), device.ID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return DeviceConfigStatusDao{
+			DeviceConfigDao: DeviceConfigDao{Config: defaultDeviceConfig},
+		}, true
+	}
+	if err != nil {
+		log.Printf("Error getting device config: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to fetch device config",
+		})
+		return DeviceConfigStatusDao{}, false
+	}
+
+	status, err := newDeviceConfigStatusDao(stored)
+	if err != nil {
+		log.Printf("Error unmarshaling device config: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to fetch device config",
+		})
+		return DeviceConfigStatusDao{}, false
+	}
+	return status, true
+}
+
+func newDeviceConfigStatusDao(stored database.DeviceConfig) (DeviceConfigStatusDao, error) {
+	status := DeviceConfigStatusDao{
+		DeviceConfigDao: DeviceConfigDao{Version: stored.Version},
+		UpdatedBy:       &stored.UpdatedBy,
+		UpdatedAt:       &stored.UpdatedAt.Time,
+	}
+	if err := json.Unmarshal(stored.Config, &status.Config); err != nil {
+		return DeviceConfigStatusDao{}, err
+	}
+	if stored.AppliedVersion.Valid {
+		status.AppliedVersion = &stored.AppliedVersion.Int32
+	}
+	if stored.AppliedAt.Valid {
+		status.AppliedAt = &stored.AppliedAt.Time
+	}
+	return status, nil
+}
+
+// getDeviceConfigHandler serves the configuration a device should apply.
+// Devices poll it with If-None-Match and get 304 until the version changes.
+func (s *Server) getDeviceConfigHandler(c *gin.Context) {
+	status, ok := s.lookupDeviceConfig(c)
+	if !ok {
+		return
+	}
+
+	jsonWithETag(c, http.StatusOK, status.DeviceConfigDao)
+}
+
+// acknowledgeDeviceConfigHandler records the configuration version a device
+// has applied.
+func (s *Server) acknowledgeDeviceConfigHandler(c *gin.Context) {
+	var ackDco DeviceConfigAckDco
+	if err := c.ShouldBindJSON(&ackDco); err != nil {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Validation failed",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	status, ok := s.lookupDeviceConfig(c)
+	if !ok {
+		return
+	}
+	if *ackDco.Version > status.Version {
+		c.JSON(http.StatusConflict, APIResponse{
+			Success: false,
+			Error:   "Unknown config version",
+		})
+		return
+	}
+	// The defaults are not stored, so there is nothing to record.
+	if status.Version == 0 {
+		c.JSON(http.StatusOK, APIResponse{Success: true})
+		return
+	}
+
+	_, err := s.db.Queries().AcknowledgeDeviceConfig(c.Request.Context(), database.AcknowledgeDeviceConfigParams{
+		DeviceID:       c.Param("id"),
+		AppliedVersion: pgtype.Int4{Int32: *ackDco.Version, Valid: true},
+	})
+	if err != nil {
+		log.Printf("Error acknowledging device config: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to acknowledge config",
+		})
+		return
+	}
+
+	log.Printf("Device %s applied config version %d", c.Param("id"), *ackDco.Version)
+
+	c.JSON(http.StatusOK, APIResponse{Success: true})
+}
+
+// getDeviceConfigStatusHandler shows admins a device's configuration and
+// which version the device has applied.
+func (s *Server) getDeviceConfigStatusHandler(c *gin.Context) {
+	status, ok := s.lookupDeviceConfig(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// updateDeviceConfigHandler replaces a device's configuration with a new
+// version and keeps the change, with the admin who made it, in the audit
+// trail.
+func (s *Server) updateDeviceConfigHandler(c *gin.Context) {
+	var configDco DeviceConfigDco
+	if err := c.ShouldBindJSON(&configDco); err != nil {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Validation failed",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	if _, ok := s.lookupDeviceConfig(c); !ok {
+		return
+	}
+
+	config, err := json.Marshal(configDco)
+	if err != nil {
+		log.Printf("Error marshaling device config: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Internal server error",
+		})
+		return
+	}
+
+	ctx := c.Request.Context()
+	editor := c.GetString(gin.AuthUserKey)
+
+	tx, err := s.db.Pool().Begin(ctx)
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to update device config",
+		})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	queries := s.db.Queries().WithTx(tx)
+
+	stored, err := queries.SaveDeviceConfig(ctx, database.SaveDeviceConfigParams{
+		DeviceID:  c.Param("id"),
+		Config:    config,
+		UpdatedBy: editor,
+	})
+	if err == nil {
+		err = queries.CreateDeviceConfigRevision(ctx, database.CreateDeviceConfigRevisionParams{
+			DeviceID:  stored.DeviceID,
+			Version:   stored.Version,
+			Config:    config,
+			ChangedBy: editor,
+		})
+	}
+	if err == nil {
+		err = tx.Commit(ctx)
+	}
+	var status DeviceConfigStatusDao
+	if err == nil {
+		status, err = newDeviceConfigStatusDao(stored)
+	}
+	if err != nil {
+		log.Printf("Error updating device config: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to update device config",
+		})
+		return
+	}
+
+	log.Printf("%s set config version %d of device %s", editor, stored.Version, stored.DeviceID)
+
+	c.JSON(http.StatusOK, status)
+}
+
+// getDeviceConfigHistoryHandler returns every configuration version of a
+// device, newest first.
+func (s *Server) getDeviceConfigHistoryHandler(c *gin.Context) {
+	device, ok := s.lookupDevice(c)
+	if !ok {
+		return
+	}
+
+	revisions, err := s.db.Queries().GetDeviceConfigRevisions(c.Request.Context(), device.ID)
+	if err != nil {
+		log.Printf("Error getting device config revisions: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to fetch device config history",
+		})
+		return
+	}
+
+	response := make([]DeviceConfigRevisionDao, 0, len(revisions))
+	for _, revision := range revisions {
+		entry := DeviceConfigRevisionDao{
+			Version:   revision.Version,
+			ChangedBy: revision.ChangedBy,
+			CreatedAt: revision.CreatedAt.Time,
+		}
+		if err := json.Unmarshal(revision.Config, &entry.Config); err != nil {
+			log.Printf("Error unmarshaling device config: %v", err)
+			continue
+		}
+		response = append(response, entry)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
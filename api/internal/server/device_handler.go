@@ -0,0 +1,284 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/tt-trichter/app/api/internal/database"
+)
+
+const EventDeviceStatusChanged = "device-status"
+
+const (
+	deviceStatusOnline  = "online"
+	deviceStatusOffline = "offline"
+)
+
+const (
+	defaultDeviceTimeout = 90 * time.Second
+	deviceStatusInterval = 15 * time.Second
+)
+
+type DeviceDao struct {
+	ID   string  `json:"id"`
+	Name *string `json:"name"`
+	// Registered is false for devices that reported their clock before
+	// devices had to be registered; they cannot send heartbeats.
+	Registered      bool       `json:"registered"`
+	Status          string     `json:"status"`
+	StatusChangedAt *time.Time `json:"statusChangedAt"`
+	LastHeartbeatAt *time.Time `json:"lastHeartbeatAt"`
+	FirmwareVersion *string    `json:"firmwareVersion"`
+	UptimeSeconds   *int64     `json:"uptimeSeconds"`
+	WifiRssi        *int32     `json:"wifiRssi"`
+	SensorHealthy   *bool      `json:"sensorHealthy"`
+	SensorError     *string    `json:"sensorError"`
+	// ClockSkewMs is how far the device clock is behind the server clock,
+	// negative when it is ahead; null until the device reported its clock.
+	ClockSkewMs   *int64     `json:"clockSkewMs"`
+	ClockSyncedAt *time.Time `json:"clockSyncedAt"`
+	CreatedAt     time.Time  `json:"createdAt"`
+}
+
+type DeviceDco struct {
+	ID   string `json:"id" binding:"required,max=64"`
+	Name string `json:"name" binding:"max=100"`
+}
+
+type HeartbeatDco struct {
+	FirmwareVersion string `json:"firmwareVersion" binding:"required,max=64"`
+	UptimeSeconds   *int64 `json:"uptimeSeconds" binding:"required,min=0"`
+	// WifiRssi is in dBm; devices on a cable leave it out.
+	WifiRssi      *int32 `json:"wifiRssi" binding:"omitempty,min=-127,max=0"`
+	SensorHealthy *bool  `json:"sensorHealthy" binding:"required"`
+	SensorError   string `json:"sensorError" binding:"max=500"`
+}
+
+func newDeviceDao(device database.Device) DeviceDao {
+	dao := DeviceDao{
+		ID:         device.ID,
+		Registered: device.RegisteredAt.Valid,
+		Status:     device.Status,
+		CreatedAt:  device.CreatedAt.Time,
+	}
+	if device.Name.Valid {
+		dao.Name = &device.Name.String
+	}
+	if device.StatusChangedAt.Valid {
+		dao.StatusChangedAt = &device.StatusChangedAt.Time
+	}
+	if device.LastHeartbeatAt.Valid {
+		dao.LastHeartbeatAt = &device.LastHeartbeatAt.Time
+	}
+	if device.FirmwareVersion.Valid {
+		dao.FirmwareVersion = &device.FirmwareVersion.String
+	}
+	if device.UptimeSeconds.Valid {
+		dao.UptimeSeconds = &device.UptimeSeconds.Int64
+	}
+	if device.WifiRssi.Valid {
+		dao.WifiRssi = &device.WifiRssi.Int32
+	}
+	if device.SensorHealthy.Valid {
+		dao.SensorHealthy = &device.SensorHealthy.Bool
+	}
+	if device.SensorError.Valid {
+		dao.SensorError = &device.SensorError.String
+	}
+	if device.ClockOffsetMs.Valid {
+		dao.ClockSkewMs = &device.ClockOffsetMs.Int64
+	}
+	if device.ClockSyncedAt.Valid {
+		dao.ClockSyncedAt = &device.ClockSyncedAt.Time
+	}
+	return dao
+}
+
+// getDevicesHandler lists registered devices followed by the ones only
+// known from their requests.
+func (s *Server) getDevicesHandler(c *gin.Context) {
+	devices, err := s.db.Queries().ListDevices(c.Request.Context())
+	if err != nil {
+		log.Printf("Error listing devices: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to fetch devices",
+		})
+		return
+	}
+
+	response := make([]DeviceDao, 0, len(devices))
+	for _, device := range devices {
+		response = append(response, newDeviceDao(device))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (s *Server) getDeviceHandler(c *gin.Context) {
+	device, ok := s.lookupDevice(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, newDeviceDao(device))
+}
+
+// lookupDevice loads the device named by the id parameter, writing the
+// error response itself when that fails.
+func (s *Server) lookupDevice(c *gin.Context) (database.Device, bool) {
+	device, err := s.db.Queries().GetDevice(c.Request.Context(), c.Param("id"))
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, APIResponse{
+			Success: false,
+			Error:   "Device not found",
+		})
+		return database.Device{}, false
+	}
+	if err != nil {
+		log.Printf("Error getting device: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to fetch device",
+		})
+		return database.Device{}, false
+	}
+	return device, true
+}
+
+// registerDeviceHandler registers a funnel so it can send heartbeats.
+// Registering a known device renames it.
+func (s *Server) registerDeviceHandler(c *gin.Context) {
+	var deviceDco DeviceDco
+	if err := c.ShouldBindJSON(&deviceDco); err != nil {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Validation failed",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	var name pgtype.Text
+	if deviceDco.Name != "" {
+		name = pgtype.Text{String: deviceDco.Name, Valid: true}
+	}
+
+	device, err := s.db.Queries().RegisterDevice(c.Request.Context(), database.RegisterDeviceParams{
+		ID:   deviceDco.ID,
+		Name: name,
+	})
+	if err != nil {
+		log.Printf("Error registering device: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to register device",
+		})
+		return
+	}
+
+	log.Printf("Registered device %s", device.ID)
+
+	c.JSON(http.StatusCreated, newDeviceDao(device))
+}
+
+// heartbeatHandler records a heartbeat of a registered device and marks it
+// online.
+func (s *Server) heartbeatHandler(c *gin.Context) {
+	var heartbeatDco HeartbeatDco
+	if err := c.ShouldBindJSON(&heartbeatDco); err != nil {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Validation failed",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	params := database.RecordDeviceHeartbeatParams{
+		ID:              c.Param("id"),
+		FirmwareVersion: pgtype.Text{String: heartbeatDco.FirmwareVersion, Valid: true},
+		UptimeSeconds:   pgtype.Int8{Int64: *heartbeatDco.UptimeSeconds, Valid: true},
+		SensorHealthy:   pgtype.Bool{Bool: *heartbeatDco.SensorHealthy, Valid: true},
+	}
+	if heartbeatDco.WifiRssi != nil {
+		params.WifiRssi = pgtype.Int4{Int32: *heartbeatDco.WifiRssi, Valid: true}
+	}
+	if heartbeatDco.SensorError != "" {
+		params.SensorError = pgtype.Text{String: heartbeatDco.SensorError, Valid: true}
+	}
+
+	row, err := s.db.Queries().RecordDeviceHeartbeat(c.Request.Context(), params)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, APIResponse{
+			Success: false,
+			Error:   "Device not registered",
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Error recording heartbeat: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to record heartbeat",
+		})
+		return
+	}
+
+	device := newDeviceDao(database.Device{
+		ID:              row.ID,
+		Name:            row.Name,
+		ClockOffsetMs:   row.ClockOffsetMs,
+		ClockBootID:     row.ClockBootID,
+		ClockSyncedAt:   row.ClockSyncedAt,
+		RegisteredAt:    row.RegisteredAt,
+		Status:          row.Status,
+		StatusChangedAt: row.StatusChangedAt,
+		LastHeartbeatAt: row.LastHeartbeatAt,
+		FirmwareVersion: row.FirmwareVersion,
+		UptimeSeconds:   row.UptimeSeconds,
+		WifiRssi:        row.WifiRssi,
+		SensorHealthy:   row.SensorHealthy,
+		SensorError:     row.SensorError,
+		CreatedAt:       row.CreatedAt,
+	})
+	if row.PreviousStatus != deviceStatusOnline {
+		log.Printf("Device %s is online", device.ID)
+		s.events.Publish(EventDeviceStatusChanged, device)
+	}
+
+	c.JSON(http.StatusOK, device)
+}
+
+// runDeviceStatusCheck marks devices offline once their heartbeats stop
+// for longer than the device timeout.
+func (s *Server) runDeviceStatusCheck() {
+	ticker := time.NewTicker(deviceStatusInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		s.markDevicesOffline()
+	}
+}
+
+func (s *Server) markDevicesOffline() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	cutoff := pgtype.Timestamp{Time: time.Now().UTC().Add(-s.deviceTimeout), Valid: true}
+	devices, err := s.db.Queries().MarkDevicesOffline(ctx, cutoff)
+	if err != nil {
+		log.Printf("Error marking devices offline: %v", err)
+		return
+	}
+	for _, device := range devices {
+		log.Printf("Device %s is %s", device.ID, deviceStatusOffline)
+		s.events.Publish(EventDeviceStatusChanged, newDeviceDao(device))
+	}
+}
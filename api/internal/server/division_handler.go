@@ -0,0 +1,202 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/tt-trichter/app/api/internal/database"
+)
+
+// DivisionDco describes a volume class in litres. A run belongs to the
+// division whose nominal volume is closest, provided it lies within the
+// tolerance band.
+type DivisionDco struct {
+	Name      string   `json:"name" binding:"required"`
+	Volume    float64  `json:"volume" binding:"required,gt=0"`
+	Tolerance float64  `json:"tolerance" binding:"gte=0"`
+	Handicap  *float64 `json:"handicap" binding:"omitempty,gt=0"`
+}
+
+type DivisionDao struct {
+	ID        string  `json:"id"`
+	Name      string  `json:"name"`
+	Volume    float64 `json:"volume"`
+	Tolerance float64 `json:"tolerance"`
+	Handicap  float64 `json:"handicap"`
+}
+
+func newDivisionDao(division database.Division) DivisionDao {
+	return DivisionDao{
+		ID:        division.ID.String(),
+		Name:      division.Name,
+		Volume:    division.Volume,
+		Tolerance: division.Tolerance,
+		Handicap:  division.Handicap,
+	}
+}
+
+func (s *Server) listDivisionsHandler(c *gin.Context) {
+	divisions, err := s.db.Queries().ListDivisions(c.Request.Context())
+	if err != nil {
+		log.Printf("Error listing divisions: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to fetch divisions",
+		})
+		return
+	}
+
+	response := make([]DivisionDao, 0, len(divisions))
+	for _, division := range divisions {
+		response = append(response, newDivisionDao(division))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (s *Server) createDivisionHandler(c *gin.Context) {
+	var divisionDco DivisionDco
+	if err := c.ShouldBindJSON(&divisionDco); err != nil {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Validation failed",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	division, err := s.db.Queries().CreateDivision(c.Request.Context(), database.CreateDivisionParams{
+		Name:      divisionDco.Name,
+		Volume:    divisionDco.Volume,
+		Tolerance: divisionDco.Tolerance,
+		Handicap:  divisionHandicap(divisionDco),
+	})
+	if err != nil {
+		log.Printf("Error creating division: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to create division",
+		})
+		return
+	}
+
+	log.Printf("Created division %s: %s", division.ID.String(), division.Name)
+
+	s.assignRunsToDivisions(c.Request.Context(), pgtype.UUID{})
+
+	c.JSON(http.StatusCreated, newDivisionDao(division))
+}
+
+func (s *Server) updateDivisionHandler(c *gin.Context) {
+	var divisionUUID pgtype.UUID
+	if err := divisionUUID.Scan(c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Invalid division ID format",
+		})
+		return
+	}
+
+	var divisionDco DivisionDco
+	if err := c.ShouldBindJSON(&divisionDco); err != nil {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Validation failed",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	division, err := s.db.Queries().UpdateDivision(c.Request.Context(), database.UpdateDivisionParams{
+		ID:        divisionUUID,
+		Name:      divisionDco.Name,
+		Volume:    divisionDco.Volume,
+		Tolerance: divisionDco.Tolerance,
+		Handicap:  divisionHandicap(divisionDco),
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, APIResponse{
+			Success: false,
+			Error:   "Division not found",
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Error updating division: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to update division",
+		})
+		return
+	}
+
+	s.assignRunsToDivisions(c.Request.Context(), pgtype.UUID{})
+
+	c.JSON(http.StatusOK, newDivisionDao(division))
+}
+
+func (s *Server) deleteDivisionHandler(c *gin.Context) {
+	var divisionUUID pgtype.UUID
+	if err := divisionUUID.Scan(c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Invalid division ID format",
+		})
+		return
+	}
+
+	deleted, err := s.db.Queries().DeleteDivision(c.Request.Context(), divisionUUID)
+	if err != nil {
+		log.Printf("Error deleting division: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to delete division",
+		})
+		return
+	}
+	if deleted == 0 {
+		c.JSON(http.StatusNotFound, APIResponse{
+			Success: false,
+			Error:   "Division not found",
+		})
+		return
+	}
+
+	// Runs of the deleted division may fall into a neighbouring band.
+	s.assignRunsToDivisions(c.Request.Context(), pgtype.UUID{})
+
+	c.JSON(http.StatusOK, APIResponse{Success: true})
+}
+
+// assignRunsToDivisions re-classifies one run, or every run when runID is
+// invalid. Bands may overlap, so any division change can move runs.
+func (s *Server) assignRunsToDivisions(ctx context.Context, runID pgtype.UUID) {
+	assigned, err := s.db.Queries().AssignRunsToDivisions(ctx, runID)
+	if err != nil {
+		log.Printf("Error assigning runs to divisions: %v", err)
+		return
+	}
+	log.Printf("Re-evaluated division assignment for %d runs", assigned)
+}
+
+// backfillDivisions classifies runs recorded before divisions existed or
+// while the server was down.
+func (s *Server) backfillDivisions() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	s.assignRunsToDivisions(ctx, pgtype.UUID{})
+}
+
+func divisionHandicap(divisionDco DivisionDco) float64 {
+	if divisionDco.Handicap == nil {
+		return 1
+	}
+	return *divisionDco.Handicap
+}
@@ -0,0 +1,505 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/tt-trichter/app/api/internal/database"
+)
+
+const (
+	EventDuelStarted  = "duel-started"
+	EventDuelFinished = "duel-finished"
+)
+
+const (
+	duelStatusOpen      = "open"
+	duelStatusFinished  = "finished"
+	duelStatusExpired   = "expired"
+	duelStatusCancelled = "cancelled"
+)
+
+const (
+	defaultDuelWindow  = 30 * time.Second
+	defaultDuelTimeout = 10 * time.Minute
+	duelExpiryInterval = time.Minute
+)
+
+type DuelDco struct {
+	DeviceA string `json:"deviceA" binding:"required"`
+	DeviceB string `json:"deviceB" binding:"required"`
+	Metric  string `json:"metric"`
+}
+
+type DuelSideDao struct {
+	DeviceID string   `json:"deviceId"`
+	Run      *RunDao  `json:"run"`
+	Value    *float64 `json:"value"`
+	Winner   bool     `json:"winner"`
+}
+
+type DuelDao struct {
+	ID         string        `json:"id"`
+	Metric     string        `json:"metric"`
+	Status     string        `json:"status"`
+	Sides      []DuelSideDao `json:"sides"`
+	StartedAt  time.Time     `json:"startedAt"`
+	FinishedAt *time.Time    `json:"finishedAt"`
+}
+
+type UserDuelsDao struct {
+	Wins   int       `json:"wins"`
+	Losses int       `json:"losses"`
+	Draws  int       `json:"draws"`
+	Duels  []DuelDao `json:"duels"`
+}
+
+func (s *Server) createDuelHandler(c *gin.Context) {
+	var duelDco DuelDco
+	if err := c.ShouldBindJSON(&duelDco); err != nil {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Validation failed",
+			Details: err.Error(),
+		})
+		return
+	}
+	if duelDco.Metric == "" {
+		duelDco.Metric = string(database.LeaderboardMetricRate)
+	}
+	if !database.LeaderboardMetric(duelDco.Metric).Valid() {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Validation failed",
+			Details: "metric must be one of rate, duration, volume",
+		})
+		return
+	}
+	if duelDco.DeviceA == duelDco.DeviceB {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Validation failed",
+			Details: "deviceA and deviceB must differ",
+		})
+		return
+	}
+
+	ctx := c.Request.Context()
+	tx, err := s.db.Pool().Begin(ctx)
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to start duel",
+		})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	queries := s.db.Queries().WithTx(tx)
+
+	// Both devices stay locked until the duel is stored, so concurrent
+	// requests cannot both find a device free. Locking in ID order keeps two
+	// requests for the same pair from deadlocking.
+	deviceIDs := []string{duelDco.DeviceA, duelDco.DeviceB}
+	slices.Sort(deviceIDs)
+	for _, deviceID := range deviceIDs {
+		device, err := queries.GetDeviceForUpdate(ctx, deviceID)
+		if errors.Is(err, pgx.ErrNoRows) || (err == nil && !device.RegisteredAt.Valid) {
+			c.JSON(http.StatusNotFound, APIResponse{
+				Success: false,
+				Error:   "Device not registered",
+				Details: deviceID,
+			})
+			return
+		}
+		if err == nil {
+			_, err = queries.GetOpenDuelForDevice(ctx, deviceID)
+			if err == nil {
+				c.JSON(http.StatusConflict, APIResponse{
+					Success: false,
+					Error:   "Device is already in an open duel",
+					Details: deviceID,
+				})
+				return
+			}
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			log.Printf("Error checking open duels: %v", err)
+			c.JSON(http.StatusInternalServerError, APIResponse{
+				Success: false,
+				Error:   "Failed to start duel",
+			})
+			return
+		}
+	}
+
+	duel, err := queries.CreateDuel(ctx, database.CreateDuelParams{
+		Metric:  duelDco.Metric,
+		DeviceA: duelDco.DeviceA,
+		DeviceB: duelDco.DeviceB,
+	})
+	if err == nil {
+		err = tx.Commit(ctx)
+	}
+	if err != nil {
+		log.Printf("Error creating duel: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to start duel",
+		})
+		return
+	}
+
+	log.Printf("Started duel %s between %s and %s", duel.ID.String(), duel.DeviceA, duel.DeviceB)
+
+	response := s.duelDao(ctx, duel)
+	s.events.Publish(EventDuelStarted, response)
+
+	c.JSON(http.StatusCreated, response)
+}
+
+func (s *Server) listDuelsHandler(c *gin.Context) {
+	params := database.ListDuelsParams{LimitCount: defaultPageLimit}
+	if status := c.Query("status"); status != "" {
+		params.Status = pgtype.Text{String: status, Valid: true}
+	}
+	if limitStr := c.Query("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > maxPageLimit {
+			c.JSON(http.StatusBadRequest, APIResponse{
+				Success: false,
+				Error:   "Invalid query parameters",
+				Details: "limit must be between 1 and 200",
+			})
+			return
+		}
+		params.LimitCount = int32(limit)
+	}
+
+	duels, err := s.db.Queries().ListDuels(c.Request.Context(), params)
+	if err != nil {
+		log.Printf("Error listing duels: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to fetch duels",
+		})
+		return
+	}
+
+	response := make([]DuelDao, 0, len(duels))
+	for _, duel := range duels {
+		response = append(response, s.duelDao(c.Request.Context(), duel))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (s *Server) getDuelHandler(c *gin.Context) {
+	var duelUUID pgtype.UUID
+	if err := duelUUID.Scan(c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Invalid duel ID format",
+		})
+		return
+	}
+
+	duel, err := s.db.Queries().GetDuel(c.Request.Context(), duelUUID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, APIResponse{
+			Success: false,
+			Error:   "Duel not found",
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Error getting duel: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to fetch duel",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, s.duelDao(c.Request.Context(), duel))
+}
+
+func (s *Server) cancelDuelHandler(c *gin.Context) {
+	var duelUUID pgtype.UUID
+	if err := duelUUID.Scan(c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Invalid duel ID format",
+		})
+		return
+	}
+
+	duel, err := s.db.Queries().FinishDuel(c.Request.Context(), database.FinishDuelParams{
+		ID:     duelUUID,
+		Status: duelStatusCancelled,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, APIResponse{
+			Success: false,
+			Error:   "Duel not found or already finished",
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Error cancelling duel: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to cancel duel",
+		})
+		return
+	}
+
+	s.duelFinished(c.Request.Context(), duel)
+
+	c.JSON(http.StatusOK, APIResponse{Success: true})
+}
+
+// getUserDuelsHandler returns a user's finished duels, optionally only those
+// against one opponent, together with the win/loss tally.
+func (s *Server) getUserDuelsHandler(c *gin.Context) {
+	userID := pgtype.Text{String: c.Param("id"), Valid: true}
+
+	var opponentID pgtype.Text
+	if opponent := c.Query("opponent"); opponent != "" {
+		opponentID = pgtype.Text{String: opponent, Valid: true}
+	}
+
+	duels, err := s.db.Queries().GetUserDuels(c.Request.Context(), database.GetUserDuelsParams{
+		UserID:     userID,
+		OpponentID: opponentID,
+		LimitCount: maxPageLimit,
+	})
+	if err != nil {
+		log.Printf("Error getting user duels: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to fetch duels",
+		})
+		return
+	}
+
+	response := UserDuelsDao{Duels: []DuelDao{}}
+	for _, row := range duels {
+		duel := database.Duel{
+			ID:          row.ID,
+			Metric:      row.Metric,
+			DeviceA:     row.DeviceA,
+			DeviceB:     row.DeviceB,
+			RunA:        row.RunA,
+			RunB:        row.RunB,
+			WinnerRunID: row.WinnerRunID,
+			Status:      row.Status,
+			StartedAt:   row.StartedAt,
+			FinishedAt:  row.FinishedAt,
+		}
+
+		ownRun := row.RunA
+		if row.UserA != userID {
+			ownRun = row.RunB
+		}
+		switch {
+		case !row.WinnerRunID.Valid:
+			response.Draws++
+		case row.WinnerRunID == ownRun:
+			response.Wins++
+		default:
+			response.Losses++
+		}
+
+		response.Duels = append(response.Duels, s.duelDao(c.Request.Context(), duel))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// recordDuelRun places a new run into the open duel of its device. The run
+// replaces an earlier attempt from the same device; the opponent's run only
+// counts if it was recorded within the duel window, otherwise it is dropped
+// as stale. Once both sides have a run the duel is decided.
+func (s *Server) recordDuelRun(ctx context.Context, run database.Run) {
+	if !run.DeviceID.Valid {
+		return
+	}
+
+	tx, err := s.db.Pool().Begin(ctx)
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	queries := s.db.Queries().WithTx(tx)
+
+	duel, err := queries.GetOpenDuelForDevice(ctx, run.DeviceID.String)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return
+	}
+	if err != nil {
+		log.Printf("Error getting open duel: %v", err)
+		return
+	}
+
+	runA, runB := duel.RunA, duel.RunB
+	own, other := &runA, &runB
+	if run.DeviceID.String == duel.DeviceB {
+		own, other = &runB, &runA
+	}
+	*own = run.ID
+
+	var opponent database.Run
+	if other.Valid {
+		opponent, err = queries.GetRunById(ctx, *other)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			log.Printf("Error getting duel run: %v", err)
+			return
+		}
+		gap := run.CreatedAt.Time.Sub(opponent.CreatedAt.Time).Abs()
+		if err != nil || opponent.DeletedAt.Valid || gap > s.duelWindow {
+			*other = pgtype.UUID{}
+		}
+	}
+
+	duel, err = queries.SetDuelRuns(ctx, database.SetDuelRunsParams{
+		ID:   duel.ID,
+		RunA: runA,
+		RunB: runB,
+	})
+	if err != nil {
+		log.Printf("Error updating duel runs: %v", err)
+		return
+	}
+
+	finished := runA.Valid && runB.Valid
+	if finished {
+		duel, err = queries.FinishDuel(ctx, database.FinishDuelParams{
+			ID:          duel.ID,
+			Status:      duelStatusFinished,
+			WinnerRunID: duelWinner(database.LeaderboardMetric(duel.Metric), run, opponent),
+		})
+		if err != nil {
+			log.Printf("Error finishing duel: %v", err)
+			return
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("Error committing duel run: %v", err)
+		return
+	}
+
+	if finished {
+		s.duelFinished(ctx, duel)
+	}
+}
+
+// duelWinner returns the run that wins on the metric, or an invalid UUID on
+// a draw.
+func duelWinner(metric database.LeaderboardMetric, a, b database.Run) pgtype.UUID {
+	valueA, okA := runMetricValue(a, metric)
+	valueB, okB := runMetricValue(b, metric)
+	switch {
+	case !okA || !okB || valueA == valueB:
+		return pgtype.UUID{}
+	case metric.Better(valueA, valueB):
+		return a.ID
+	default:
+		return b.ID
+	}
+}
+
+func runMetricValue(run database.Run, metric database.LeaderboardMetric) (float64, bool) {
+	var values map[string]float64
+	if err := json.Unmarshal(run.Data, &values); err != nil {
+		log.Printf("Error unmarshaling run data: %v", err)
+		return 0, false
+	}
+	value, ok := values[string(metric)]
+	return value, ok
+}
+
+// duelFinished announces a duel that was decided, expired or cancelled.
+func (s *Server) duelFinished(ctx context.Context, duel database.Duel) {
+	log.Printf("Duel %s ended: %s", duel.ID.String(), duel.Status)
+
+	s.events.Publish(EventDuelFinished, s.duelDao(ctx, duel))
+	s.rateDuel(ctx, duel)
+	s.recordDuelInTournaments(ctx, duel)
+}
+
+// runDuelExpiry closes duels that never received both runs.
+func (s *Server) runDuelExpiry() {
+	ticker := time.NewTicker(duelExpiryInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		s.expireDuels()
+	}
+}
+
+func (s *Server) expireDuels() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	cutoff := pgtype.Timestamp{Time: time.Now().UTC().Add(-s.duelTimeout), Valid: true}
+	duels, err := s.db.Queries().ExpireDuels(ctx, cutoff)
+	if err != nil {
+		log.Printf("Error expiring duels: %v", err)
+		return
+	}
+	for _, duel := range duels {
+		s.duelFinished(ctx, duel)
+	}
+}
+
+func (s *Server) duelDao(ctx context.Context, duel database.Duel) DuelDao {
+	dao := DuelDao{
+		ID:        duel.ID.String(),
+		Metric:    duel.Metric,
+		Status:    duel.Status,
+		StartedAt: duel.StartedAt.Time,
+		Sides: []DuelSideDao{
+			s.duelSideDao(ctx, duel, duel.DeviceA, duel.RunA),
+			s.duelSideDao(ctx, duel, duel.DeviceB, duel.RunB),
+		},
+	}
+	if duel.FinishedAt.Valid {
+		dao.FinishedAt = &duel.FinishedAt.Time
+	}
+	return dao
+}
+
+func (s *Server) duelSideDao(ctx context.Context, duel database.Duel, deviceID string, runID pgtype.UUID) DuelSideDao {
+	side := DuelSideDao{DeviceID: deviceID}
+	if !runID.Valid {
+		return side
+	}
+
+	run, err := s.db.Queries().GetRunById(ctx, runID)
+	if err != nil {
+		log.Printf("Error getting duel run %s: %v", runID.String(), err)
+		return side
+	}
+
+	runDao := s.runDaoWithUser(ctx, run)
+	side.Run = &runDao
+	if value, ok := runMetricValue(run, database.LeaderboardMetric(duel.Metric)); ok {
+		side.Value = &value
+	}
+	side.Winner = duel.WinnerRunID.Valid && duel.WinnerRunID == runID
+	return side
+}
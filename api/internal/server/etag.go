@@ -0,0 +1,47 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// jsonWithETag writes body as JSON tagged with a content hash, answering
+// 304 Not Modified when the client already holds the same representation.
+func jsonWithETag(c *gin.Context, status int, body any) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		log.Printf("Error marshaling response: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Internal server error",
+		})
+		return
+	}
+
+	sum := sha256.Sum256(payload)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	c.Header("ETag", etag)
+
+	if etagMatches(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.Data(status, "application/json; charset=utf-8", payload)
+}
+
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
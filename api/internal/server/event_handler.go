@@ -0,0 +1,365 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/tt-trichter/app/api/internal/database"
+)
+
+type EventDco struct {
+	Name      string     `json:"name" binding:"required"`
+	Venue     string     `json:"venue"`
+	StartsAt  time.Time  `json:"startsAt" binding:"required"`
+	EndsAt    *time.Time `json:"endsAt"`
+	DeviceIDs []string   `json:"deviceIds"`
+}
+
+type EventDao struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	Venue     *string    `json:"venue"`
+	StartsAt  time.Time  `json:"startsAt"`
+	EndsAt    *time.Time `json:"endsAt"`
+	DeviceIDs []string   `json:"deviceIds"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+type EventSummaryDao struct {
+	RunCount         int64      `json:"runCount"`
+	ParticipantCount int64      `json:"participantCount"`
+	TotalVolume      float64    `json:"totalVolume"`
+	BestRate         float64    `json:"bestRate"`
+	MeanRate         float64    `json:"meanRate"`
+	FastestDuration  float64    `json:"fastestDuration"`
+	FirstRunAt       *time.Time `json:"firstRunAt"`
+	LastRunAt        *time.Time `json:"lastRunAt"`
+}
+
+type EventDetailDao struct {
+	EventDao
+	Summary EventSummaryDao `json:"summary"`
+}
+
+type EventLeaderboardDao struct {
+	Event       EventDao              `json:"event"`
+	Metric      string                `json:"metric"`
+	Score       string                `json:"score,omitempty"`
+	Division    string                `json:"division,omitempty"`
+	Handicap    bool                  `json:"handicap"`
+	BestPerUser bool                  `json:"bestPerUser"`
+	Entries     []LeaderboardEntryDao `json:"entries"`
+}
+
+func newEventDao(event database.Event) EventDao {
+	dao := EventDao{
+		ID:        event.ID.String(),
+		Name:      event.Name,
+		StartsAt:  event.StartsAt.Time,
+		DeviceIDs: event.DeviceIds,
+		CreatedAt: event.CreatedAt.Time,
+	}
+	if dao.DeviceIDs == nil {
+		dao.DeviceIDs = []string{}
+	}
+	if event.Venue.Valid {
+		dao.Venue = &event.Venue.String
+	}
+	if event.EndsAt.Valid {
+		dao.EndsAt = &event.EndsAt.Time
+	}
+	return dao
+}
+
+func (s *Server) listEventsHandler(c *gin.Context) {
+	events, err := s.db.Queries().ListEvents(c.Request.Context())
+	if err != nil {
+		log.Printf("Error listing events: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to fetch events",
+		})
+		return
+	}
+
+	response := make([]EventDao, 0, len(events))
+	for _, event := range events {
+		response = append(response, newEventDao(event))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (s *Server) getEventHandler(c *gin.Context) {
+	event, ok := s.lookupEvent(c)
+	if !ok {
+		return
+	}
+
+	summary, err := s.db.Queries().GetEventSummary(c.Request.Context(), event.ID)
+	if err != nil {
+		log.Printf("Error getting event summary: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to fetch event",
+		})
+		return
+	}
+
+	response := EventDetailDao{
+		EventDao: newEventDao(event),
+		Summary: EventSummaryDao{
+			RunCount:         summary.RunCount,
+			ParticipantCount: summary.ParticipantCount,
+			TotalVolume:      summary.TotalVolume,
+			BestRate:         summary.BestRate,
+			MeanRate:         summary.MeanRate,
+			FastestDuration:  summary.FastestDuration,
+		},
+	}
+	if summary.RunCount > 0 {
+		response.Summary.FirstRunAt = &summary.FirstRunAt.Time
+		response.Summary.LastRunAt = &summary.LastRunAt.Time
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (s *Server) getEventLeaderboardHandler(c *gin.Context) {
+	event, ok := s.lookupEvent(c)
+	if !ok {
+		return
+	}
+
+	params, err := parseLeaderboardParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Invalid query parameters",
+			Details: err.Error(),
+		})
+		return
+	}
+	if !s.requireScoringFormula(c, params.Formula) {
+		return
+	}
+	params.EventID = event.ID
+
+	rows, err := s.db.Queries().GetLeaderboard(c.Request.Context(), params)
+	if err != nil {
+		log.Printf("Error getting event leaderboard: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to fetch leaderboard",
+		})
+		return
+	}
+
+	response := EventLeaderboardDao{
+		Event:       newEventDao(event),
+		Metric:      string(params.Metric),
+		Score:       params.Formula,
+		Handicap:    params.Handicap,
+		BestPerUser: params.BestPerUser,
+		Entries:     leaderboardEntries(rows),
+	}
+	if params.DivisionID.Valid {
+		response.Division = params.DivisionID.String()
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (s *Server) createEventHandler(c *gin.Context) {
+	var eventDco EventDco
+	if !bindEventDco(c, &eventDco) {
+		return
+	}
+
+	event, err := s.db.Queries().CreateEvent(c.Request.Context(), database.CreateEventParams{
+		Name:      eventDco.Name,
+		Venue:     eventVenue(eventDco),
+		StartsAt:  pgtype.Timestamp{Time: eventDco.StartsAt.UTC(), Valid: true},
+		EndsAt:    eventEndsAt(eventDco),
+		DeviceIds: eventDeviceIDs(eventDco),
+	})
+	if err != nil {
+		log.Printf("Error creating event: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to create event",
+		})
+		return
+	}
+
+	log.Printf("Created event %s: %s", event.ID.String(), event.Name)
+
+	s.assignRunsToEvents(c.Request.Context(), event.ID)
+
+	c.JSON(http.StatusCreated, newEventDao(event))
+}
+
+func (s *Server) updateEventHandler(c *gin.Context) {
+	var eventUUID pgtype.UUID
+	if err := eventUUID.Scan(c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Invalid event ID format",
+		})
+		return
+	}
+
+	var eventDco EventDco
+	if !bindEventDco(c, &eventDco) {
+		return
+	}
+
+	event, err := s.db.Queries().UpdateEvent(c.Request.Context(), database.UpdateEventParams{
+		ID:        eventUUID,
+		Name:      eventDco.Name,
+		Venue:     eventVenue(eventDco),
+		StartsAt:  pgtype.Timestamp{Time: eventDco.StartsAt.UTC(), Valid: true},
+		EndsAt:    eventEndsAt(eventDco),
+		DeviceIds: eventDeviceIDs(eventDco),
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, APIResponse{
+			Success: false,
+			Error:   "Event not found",
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Error updating event: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to update event",
+		})
+		return
+	}
+
+	s.assignRunsToEvents(c.Request.Context(), event.ID)
+
+	c.JSON(http.StatusOK, newEventDao(event))
+}
+
+func (s *Server) deleteEventHandler(c *gin.Context) {
+	var eventUUID pgtype.UUID
+	if err := eventUUID.Scan(c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Invalid event ID format",
+		})
+		return
+	}
+
+	deleted, err := s.db.Queries().DeleteEvent(c.Request.Context(), eventUUID)
+	if err != nil {
+		log.Printf("Error deleting event: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to delete event",
+		})
+		return
+	}
+	if deleted == 0 {
+		c.JSON(http.StatusNotFound, APIResponse{
+			Success: false,
+			Error:   "Event not found",
+		})
+		return
+	}
+
+	// Runs of the deleted event may belong to an overlapping one.
+	s.assignRunsToEvents(c.Request.Context(), pgtype.UUID{})
+
+	c.JSON(http.StatusOK, APIResponse{Success: true})
+}
+
+// lookupEvent loads the event named by the :id path parameter, writing the
+// error response itself when it cannot.
+func (s *Server) lookupEvent(c *gin.Context) (database.Event, bool) {
+	var eventUUID pgtype.UUID
+	if err := eventUUID.Scan(c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Invalid event ID format",
+		})
+		return database.Event{}, false
+	}
+
+	event, err := s.db.Queries().GetEvent(c.Request.Context(), eventUUID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, APIResponse{
+			Success: false,
+			Error:   "Event not found",
+		})
+		return event, false
+	}
+	if err != nil {
+		log.Printf("Error getting event: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to fetch event",
+		})
+		return event, false
+	}
+
+	return event, true
+}
+
+// assignRunsToEvents re-evaluates the event of every unassigned run and of
+// the runs currently attached to eventID. This is also how runs recorded
+// before an event was created get backfilled into it.
+func (s *Server) assignRunsToEvents(ctx context.Context, eventID pgtype.UUID) {
+	assigned, err := s.db.Queries().AssignRunsToEvents(ctx, eventID)
+	if err != nil {
+		log.Printf("Error assigning runs to events: %v", err)
+		return
+	}
+	log.Printf("Re-evaluated event assignment for %d runs", assigned)
+}
+
+func bindEventDco(c *gin.Context, eventDco *EventDco) bool {
+	if err := c.ShouldBindJSON(eventDco); err != nil {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Validation failed",
+			Details: err.Error(),
+		})
+		return false
+	}
+	if eventDco.EndsAt != nil && !eventDco.EndsAt.After(eventDco.StartsAt) {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Validation failed",
+			Details: "endsAt must be after startsAt",
+		})
+		return false
+	}
+	return true
+}
+
+func eventVenue(eventDco EventDco) pgtype.Text {
+	return pgtype.Text{String: eventDco.Venue, Valid: eventDco.Venue != ""}
+}
+
+func eventEndsAt(eventDco EventDco) pgtype.Timestamp {
+	if eventDco.EndsAt == nil {
+		return pgtype.Timestamp{}
+	}
+	return pgtype.Timestamp{Time: eventDco.EndsAt.UTC(), Valid: true}
+}
+
+func eventDeviceIDs(eventDco EventDco) []string {
+	if eventDco.DeviceIDs == nil {
+		return []string{}
+	}
+	return eventDco.DeviceIDs
+}
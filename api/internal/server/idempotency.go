@@ -0,0 +1,253 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/tt-trichter/app/api/internal/database"
+)
+
+const (
+	idempotencyKeyHeader        = "Idempotency-Key"
+	maxIdempotencyKeyLength     = 255
+	defaultIdempotencyRetention = 24 * time.Hour
+	idempotencyPurgeInterval    = time.Hour
+	// maxIdempotentBodyBytes bounds the bodies of requests with a key; it
+	// leaves room for a full-resolution photo.
+	maxIdempotentBodyBytes = 16 << 20
+)
+
+// responseRecorder keeps a copy of everything a handler writes so the
+// response can be replayed later.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(data string) (int, error) {
+	w.body.WriteString(data)
+	return w.ResponseWriter.WriteString(data)
+}
+
+// idempotent lets clients retry a request safely by sending an
+// Idempotency-Key header. The first response for a key is stored and
+// replayed to retries within the retention window, and a key reused for a
+// different request is answered with 422. Requests without the header are
+// handled as usual.
+func (s *Server) idempotent() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(idempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			c.AbortWithStatusJSON(http.StatusBadRequest, APIResponse{
+				Success: false,
+				Error:   "Invalid Idempotency-Key",
+				Details: "must be at most 255 characters",
+			})
+			return
+		}
+
+		// The body is buffered to fingerprint it, so it is bounded before
+		// anything is read.
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxIdempotentBodyBytes))
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, APIResponse{
+				Success: false,
+				Error:   "Request body too large",
+			})
+			return
+		}
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, APIResponse{
+				Success: false,
+				Error:   "Failed to read request body",
+			})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		route := c.Request.Method + " " + c.FullPath()
+		fingerprint := requestFingerprint(c.ContentType(), body)
+
+		claimed, err := s.db.Queries().ClaimIdempotencyKey(c.Request.Context(), database.ClaimIdempotencyKeyParams{
+			Key:           key,
+			Route:         route,
+			Fingerprint:   fingerprint,
+			ExpiredBefore: pgtype.Timestamp{Time: time.Now().Add(-s.idempotencyRetention), Valid: true},
+		})
+		if err != nil {
+			log.Printf("Error claiming idempotency key: %v", err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, APIResponse{
+				Success: false,
+				Error:   "Internal server error",
+			})
+			return
+		}
+		if claimed == 0 {
+			s.replayResponse(c, key, route, fingerprint)
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		// The client that sent the key has often given up by now, which must
+		// not keep its retries from seeing the response.
+		ctx := context.WithoutCancel(c.Request.Context())
+
+		// Server errors are not kept, so a retry gets another chance.
+		if recorder.Status() >= http.StatusInternalServerError {
+			err = s.db.Queries().ReleaseIdempotencyKey(ctx, database.ReleaseIdempotencyKeyParams{
+				Key:   key,
+				Route: route,
+			})
+		} else {
+			var contentType pgtype.Text
+			if value := recorder.Header().Get("Content-Type"); value != "" {
+				contentType = pgtype.Text{String: value, Valid: true}
+			}
+			err = s.db.Queries().CompleteIdempotencyKey(ctx, database.CompleteIdempotencyKeyParams{
+				Key:         key,
+				Route:       route,
+				StatusCode:  pgtype.Int4{Int32: int32(recorder.Status()), Valid: true},
+				ContentType: contentType,
+				Response:    recorder.body.Bytes(),
+			})
+		}
+		if err != nil {
+			log.Printf("Error storing idempotent response: %v", err)
+		}
+	}
+}
+
+// replayResponse answers a retry with the response stored for its key.
+func (s *Server) replayResponse(c *gin.Context, key, route, fingerprint string) {
+	stored, err := s.db.Queries().GetIdempotencyKey(c.Request.Context(), database.GetIdempotencyKeyParams{
+		Key:   key,
+		Route: route,
+	})
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && !stored.StatusCode.Valid) {
+		c.AbortWithStatusJSON(http.StatusConflict, APIResponse{
+			Success: false,
+			Error:   "A request with this Idempotency-Key is still in progress",
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Error getting idempotency key: %v", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Internal server error",
+		})
+		return
+	}
+	if stored.Fingerprint != fingerprint {
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, APIResponse{
+			Success: false,
+			Error:   "Idempotency-Key was already used for a different request",
+		})
+		return
+	}
+
+	c.Header("Idempotent-Replayed", "true")
+	c.Data(int(stored.StatusCode.Int32), stored.ContentType.String, stored.Response)
+	c.Abort()
+}
+
+// requestFingerprint hashes what identifies a request. Multipart bodies are
+// hashed part by part, since clients pick a new boundary for every attempt.
+func requestFingerprint(contentType string, body []byte) string {
+	mediaType, params, _ := mime.ParseMediaType(contentType)
+	if strings.HasPrefix(mediaType, "multipart/") {
+		if fingerprint, err := multipartFingerprint(mediaType, params["boundary"], body); err == nil {
+			return fingerprint
+		}
+	}
+
+	hash := sha256.New()
+	hash.Write([]byte(contentType))
+	hash.Write([]byte{0})
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// multipartFingerprint hashes the fields of a multipart body and the
+// contents of its files, leaving out the boundary.
+func multipartFingerprint(mediaType, boundary string, body []byte) (string, error) {
+	hash := sha256.New()
+	hash.Write([]byte(mediaType))
+	hash.Write([]byte{0})
+
+	reader := multipart.NewReader(bytes.NewReader(body), boundary)
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+
+		content := sha256.New()
+		if _, err := io.Copy(content, part); err != nil {
+			return "", err
+		}
+		for _, field := range []string{part.FormName(), part.FileName(), part.Header.Get("Content-Type")} {
+			hash.Write([]byte(field))
+			hash.Write([]byte{0})
+		}
+		hash.Write(content.Sum(nil))
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// runIdempotencyKeyExpiry removes stored responses once they are past the
+// retention window.
+func (s *Server) runIdempotencyKeyExpiry() {
+	ticker := time.NewTicker(idempotencyPurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		s.purgeIdempotencyKeys()
+		<-ticker.C
+	}
+}
+
+func (s *Server) purgeIdempotencyKeys() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	cutoff := pgtype.Timestamp{Time: time.Now().Add(-s.idempotencyRetention), Valid: true}
+	purged, err := s.db.Queries().PurgeIdempotencyKeys(ctx, cutoff)
+	if err != nil {
+		log.Printf("Error purging idempotency keys: %v", err)
+		return
+	}
+	if purged > 0 {
+		log.Printf("Purged %d idempotency keys older than %s", purged, s.idempotencyRetention)
+	}
+}
@@ -0,0 +1,261 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/tt-trichter/app/api/internal/database"
+)
+
+type LeaderboardEntryDao struct {
+	RunDao
+	Rank  int64   `json:"rank"`
+	Score float64 `json:"score"`
+}
+
+type LeaderboardDao struct {
+	Window      string                `json:"window"`
+	From        *time.Time            `json:"from"`
+	To          *time.Time            `json:"to"`
+	Metric      string                `json:"metric"`
+	Score       string                `json:"score,omitempty"`
+	Division    string                `json:"division,omitempty"`
+	Handicap    bool                  `json:"handicap"`
+	BestPerUser bool                  `json:"bestPerUser"`
+	Entries     []LeaderboardEntryDao `json:"entries"`
+}
+
+func (s *Server) getLeaderboardHandler(c *gin.Context) {
+	window := c.Param("window")
+
+	from, to, ok := s.resolveLeaderboardWindow(c, window)
+	if !ok {
+		return
+	}
+
+	params, err := parseLeaderboardParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Invalid query parameters",
+			Details: err.Error(),
+		})
+		return
+	}
+	if !s.requireScoringFormula(c, params.Formula) {
+		return
+	}
+
+	response := LeaderboardDao{
+		Window:      window,
+		Metric:      string(params.Metric),
+		Score:       params.Formula,
+		Handicap:    params.Handicap,
+		BestPerUser: params.BestPerUser,
+	}
+	if !from.IsZero() {
+		response.From = &from
+		params.From = pgtype.Timestamp{Time: from.UTC(), Valid: true}
+	}
+	if params.DivisionID.Valid {
+		response.Division = params.DivisionID.String()
+	}
+	if !to.IsZero() {
+		response.To = &to
+		params.To = pgtype.Timestamp{Time: to.UTC(), Valid: true}
+	}
+
+	rows, err := s.db.Queries().GetLeaderboard(c.Request.Context(), params)
+	if err != nil {
+		log.Printf("Error getting leaderboard: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to fetch leaderboard",
+		})
+		return
+	}
+	response.Entries = leaderboardEntries(rows)
+
+	c.JSON(http.StatusOK, response)
+}
+
+// parseLeaderboardParams reads the ranking options shared by all leaderboards.
+// A score parameter names a scoring formula that overrides the metric, a
+// division parameter restricts the board to one volume class.
+func parseLeaderboardParams(c *gin.Context) (database.GetLeaderboardParams, error) {
+	params := database.GetLeaderboardParams{
+		Metric:  database.LeaderboardMetric(c.DefaultQuery("metric", string(database.LeaderboardMetricRate))),
+		Formula: c.Query("score"),
+		Limit:   defaultPageLimit,
+	}
+	if !params.Metric.Valid() {
+		return params, errors.New("metric must be one of rate, duration, volume")
+	}
+
+	if division := c.Query("division"); division != "" {
+		if err := params.DivisionID.Scan(division); err != nil {
+			return params, errors.New("division must be a division ID")
+		}
+	}
+
+	if handicap := c.Query("handicap"); handicap != "" {
+		value, err := strconv.ParseBool(handicap)
+		if err != nil {
+			return params, errors.New("handicap must be true or false")
+		}
+		params.Handicap = value
+	}
+
+	if bestPerUser := c.Query("bestPerUser"); bestPerUser != "" {
+		value, err := strconv.ParseBool(bestPerUser)
+		if err != nil {
+			return params, errors.New("bestPerUser must be true or false")
+		}
+		params.BestPerUser = value
+	}
+
+	if limitStr := c.Query("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > maxPageLimit {
+			return params, errors.New("limit must be between 1 and 200")
+		}
+		params.Limit = int32(limit)
+	}
+
+	return params, nil
+}
+
+func leaderboardEntries(rows []database.GetLeaderboardRow) []LeaderboardEntryDao {
+	entries := []LeaderboardEntryDao{}
+	for _, row := range rows {
+		entry := LeaderboardEntryDao{
+			RunDao: RunDao{
+				ID:        row.ID.String(),
+				Image:     row.Image,
+				CreatedAt: row.CreatedAt.Time,
+			},
+			Rank:  row.Rank,
+			Score: row.Score,
+		}
+
+		if err := json.Unmarshal(row.Data, &entry.Data); err != nil {
+			log.Printf("Error unmarshaling run data: %v", err)
+			continue
+		}
+
+		if row.UserName.Valid {
+			entry.User = &UserInfo{
+				ID:       row.UserIDFull.String,
+				Name:     row.UserName.String,
+				Username: row.UserUsername.String,
+			}
+		}
+
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// resolveLeaderboardWindow resolves a window like leaderboardWindow and also
+// knows "season", the bounds of the season running now. It writes the error
+// response itself.
+func (s *Server) resolveLeaderboardWindow(c *gin.Context, window string) (time.Time, time.Time, bool) {
+	if window != "season" {
+		from, to, err := s.leaderboardWindow(window, c.Query("from"), c.Query("to"), time.Now())
+		if err != nil {
+			c.JSON(http.StatusBadRequest, APIResponse{
+				Success: false,
+				Error:   "Invalid leaderboard window",
+				Details: err.Error(),
+			})
+			return time.Time{}, time.Time{}, false
+		}
+		return from, to, true
+	}
+
+	season, err := s.db.Queries().GetCurrentSeason(c.Request.Context(), pgtype.Timestamp{Time: time.Now().UTC(), Valid: true})
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, APIResponse{
+			Success: false,
+			Error:   "No season is running",
+		})
+		return time.Time{}, time.Time{}, false
+	}
+	if err != nil {
+		log.Printf("Error getting current season: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to fetch leaderboard",
+		})
+		return time.Time{}, time.Time{}, false
+	}
+	return season.StartsAt.Time, season.EndsAt.Time, true
+}
+
+// leaderboardWindow resolves a named window to its [from, to) bounds. Windows
+// follow event nights, so "today" after midnight still means tonight's party.
+// A zero bound is open-ended.
+func (s *Server) leaderboardWindow(window, fromParam, toParam string, now time.Time) (time.Time, time.Time, error) {
+	tonight := s.nights.night(now)
+
+	switch window {
+	case "today":
+		return s.nights.start(tonight), s.nights.start(tonight.AddDate(0, 0, 1)), nil
+	case "week":
+		// Weeks start on Monday night.
+		offset := (int(tonight.Weekday()) + 6) % 7
+		monday := tonight.AddDate(0, 0, -offset)
+		return s.nights.start(monday), s.nights.start(monday.AddDate(0, 0, 7)), nil
+	case "month":
+		first := time.Date(tonight.Year(), tonight.Month(), 1, 0, 0, 0, 0, tonight.Location())
+		return s.nights.start(first), s.nights.start(first.AddDate(0, 1, 0)), nil
+	case "all":
+		return time.Time{}, time.Time{}, nil
+	case "custom":
+		if fromParam == "" && toParam == "" {
+			return time.Time{}, time.Time{}, errors.New("custom window requires from and/or to")
+		}
+		var from, to time.Time
+		var err error
+		if fromParam != "" {
+			if from, err = s.nightBoundary(fromParam, false); err != nil {
+				return time.Time{}, time.Time{}, errors.New("from must be an RFC 3339 timestamp or YYYY-MM-DD date")
+			}
+		}
+		if toParam != "" {
+			if to, err = s.nightBoundary(toParam, true); err != nil {
+				return time.Time{}, time.Time{}, errors.New("to must be an RFC 3339 timestamp or YYYY-MM-DD date")
+			}
+		}
+		if !from.IsZero() && !to.IsZero() && !from.Before(to) {
+			return time.Time{}, time.Time{}, errors.New("from must be before to")
+		}
+		return from, to, nil
+	default:
+		return time.Time{}, time.Time{}, errors.New("window must be one of today, week, month, season, all, custom")
+	}
+}
+
+// nightBoundary parses a custom window bound. Plain dates name event nights:
+// as a lower bound the night's start, as an upper bound the night's end.
+func (s *Server) nightBoundary(value string, end bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	day, err := time.ParseInLocation(time.DateOnly, value, s.nights.loc)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if end {
+		day = day.AddDate(0, 0, 1)
+	}
+	return s.nights.start(day), nil
+}
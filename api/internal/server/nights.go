@@ -0,0 +1,94 @@
+package server
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	// The production image is alpine without zoneinfo.
+	_ "time/tzdata"
+)
+
+const (
+	defaultTimezone     = "Europe/Berlin"
+	defaultNightEndHour = 6
+)
+
+// nightClock groups instants into event nights. A night belongs to the local
+// calendar day it started on and runs until endHour the following morning, so
+// a run at 02:00 on Saturday still counts towards Friday night.
+type nightClock struct {
+	loc     *time.Location
+	endHour int
+}
+
+func nightClockFromEnv() nightClock {
+	name := os.Getenv("TIMEZONE")
+	if name == "" {
+		name = defaultTimezone
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		log.Printf("Unknown TIMEZONE %q, falling back to %s: %v", name, defaultTimezone, err)
+		loc, _ = time.LoadLocation(defaultTimezone)
+	}
+
+	endHour := defaultNightEndHour
+	if value := os.Getenv("NIGHT_END_HOUR"); value != "" {
+		hour, err := strconv.Atoi(value)
+		if err != nil || hour < 0 || hour > 23 {
+			log.Printf("Invalid NIGHT_END_HOUR %q, falling back to %d", value, defaultNightEndHour)
+		} else {
+			endHour = hour
+		}
+	}
+
+	return nightClock{loc: loc, endHour: endHour}
+}
+
+// night returns the local date of the night t falls into, at midnight.
+func (n nightClock) night(t time.Time) time.Time {
+	local := t.In(n.loc).Add(-time.Duration(n.endHour) * time.Hour)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, n.loc)
+}
+
+// start returns the instant the night dated day begins.
+func (n nightClock) start(day time.Time) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), n.endHour, 0, 0, 0, n.loc)
+}
+
+// nightStreak counts consecutive nights as runs are fed to it in
+// chronological order.
+type nightStreak struct {
+	last   time.Time
+	length int
+}
+
+func (st *nightStreak) add(night time.Time) {
+	switch {
+	case st.last.IsZero() || night.After(st.last.AddDate(0, 0, 1)):
+		st.length = 1
+	case night.Equal(st.last.AddDate(0, 0, 1)):
+		st.length++
+	default:
+		return
+	}
+	st.last = night
+}
+
+// streaks returns the current and longest runs of consecutive nights in
+// times, which must be sorted ascending. The current streak is only kept alive
+// while its latest night is tonight or last night.
+func (n nightClock) streaks(times []time.Time, now time.Time) (current, longest int) {
+	var streak nightStreak
+	for _, t := range times {
+		streak.add(n.night(t))
+		longest = max(longest, streak.length)
+	}
+
+	if !streak.last.IsZero() && !streak.last.Before(n.night(now).AddDate(0, 0, -1)) {
+		current = streak.length
+	}
+	return current, longest
+}
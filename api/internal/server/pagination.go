@@ -0,0 +1,142 @@
+package server
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/tt-trichter/app/api/internal/database"
+)
+
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 200
+)
+
+// runCursor is the opaque keyset position handed out as nextCursor. The sort
+// and order are embedded so a cursor cannot be replayed against another listing.
+type runCursor struct {
+	Sort  database.RunSort `json:"s"`
+	Order string           `json:"o"`
+	Value string           `json:"v"`
+	ID    string           `json:"id"`
+}
+
+func encodeRunCursor(cursor runCursor) string {
+	raw, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeRunCursor(encoded string) (runCursor, error) {
+	var cursor runCursor
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return cursor, errors.New("malformed cursor")
+	}
+	if err := json.Unmarshal(raw, &cursor); err != nil {
+		return cursor, errors.New("malformed cursor")
+	}
+	return cursor, nil
+}
+
+// parseRunListParams reads the sort, order, filter and cursor query
+// parameters shared by the run listings.
+func parseRunListParams(c *gin.Context) (database.ListRunsWithUsersParams, error) {
+	params := database.ListRunsWithUsersParams{
+		Sort:       database.RunSort(c.DefaultQuery("sort", string(database.RunSortRate))),
+		Descending: true,
+		Limit:      defaultPageLimit,
+	}
+
+	if !params.Sort.Valid() {
+		return params, errors.New("sort must be one of rate, duration, volume, createdAt")
+	}
+
+	order := c.DefaultQuery("order", "desc")
+	switch order {
+	case "desc":
+	case "asc":
+		params.Descending = false
+	default:
+		return params, errors.New("order must be asc or desc")
+	}
+
+	if limitStr := c.Query("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > maxPageLimit {
+			return params, errors.New("limit must be between 1 and 200")
+		}
+		params.Limit = int32(limit)
+	}
+
+	if userID := c.Query("userId"); userID != "" {
+		params.UserID = pgtype.Text{String: userID, Valid: true}
+	}
+
+	if team := c.Query("team"); team != "" {
+		if err := params.TeamID.Scan(team); err != nil {
+			return params, errors.New("team must be a team ID")
+		}
+	}
+
+	if hasUser := c.Query("hasUser"); hasUser != "" {
+		value, err := strconv.ParseBool(hasUser)
+		if err != nil {
+			return params, errors.New("hasUser must be true or false")
+		}
+		params.HasUser = pgtype.Bool{Bool: value, Valid: true}
+	}
+
+	if from := c.Query("from"); from != "" {
+		t, err := parseTimeParam(from, false)
+		if err != nil {
+			return params, errors.New("from must be an RFC 3339 timestamp or YYYY-MM-DD date")
+		}
+		params.From = pgtype.Timestamp{Time: t, Valid: true}
+	}
+
+	if to := c.Query("to"); to != "" {
+		t, err := parseTimeParam(to, true)
+		if err != nil {
+			return params, errors.New("to must be an RFC 3339 timestamp or YYYY-MM-DD date")
+		}
+		params.To = pgtype.Timestamp{Time: t, Valid: true}
+	}
+
+	if encoded := c.Query("cursor"); encoded != "" {
+		cursor, err := decodeRunCursor(encoded)
+		if err != nil {
+			return params, err
+		}
+		if cursor.Sort != params.Sort || cursor.Order != order {
+			return params, errors.New("cursor does not match sort and order")
+		}
+		if err := params.AfterID.Scan(cursor.ID); err != nil {
+			return params, errors.New("malformed cursor")
+		}
+		params.AfterValue = cursor.Value
+	}
+
+	return params, nil
+}
+
+// parseTimeParam accepts RFC 3339 timestamps or plain dates. A plain date used
+// as an exclusive upper bound is moved to the end of that day.
+func parseTimeParam(value string, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), nil
+	}
+
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
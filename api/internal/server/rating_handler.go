@@ -0,0 +1,238 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/tt-trichter/app/api/internal/database"
+	"github.com/tt-trichter/app/api/internal/rating"
+)
+
+type RatingEntryDao struct {
+	Rank         int       `json:"rank"`
+	User         UserInfo  `json:"user"`
+	Rating       float64   `json:"rating"`
+	Games        int32     `json:"games"`
+	LastPlayedAt time.Time `json:"lastPlayedAt"`
+}
+
+type RatingHistoryDao struct {
+	OpponentID   string    `json:"opponentId"`
+	Source       string    `json:"source"`
+	SourceID     string    `json:"sourceId"`
+	Score        float64   `json:"score"`
+	RatingBefore float64   `json:"ratingBefore"`
+	RatingAfter  float64   `json:"ratingAfter"`
+	PlayedAt     time.Time `json:"playedAt"`
+}
+
+type UserRatingDao struct {
+	Rating       float64            `json:"rating"`
+	Games        int32              `json:"games"`
+	LastPlayedAt *time.Time         `json:"lastPlayedAt"`
+	History      []RatingHistoryDao `json:"history"`
+}
+
+// getRatingsHandler ranks all rated users by their current, decayed rating.
+func (s *Server) getRatingsHandler(c *gin.Context) {
+	limit := defaultPageLimit
+	if limitStr := c.Query("limit"); limitStr != "" {
+		value, err := strconv.Atoi(limitStr)
+		if err != nil || value < 1 || value > maxPageLimit {
+			c.JSON(http.StatusBadRequest, APIResponse{
+				Success: false,
+				Error:   "Invalid query parameters",
+				Details: "limit must be between 1 and 200",
+			})
+			return
+		}
+		limit = value
+	}
+
+	ratings, err := s.db.Queries().GetUserRatings(c.Request.Context())
+	if err != nil {
+		log.Printf("Error getting ratings: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to fetch ratings",
+		})
+		return
+	}
+
+	now := time.Now()
+	entries := make([]RatingEntryDao, 0, len(ratings))
+	for _, row := range ratings {
+		entries = append(entries, RatingEntryDao{
+			User: UserInfo{
+				ID:       row.UserID,
+				Name:     row.UserName,
+				Username: row.UserUsername,
+			},
+			Rating:       s.ratings.Decayed(row.Rating, row.LastPlayedAt.Time, now),
+			Games:        row.Games,
+			LastPlayedAt: row.LastPlayedAt.Time,
+		})
+	}
+
+	// Decay depends on the current time, so the order is settled here
+	// rather than in SQL.
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Rating > entries[j].Rating
+	})
+	for i := range entries {
+		entries[i].Rank = i + 1
+		if i > 0 && entries[i].Rating == entries[i-1].Rating {
+			entries[i].Rank = entries[i-1].Rank
+		}
+	}
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+func (s *Server) getUserRatingHandler(c *gin.Context) {
+	userID := c.Param("id")
+	ctx := c.Request.Context()
+
+	response := UserRatingDao{
+		Rating:  s.ratings.Initial,
+		History: []RatingHistoryDao{},
+	}
+
+	current, err := s.db.Queries().GetUserRating(ctx, userID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		log.Printf("Error getting user rating: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to fetch rating",
+		})
+		return
+	}
+	if err == nil {
+		response.Rating = s.ratings.Decayed(current.Rating, current.LastPlayedAt.Time, time.Now())
+		response.Games = current.Games
+		response.LastPlayedAt = &current.LastPlayedAt.Time
+	}
+
+	history, err := s.db.Queries().GetRatingHistory(ctx, database.GetRatingHistoryParams{
+		UserID: userID,
+		Limit:  maxPageLimit,
+	})
+	if err != nil {
+		log.Printf("Error getting rating history: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to fetch rating",
+		})
+		return
+	}
+	for _, entry := range history {
+		response.History = append(response.History, RatingHistoryDao{
+			OpponentID:   entry.OpponentID,
+			Source:       entry.Source,
+			SourceID:     entry.SourceID.String(),
+			Score:        entry.Score,
+			RatingBefore: entry.RatingBefore,
+			RatingAfter:  entry.RatingAfter,
+			PlayedAt:     entry.PlayedAt.Time,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// rateDuel updates the ratings of both duellists once a duel is decided.
+// Duels with unassigned runs are skipped; a replay picks them up once the
+// runs have users.
+func (s *Server) rateDuel(ctx context.Context, duel database.Duel) {
+	if duel.Status != duelStatusFinished {
+		return
+	}
+
+	runA, errA := s.db.Queries().GetRunById(ctx, duel.RunA)
+	runB, errB := s.db.Queries().GetRunById(ctx, duel.RunB)
+	if errA != nil || errB != nil || !runA.UserID.Valid || !runB.UserID.Valid || runA.UserID == runB.UserID {
+		return
+	}
+
+	scoreA := 0.5
+	switch {
+	case duel.WinnerRunID == runA.ID:
+		scoreA = 1
+	case duel.WinnerRunID == runB.ID:
+		scoreA = 0
+	}
+
+	s.rateGame(ctx, rating.Game{
+		Source:   rating.SourceDuel,
+		SourceID: duel.ID,
+		PlayerA:  runA.UserID.String,
+		PlayerB:  runB.UserID.String,
+		ScoreA:   scoreA,
+		PlayedAt: duel.FinishedAt.Time,
+	})
+}
+
+// rateMatch updates ratings after a tournament match that was decided by
+// linked runs. Matches decided by a duel were already rated with the duel.
+func (s *Server) rateMatch(ctx context.Context, tournamentID, matchID pgtype.UUID) {
+	match, err := s.db.Queries().GetTournamentMatch(ctx, database.GetTournamentMatchParams{
+		ID:           matchID,
+		TournamentID: tournamentID,
+	})
+	if err != nil {
+		log.Printf("Error getting match for rating: %v", err)
+		return
+	}
+	if match.DuelID.Valid || match.Status != matchStatusFinished {
+		return
+	}
+
+	scoreA := 0.0
+	if match.WinnerUserID == match.PlayerA {
+		scoreA = 1
+	}
+
+	s.rateGame(ctx, rating.Game{
+		Source:   rating.SourceMatch,
+		SourceID: match.ID,
+		PlayerA:  match.PlayerA.String,
+		PlayerB:  match.PlayerB.String,
+		ScoreA:   scoreA,
+		PlayedAt: match.FinishedAt.Time,
+	})
+}
+
+func (s *Server) rateGame(ctx context.Context, game rating.Game) {
+	tx, err := s.db.Pool().Begin(ctx)
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	changes, err := s.ratings.Apply(ctx, s.db.Queries().WithTx(tx), game)
+	if err != nil {
+		log.Printf("Error rating %s %s: %v", game.Source, game.SourceID.String(), err)
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("Error committing rating: %v", err)
+		return
+	}
+
+	for _, change := range changes {
+		log.Printf("Rating of %s: %.1f -> %.1f", change.UserID, change.Before, change.After)
+	}
+}
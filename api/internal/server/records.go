@@ -0,0 +1,203 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"slices"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/tt-trichter/app/api/internal/database"
+)
+
+const (
+	EventPersonalBest = "personal-best"
+	EventRecordBroken = "record-broken"
+)
+
+type PreviousBestDao struct {
+	RunID string    `json:"runId"`
+	User  *UserInfo `json:"user"`
+	Value float64   `json:"value"`
+}
+
+type RecordEventDao struct {
+	Run      RunDao           `json:"run"`
+	Metric   string           `json:"metric"`
+	Value    float64          `json:"value"`
+	Previous *PreviousBestDao `json:"previous"`
+}
+
+type recordAnnouncement struct {
+	event   string
+	payload RecordEventDao
+}
+
+// detectRecords compares a freshly created or reassigned run against
+// everything recorded before it, persists which personal bests and all-time
+// records it set, and announces them on the event stream.
+func (s *Server) detectRecords(ctx context.Context, run database.Run) {
+	personalBests, records, announcements, err := s.findRecords(ctx, run)
+	if err != nil {
+		log.Printf("Error detecting records of run %s: %v", run.ID.String(), err)
+		return
+	}
+
+	if err := s.db.Queries().UpdateRunRecords(ctx, database.UpdateRunRecordsParams{
+		ID:            run.ID,
+		PersonalBests: personalBests,
+		Records:       records,
+	}); err != nil {
+		log.Printf("Error saving run records: %v", err)
+		return
+	}
+
+	if len(announcements) == 0 {
+		return
+	}
+
+	runDao := s.runDaoWithUser(ctx, run)
+	for _, announcement := range announcements {
+		announcement.payload.Run = runDao
+		s.events.Publish(announcement.event, announcement.payload)
+	}
+}
+
+// findRecords returns the metrics a run is a personal best and an all-time
+// record in, with the announcements for them.
+func (s *Server) findRecords(ctx context.Context, run database.Run) ([]string, []string, []recordAnnouncement, error) {
+	// Decode straight to float64 so values compare exactly with what
+	// Postgres parses out of the same JSON.
+	var values map[string]float64
+	if err := json.Unmarshal(run.Data, &values); err != nil {
+		return nil, nil, nil, err
+	}
+
+	personalBests := []string{}
+	records := []string{}
+	var announcements []recordAnnouncement
+
+	for _, metric := range database.LeaderboardMetrics {
+		value := values[string(metric)]
+
+		if run.UserID.Valid {
+			previous, beaten, err := s.beatsPreviousBest(ctx, run, metric, value, run.UserID)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			if beaten {
+				personalBests = append(personalBests, string(metric))
+				announcements = append(announcements, recordAnnouncement{EventPersonalBest, RecordEventDao{
+					Metric:   string(metric),
+					Value:    value,
+					Previous: previous,
+				}})
+			}
+		}
+
+		previous, beaten, err := s.beatsPreviousBest(ctx, run, metric, value, pgtype.Text{})
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if beaten {
+			records = append(records, string(metric))
+			announcements = append(announcements, recordAnnouncement{EventRecordBroken, RecordEventDao{
+				Metric:   string(metric),
+				Value:    value,
+				Previous: previous,
+			}})
+		}
+	}
+
+	return personalBests, records, announcements, nil
+}
+
+// redetectRecords updates the records of the runs recorded after a run that
+// no longer counts. Only a run that set a record or personal best raised the
+// bar for the runs after it, so only those runs are checked again: every
+// user's for a record, the run's own user's for a personal best. The new
+// flags are not announced, the runs are long past. It returns the users
+// whose runs changed.
+func (s *Server) redetectRecords(ctx context.Context, removed database.Run) []pgtype.Text {
+	var userID pgtype.Text
+	switch {
+	case len(removed.Records) > 0:
+	case len(removed.PersonalBests) > 0:
+		userID = removed.UserID
+	default:
+		return nil
+	}
+
+	runs, err := s.db.Queries().GetRunsRecordedSince(ctx, database.GetRunsRecordedSinceParams{
+		Since:     removed.CreatedAt,
+		ExcludeID: removed.ID,
+		UserID:    userID,
+	})
+	if err != nil {
+		log.Printf("Error getting runs to re-detect records: %v", err)
+		return nil
+	}
+
+	var changed []pgtype.Text
+	for _, run := range runs {
+		personalBests, records, _, err := s.findRecords(ctx, run)
+		if err != nil {
+			log.Printf("Error detecting records of run %s: %v", run.ID.String(), err)
+			continue
+		}
+		if slices.Equal(personalBests, run.PersonalBests) && slices.Equal(records, run.Records) {
+			continue
+		}
+
+		if err := s.db.Queries().UpdateRunRecords(ctx, database.UpdateRunRecordsParams{
+			ID:            run.ID,
+			PersonalBests: personalBests,
+			Records:       records,
+		}); err != nil {
+			log.Printf("Error saving run records: %v", err)
+			continue
+		}
+		if !slices.Contains(changed, run.UserID) {
+			changed = append(changed, run.UserID)
+		}
+	}
+
+	return changed
+}
+
+// beatsPreviousBest looks up the best run before this one, either for one
+// user or across everyone. A run with nothing to compare against sets no
+// record, so a user's first run is not a personal best and the first run
+// overall is not an all-time record.
+func (s *Server) beatsPreviousBest(ctx context.Context, run database.Run, metric database.LeaderboardMetric, value float64, userID pgtype.Text) (*PreviousBestDao, bool, error) {
+	best, err := s.db.Queries().GetPreviousBest(ctx, database.GetPreviousBestParams{
+		Metric:    metric,
+		UserID:    userID,
+		Before:    run.CreatedAt,
+		ExcludeID: run.ID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if !metric.Better(value, best.Value) {
+		return nil, false, nil
+	}
+
+	previous := &PreviousBestDao{
+		RunID: best.ID.String(),
+		Value: best.Value,
+	}
+	if best.UserName.Valid {
+		previous.User = &UserInfo{
+			ID:       best.UserIDFull.String,
+			Name:     best.UserName.String,
+			Username: best.UserUsername.String,
+		}
+	}
+	return previous, true, nil
+}
@@ -0,0 +1,38 @@
+package server
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const trashPurgeInterval = time.Hour
+
+// runTrashRetention permanently removes runs that have been in the trash
+// longer than the configured retention period.
+func (s *Server) runTrashRetention() {
+	ticker := time.NewTicker(trashPurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		s.purgeTrashedRuns()
+		<-ticker.C
+	}
+}
+
+func (s *Server) purgeTrashedRuns() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	cutoff := pgtype.Timestamp{Time: time.Now().Add(-s.trashRetention), Valid: true}
+	purged, err := s.db.Queries().PurgeDeletedRuns(ctx, cutoff)
+	if err != nil {
+		log.Printf("Error purging trashed runs: %v", err)
+		return
+	}
+	if purged > 0 {
+		log.Printf("Purged %d trashed runs older than %s", purged, s.trashRetention)
+	}
+}
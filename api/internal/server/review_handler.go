@@ -0,0 +1,406 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/tt-trichter/app/api/internal/database"
+	"github.com/tt-trichter/app/api/internal/plausibility"
+)
+
+const (
+	reviewStatusPending  = "pending"
+	reviewStatusApproved = "approved"
+	reviewStatusRejected = "rejected"
+)
+
+const defaultReportThreshold = 3
+
+type PendingRunDao struct {
+	RunDao
+	AnomalyScore  float64  `json:"anomalyScore"`
+	ReviewReasons []string `json:"reviewReasons"`
+	// ReportCount only counts reports since the run was last reviewed, the
+	// same ones that count towards holding it.
+	ReportCount int64 `json:"reportCount"`
+}
+
+type RunReviewDco struct {
+	RunIDs []string `json:"runIds" binding:"required,min=1,max=200"`
+	Status string   `json:"status" binding:"required,oneof=approved rejected"`
+	Reason string   `json:"reason" binding:"max=500"`
+}
+
+type RunReviewDao struct {
+	// Reviewed are the runs that were decided, Skipped the ones that were
+	// not pending review (anymore) or do not exist.
+	Reviewed []string `json:"reviewed"`
+	Skipped  []string `json:"skipped"`
+}
+
+type RunReportDco struct {
+	UserID string `json:"userId" binding:"required"`
+	Reason string `json:"reason" binding:"max=500"`
+}
+
+type RunReportDao struct {
+	ID        string    `json:"id"`
+	RunID     string    `json:"runId"`
+	UserID    string    `json:"userId"`
+	Reason    *string   `json:"reason"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// checkPlausibility compares a new run with the funnel limits and the
+// approved runs recorded so far.
+func (s *Server) checkPlausibility(ctx context.Context, runDco RunDco) (plausibility.Result, error) {
+	stats, err := s.db.Queries().GetRunHistoryStats(ctx)
+	if err != nil {
+		return plausibility.Result{}, err
+	}
+
+	return s.plausibility.Check(plausibility.Figures{
+		Duration: float64(runDco.Duration),
+		Rate:     float64(runDco.Rate),
+		Volume:   float64(runDco.Volume),
+	}, runDco.DeviceID, plausibility.History{
+		Count:          stats.RunCount,
+		MeanRate:       stats.MeanRate,
+		StddevRate:     stats.StddevRate,
+		MeanDuration:   stats.MeanDuration,
+		StddevDuration: stats.StddevDuration,
+		MeanVolume:     stats.MeanVolume,
+		StddevVolume:   stats.StddevVolume,
+	}), nil
+}
+
+// announceRun publishes a run that just became visible and updates the
+// records and achievements that depend on it.
+func (s *Server) announceRun(ctx context.Context, run database.Run) {
+	s.events.Publish(EventRunCreated, s.runDaoWithUser(ctx, run))
+	s.detectRecords(ctx, run)
+	s.awardAchievements(ctx, run.UserID, true)
+}
+
+// withdrawRun undoes what a rejected run earned while it was approved: the
+// records and personal bests it kept later runs from setting, and the badges
+// that depended on it.
+func (s *Server) withdrawRun(ctx context.Context, run database.Run) {
+	userIDs := s.redetectRecords(ctx, run)
+	if !slices.Contains(userIDs, run.UserID) {
+		userIDs = append(userIDs, run.UserID)
+	}
+	for _, userID := range userIDs {
+		s.reevaluateAchievements(ctx, userID)
+	}
+}
+
+// getPendingRunsHandler lists runs held for review, the most anomalous
+// first.
+func (s *Server) getPendingRunsHandler(c *gin.Context) {
+	limit := defaultPageLimit
+	if limitStr := c.Query("limit"); limitStr != "" {
+		value, err := strconv.Atoi(limitStr)
+		if err != nil || value < 1 || value > maxPageLimit {
+			c.JSON(http.StatusBadRequest, APIResponse{
+				Success: false,
+				Error:   "Invalid query parameters",
+				Details: "limit must be between 1 and 200",
+			})
+			return
+		}
+		limit = value
+	}
+
+	runs, err := s.db.Queries().GetRunsPendingReview(c.Request.Context(), int32(limit))
+	if err != nil {
+		log.Printf("Error getting runs pending review: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to fetch pending runs",
+		})
+		return
+	}
+
+	response := make([]PendingRunDao, 0, len(runs))
+	for _, run := range runs {
+		pending := PendingRunDao{
+			RunDao: RunDao{
+				ID:        run.ID.String(),
+				Image:     s.imageURL(run.Image),
+				CreatedAt: run.CreatedAt.Time,
+			},
+			AnomalyScore:  run.AnomalyScore,
+			ReviewReasons: run.ReviewReasons,
+			ReportCount:   run.ReportCount,
+		}
+		if err := json.Unmarshal(run.Data, &pending.Data); err != nil {
+			log.Printf("Error unmarshaling run data: %v", err)
+			continue
+		}
+		if run.UserName.Valid {
+			pending.User = &UserInfo{
+				ID:       run.UserIDFull.String,
+				Name:     run.UserName.String,
+				Username: run.UserUsername.String,
+			}
+		}
+		response = append(response, pending)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// reviewRunsHandler approves or rejects a batch of pending runs. The
+// decision is recorded with the admin who made it. Approved runs reach the
+// leaderboards and are awarded records and achievements now, but are not
+// added to a duel afterwards. Rejected runs lose what they earned while they
+// were approved.
+func (s *Server) reviewRunsHandler(c *gin.Context) {
+	var reviewDco RunReviewDco
+	if err := c.ShouldBindJSON(&reviewDco); err != nil {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Validation failed",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	runIDs := make([]pgtype.UUID, len(reviewDco.RunIDs))
+	for i, id := range reviewDco.RunIDs {
+		if err := runIDs[i].Scan(id); err != nil {
+			c.JSON(http.StatusBadRequest, APIResponse{
+				Success: false,
+				Error:   "Invalid run ID format",
+				Details: id,
+			})
+			return
+		}
+	}
+
+	var reason pgtype.Text
+	if reviewDco.Reason != "" {
+		reason = pgtype.Text{String: reviewDco.Reason, Valid: true}
+	}
+	reviewer := c.GetString(gin.AuthUserKey)
+
+	ctx := c.Request.Context()
+	tx, err := s.db.Pool().Begin(ctx)
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to review runs",
+		})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	queries := s.db.Queries().WithTx(tx)
+
+	response := RunReviewDao{Reviewed: []string{}, Skipped: []string{}}
+	var reviewed []database.Run
+	for _, id := range runIDs {
+		run, err := queries.ReviewRun(ctx, database.ReviewRunParams{
+			Status: reviewDco.Status,
+			ID:     id,
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			response.Skipped = append(response.Skipped, id.String())
+			continue
+		}
+		if err == nil {
+			err = queries.CreateRunReview(ctx, database.CreateRunReviewParams{
+				RunID:    id,
+				Status:   reviewDco.Status,
+				Reviewer: reviewer,
+				Reason:   reason,
+			})
+		}
+		if err != nil {
+			log.Printf("Error reviewing run %s: %v", id.String(), err)
+			c.JSON(http.StatusInternalServerError, APIResponse{
+				Success: false,
+				Error:   "Failed to review runs",
+			})
+			return
+		}
+		reviewed = append(reviewed, run)
+		response.Reviewed = append(response.Reviewed, id.String())
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("Error reviewing runs: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to review runs",
+		})
+		return
+	}
+
+	log.Printf("%s %s %d runs", reviewer, reviewDco.Status, len(reviewed))
+
+	for _, run := range reviewed {
+		if reviewDco.Status == reviewStatusApproved {
+			s.announceRun(ctx, run)
+		} else {
+			s.withdrawRun(ctx, run)
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// reportRunHandler lets a user report a run they believe is not genuine.
+// Each user can report a run once. Once reportThreshold users reported an
+// approved run since its last review, it is taken off the leaderboards and
+// held for review again.
+func (s *Server) reportRunHandler(c *gin.Context) {
+	var reportDco RunReportDco
+	if err := c.ShouldBindJSON(&reportDco); err != nil {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Validation failed",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	run, ok := s.lookupRun(c)
+	if !ok {
+		return
+	}
+	if !s.requireUser(c, reportDco.UserID) {
+		return
+	}
+
+	var reason pgtype.Text
+	if reportDco.Reason != "" {
+		reason = pgtype.Text{String: reportDco.Reason, Valid: true}
+	}
+
+	ctx := c.Request.Context()
+	tx, err := s.db.Pool().Begin(ctx)
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to report run",
+		})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	queries := s.db.Queries().WithTx(tx)
+
+	// Lock the run so concurrent reports cannot hold it twice.
+	run, err = queries.GetRunForUpdate(ctx, run.ID)
+	var report database.RunReport
+	if err == nil {
+		report, err = queries.CreateRunReport(ctx, database.CreateRunReportParams{
+			RunID:  run.ID,
+			UserID: reportDco.UserID,
+			Reason: reason,
+		})
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		c.JSON(http.StatusConflict, APIResponse{
+			Success: false,
+			Error:   "User already reported this run",
+		})
+		return
+	}
+	var reports int64
+	if err == nil {
+		reports, err = queries.CountOpenRunReports(ctx, run.ID)
+	}
+	held := false
+	if err == nil && run.ReviewStatus == reviewStatusApproved && reports >= int64(s.reportThreshold) {
+		_, err = queries.HoldRunForReview(ctx, database.HoldRunForReviewParams{
+			Reason: fmt.Sprintf("reported by %d users", reports),
+			ID:     run.ID,
+		})
+		held = true
+	}
+	if err == nil {
+		err = tx.Commit(ctx)
+	}
+	if err != nil {
+		log.Printf("Error reporting run: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to report run",
+		})
+		return
+	}
+
+	if held {
+		log.Printf("Run %s held for review after %d reports", run.ID.String(), reports)
+		s.events.Publish(EventRunHeld, gin.H{"id": run.ID.String()})
+	}
+
+	response := RunReportDao{
+		ID:        report.ID.String(),
+		RunID:     report.RunID.String(),
+		UserID:    report.UserID,
+		CreatedAt: report.CreatedAt.Time,
+	}
+	if report.Reason.Valid {
+		response.Reason = &report.Reason.String
+	}
+	c.JSON(http.StatusCreated, response)
+}
+
+type RunReviewEntryDao struct {
+	Status    string    `json:"status"`
+	Reviewer  string    `json:"reviewer"`
+	Reason    *string   `json:"reason"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// getRunReviewsHandler returns the moderation decisions taken on a run,
+// newest first.
+func (s *Server) getRunReviewsHandler(c *gin.Context) {
+	run, ok := s.lookupRun(c)
+	if !ok {
+		return
+	}
+
+	reviews, err := s.db.Queries().GetRunReviews(c.Request.Context(), run.ID)
+	if err != nil {
+		log.Printf("Error getting run reviews: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to fetch run reviews",
+		})
+		return
+	}
+
+	response := make([]RunReviewEntryDao, 0, len(reviews))
+	for _, review := range reviews {
+		entry := RunReviewEntryDao{
+			Status:    review.Status,
+			Reviewer:  review.Reviewer,
+			CreatedAt: review.CreatedAt.Time,
+		}
+		if review.Reason.Valid {
+			entry.Reason = &review.Reason.String
+		}
+		response = append(response, entry)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -14,26 +14,143 @@ func (s *Server) RegisterRoutes() http.Handler {
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"https://trichter.hauptspeicher.com"},
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"},
-		AllowHeaders:     []string{"Accept", "Authorization", "Content-Type"},
+		AllowHeaders:     []string{"Accept", "Authorization", "Content-Type", "Idempotency-Key"},
 		AllowCredentials: true,
 	}))
 
 	r.GET("/health", s.healthHandler)
 
-	v2 := r.Group("/api/v2")
+	v2 := r.Group("/api/v2", s.trackDeviceClock())
 	{
 		runs := v2.Group("/runs")
 		{
 			runs.GET("", s.getRunsWithUsersHandler)
-			runs.POST("", s.createRunHandler)
+			runs.POST("", s.idempotent(), s.createRunHandler)
+			runs.POST("/batch", s.createRunBatchHandler)
 			runs.POST("/sse", s.runsSSEHandler)
 			runs.PUT("/:id/user", s.updateRunUserHandler)
-			runs.DELETE("/:id", s.deleteRunHandler)
+			runs.DELETE("/:id", requireBasicAuth(), s.deleteRunHandler)
+			runs.GET("/:id", s.getRunHandler)
+			runs.PATCH("/:id", requireBasicAuth(), s.correctRunHandler)
+			runs.GET("/:id/history", s.getRunHistoryHandler)
+			runs.GET("/:id/samples", s.getRunSamplesHandler)
+			runs.POST("/:id/samples", requireBasicAuth(), s.uploadRunSamplesHandler)
+			runs.POST("/:id/restore", requireBasicAuth(), s.restoreRunHandler)
+			runs.POST("/:id/reports", requireBasicAuth(), s.reportRunHandler)
 		}
 
-		v2.POST("/images", s.uploadImageHandler)
+		v2.GET("/leaderboards/:window", s.getLeaderboardHandler)
+		v2.GET("/leaderboards/:window/teams", s.getTeamLeaderboardHandler)
 
-		v2.GET("/users/search", s.searchUsersHandler)
+		divisions := v2.Group("/divisions")
+		{
+			divisions.GET("", s.listDivisionsHandler)
+			divisions.POST("", requireBasicAuth(), s.createDivisionHandler)
+			divisions.PUT("/:id", requireBasicAuth(), s.updateDivisionHandler)
+			divisions.DELETE("/:id", requireBasicAuth(), s.deleteDivisionHandler)
+		}
+
+		scoringFormulas := v2.Group("/scoring-formulas")
+		{
+			scoringFormulas.GET("", s.listScoringFormulasHandler)
+			scoringFormulas.POST("", requireBasicAuth(), s.createScoringFormulaHandler)
+			scoringFormulas.PUT("/:name", requireBasicAuth(), s.updateScoringFormulaHandler)
+			scoringFormulas.DELETE("/:name", requireBasicAuth(), s.deleteScoringFormulaHandler)
+		}
+
+		events := v2.Group("/events")
+		{
+			events.GET("", s.listEventsHandler)
+			events.POST("", requireBasicAuth(), s.createEventHandler)
+			events.GET("/:id", s.getEventHandler)
+			events.PUT("/:id", requireBasicAuth(), s.updateEventHandler)
+			events.DELETE("/:id", requireBasicAuth(), s.deleteEventHandler)
+			events.GET("/:id/leaderboard", s.getEventLeaderboardHandler)
+		}
+
+		duels := v2.Group("/duels")
+		{
+			duels.GET("", s.listDuelsHandler)
+			duels.POST("", requireBasicAuth(), s.createDuelHandler)
+			duels.GET("/:id", s.getDuelHandler)
+			duels.DELETE("/:id", requireBasicAuth(), s.cancelDuelHandler)
+		}
+
+		tournaments := v2.Group("/tournaments")
+		{
+			tournaments.GET("", s.listTournamentsHandler)
+			tournaments.POST("", requireBasicAuth(), s.createTournamentHandler)
+			tournaments.GET("/:id", s.getTournamentHandler)
+			tournaments.POST("/:id/participants", requireBasicAuth(), s.addTournamentParticipantHandler)
+			tournaments.DELETE("/:id/participants/:userId", requireBasicAuth(), s.removeTournamentParticipantHandler)
+			tournaments.POST("/:id/start", requireBasicAuth(), s.startTournamentHandler)
+			tournaments.GET("/:id/bracket", s.getTournamentBracketHandler)
+			tournaments.POST("/:id/matches/:matchId/result", requireBasicAuth(), s.recordMatchResultHandler)
+		}
+
+		v2.GET("/ratings", s.getRatingsHandler)
+
+		teams := v2.Group("/teams")
+		{
+			teams.GET("", s.listTeamsHandler)
+			teams.POST("", requireBasicAuth(), s.createTeamHandler)
+			teams.GET("/:id", s.getTeamHandler)
+			teams.PUT("/:id", requireBasicAuth(), s.updateTeamHandler)
+			teams.DELETE("/:id", requireBasicAuth(), s.deleteTeamHandler)
+			teams.PUT("/:id/members/:userId", requireBasicAuth(), s.updateTeamMemberHandler)
+			teams.DELETE("/:id/members/:userId", requireBasicAuth(), s.removeTeamMemberHandler)
+			teams.GET("/:id/invitations", s.listTeamInvitationsHandler)
+			teams.POST("/:id/invitations", requireBasicAuth(), s.createTeamInvitationHandler)
+			teams.POST("/:id/invitations/:invitationId/accept", requireBasicAuth(), s.acceptTeamInvitationHandler)
+			teams.POST("/:id/invitations/:invitationId/decline", requireBasicAuth(), s.declineTeamInvitationHandler)
+			teams.DELETE("/:id/invitations/:invitationId", requireBasicAuth(), s.revokeTeamInvitationHandler)
+		}
+
+		seasons := v2.Group("/seasons")
+		{
+			seasons.GET("", s.listSeasonsHandler)
+			seasons.POST("", requireBasicAuth(), s.createSeasonHandler)
+			seasons.GET("/current/standings", s.getCurrentSeasonStandingsHandler)
+			seasons.PUT("/:id", requireBasicAuth(), s.updateSeasonHandler)
+			seasons.POST("/:id/close", requireBasicAuth(), s.closeSeasonHandler)
+			seasons.GET("/:id/standings", s.getSeasonStandingsHandler)
+		}
+
+		v2.POST("/images", s.idempotent(), s.uploadImageHandler)
+
+		users := v2.Group("/users")
+		{
+			users.GET("/search", s.searchUsersHandler)
+			users.GET("/:id", s.getUserHandler)
+			users.GET("/:id/stats", s.getUserStatsHandler)
+			users.GET("/:id/achievements", s.getUserAchievementsHandler)
+			users.GET("/:id/duels", s.getUserDuelsHandler)
+			users.GET("/:id/rating", s.getUserRatingHandler)
+			users.GET("/:id/teams", s.getUserTeamsHandler)
+			users.GET("/:id/team-invitations", s.getUserTeamInvitationsHandler)
+		}
+
+		devices := v2.Group("/devices")
+		{
+			devices.GET("", s.getDevicesHandler)
+			devices.POST("", requireBasicAuth(), s.registerDeviceHandler)
+			devices.GET("/:id", s.getDeviceHandler)
+			devices.POST("/:id/heartbeat", s.heartbeatHandler)
+			devices.GET("/:id/config", s.getDeviceConfigHandler)
+			devices.POST("/:id/config/ack", s.acknowledgeDeviceConfigHandler)
+		}
+
+		admin := v2.Group("/admin", requireBasicAuth())
+		{
+			admin.GET("/runs/trash", s.getTrashedRunsHandler)
+			admin.GET("/runs/flagged", s.getFlaggedRunsHandler)
+			admin.GET("/runs/pending", s.getPendingRunsHandler)
+			admin.POST("/runs/review", s.reviewRunsHandler)
+			admin.GET("/runs/:id/reviews", s.getRunReviewsHandler)
+			admin.GET("/devices/:id/config", s.getDeviceConfigStatusHandler)
+			admin.PUT("/devices/:id/config", s.updateDeviceConfigHandler)
+			admin.GET("/devices/:id/config/history", s.getDeviceConfigHistoryHandler)
+		}
 	}
 
 	r.GET("/metrics", s.metricsHandler)
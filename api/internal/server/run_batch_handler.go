@@ -0,0 +1,279 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/tt-trichter/app/api/internal/database"
+	"github.com/tt-trichter/app/api/internal/samples"
+)
+
+const (
+	batchRunCreated   = "created"
+	batchRunPending   = "pending"
+	batchRunDuplicate = "duplicate"
+	batchRunInvalid   = "invalid"
+)
+
+const (
+	// maxFutureRecordedAt is how far ahead of the server clock a corrected
+	// device timestamp may be.
+	maxFutureRecordedAt = 5 * time.Minute
+	// maxRunBatchBytes bounds batch uploads, which may carry flow curves.
+	maxRunBatchBytes = 32 << 20
+)
+
+type RunBatchDco struct {
+	// Runs are decoded one by one so a malformed run only fails itself.
+	Runs []json.RawMessage `json:"runs" binding:"required,min=1,max=500"`
+}
+
+// RunBatchItemDco is a run buffered on a device. ClientID is generated by
+// the device and identifies the run across retries, RecordedAt is when the
+// device recorded it and BootID the boot of the device it was recorded in.
+type RunBatchItemDco struct {
+	RunDco
+	ClientID   string    `json:"clientId" binding:"required,max=255"`
+	RecordedAt time.Time `json:"recordedAt" binding:"required"`
+	BootID     string    `json:"bootId" binding:"max=255"`
+}
+
+type RunBatchResultDao struct {
+	ClientID string `json:"clientId"`
+	Status   string `json:"status"`
+	RunID    string `json:"runId,omitempty"`
+	Error    string `json:"error,omitempty"`
+	Details  any    `json:"details,omitempty"`
+}
+
+type RunBatchDao struct {
+	Results []RunBatchResultDao `json:"results"`
+}
+
+type batchRun struct {
+	item   RunBatchItemDco
+	params database.SaveRunParams
+	result *RunBatchResultDao
+	saved  database.Run
+}
+
+// createRunBatchHandler stores runs a device buffered while it was offline.
+// All runs are inserted in one transaction and keep their recorded time,
+// corrected by the device's clock offset.
+// Runs whose client ID is already known are reported as duplicates with the
+// ID of the stored run, so a device can safely resend a batch. Every run
+// gets its own result; invalid runs do not keep the others from being
+// stored. Buffered runs are not added to duels, which only pair live runs.
+func (s *Server) createRunBatchHandler(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxRunBatchBytes)
+
+	var batchDco RunBatchDco
+	if err := c.ShouldBindJSON(&batchDco); err != nil {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Validation failed",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	ctx := c.Request.Context()
+	response := RunBatchDao{Results: make([]RunBatchResultDao, len(batchDco.Runs))}
+	var runs []*batchRun
+	firstByClientID := map[string]*RunBatchResultDao{}
+	clocks := s.newDeviceClocks()
+	for i, raw := range batchDco.Runs {
+		result := &response.Results[i]
+
+		var item RunBatchItemDco
+		err := json.Unmarshal(raw, &item)
+		result.ClientID = item.ClientID
+		if err == nil {
+			err = binding.Validator.ValidateStruct(&item)
+		}
+		if err == nil && item.Samples != nil {
+			err = samples.Validate(item.Samples)
+		}
+		if err != nil {
+			result.Status = batchRunInvalid
+			result.Error = "Validation failed"
+			result.Details = err.Error()
+			continue
+		}
+		if _, seen := firstByClientID[item.ClientID]; seen {
+			result.Status = batchRunDuplicate
+			continue
+		}
+
+		check, err := s.checkPlausibility(ctx, item.RunDco)
+		if err != nil {
+			log.Printf("Error checking run plausibility: %v", err)
+			c.JSON(http.StatusInternalServerError, APIResponse{
+				Success: false,
+				Error:   "Internal server error",
+			})
+			return
+		}
+		if len(check.Impossible) > 0 {
+			result.Status = batchRunInvalid
+			result.Error = "Implausible run"
+			result.Details = check.Impossible
+			continue
+		}
+
+		// A run whose time cannot be trusted is stored at the time it
+		// arrived and held for review.
+		recordedAt, trusted := clocks.correct(ctx, item.DeviceID, item.BootID, item.RecordedAt, s.maxRunBackdate)
+		if !trusted {
+			recordedAt = time.Time{}
+			check.Suspicious = append(check.Suspicious, fmt.Sprintf("untrusted device timestamp %s", item.RecordedAt.Format(time.RFC3339)))
+		}
+
+		params, err := s.runParams(ctx, item.RunDco, recordedAt, check)
+		if err != nil {
+			log.Printf("Error marshaling run data: %v", err)
+			c.JSON(http.StatusInternalServerError, APIResponse{
+				Success: false,
+				Error:   "Internal server error",
+			})
+			return
+		}
+		firstByClientID[item.ClientID] = result
+		runs = append(runs, &batchRun{item: item, params: params, result: result})
+	}
+
+	tx, err := s.db.Pool().Begin(ctx)
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to save runs",
+		})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	queries := s.db.Queries().WithTx(tx)
+
+	var created []*batchRun
+	for _, run := range runs {
+		duplicate, err := saveBatchRun(ctx, tx, queries, run)
+		if err != nil {
+			log.Printf("Error saving buffered run %s: %v", run.item.ClientID, err)
+			c.JSON(http.StatusInternalServerError, APIResponse{
+				Success: false,
+				Error:   "Failed to save runs",
+			})
+			return
+		}
+		if !duplicate {
+			created = append(created, run)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("Error saving buffered runs: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to save runs",
+		})
+		return
+	}
+
+	// Repeated client IDs within the batch point to the run stored for the
+	// first occurrence.
+	for i := range response.Results {
+		result := &response.Results[i]
+		if first := firstByClientID[result.ClientID]; result.Status == batchRunDuplicate && first != nil && result != first {
+			result.RunID = first.RunID
+		}
+	}
+
+	log.Printf("Stored %d of %d buffered runs", len(created), len(batchDco.Runs))
+
+	for _, run := range created {
+		saved := run.saved
+		if run.item.Samples != nil {
+			saved, _ = s.verifyRun(ctx, saved)
+		}
+		s.scoreRun(ctx, saved)
+		if saved.ReviewStatus != reviewStatusApproved {
+			run.result.Status = batchRunPending
+			run.result.Details = saved.ReviewReasons
+			continue
+		}
+		s.announceRun(ctx, saved)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// saveBatchRun stores one buffered run inside the batch transaction. It
+// uses a savepoint so a client ID that another request stored concurrently
+// only skips this run, and reports whether the run was a duplicate.
+func saveBatchRun(ctx context.Context, tx pgx.Tx, queries *database.Queries, run *batchRun) (bool, error) {
+	existing, err := queries.GetRunIdByClientId(ctx, run.item.ClientID)
+	if err == nil {
+		run.result.Status = batchRunDuplicate
+		run.result.RunID = existing.String()
+		return true, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return false, err
+	}
+
+	savepoint, err := tx.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer savepoint.Rollback(ctx)
+
+	savepointQueries := queries.WithTx(savepoint)
+	saved, err := savepointQueries.SaveRun(ctx, run.params)
+	if err == nil && run.item.Samples != nil {
+		_, err = savepointQueries.SaveRunSamples(ctx, database.SaveRunSamplesParams{
+			RunID:       saved.ID,
+			SampleCount: int32(len(run.item.Samples)),
+			Data:        samples.Encode(run.item.Samples),
+		})
+	}
+	if err == nil {
+		err = savepointQueries.SaveRunClientId(ctx, database.SaveRunClientIdParams{
+			ClientID: run.item.ClientID,
+			RunID:    saved.ID,
+		})
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		if err := savepoint.Rollback(ctx); err != nil {
+			return false, err
+		}
+		existing, err := queries.GetRunIdByClientId(ctx, run.item.ClientID)
+		if err != nil {
+			return false, err
+		}
+		run.result.Status = batchRunDuplicate
+		run.result.RunID = existing.String()
+		return true, nil
+	}
+	if err == nil {
+		err = savepoint.Commit(ctx)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	run.saved = saved
+	run.result.Status = batchRunCreated
+	run.result.RunID = saved.ID.String()
+	return false, nil
+}
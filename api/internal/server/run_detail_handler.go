@@ -0,0 +1,107 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ImageVariants lists the URLs under which a run's photo is served. Only the
+// original upload is stored today.
+type ImageVariants struct {
+	Original string `json:"original"`
+}
+
+type RunDetailDao struct {
+	RunDao
+	Images   ImageVariants `json:"images"`
+	Rank     int64         `json:"rank"`
+	UserRank *int64        `json:"userRank"`
+	// PersonalBests and Records name the metrics the run was a personal best
+	// or all-time record in when it was recorded. Later deletions or reviews
+	// do not change them.
+	PersonalBests []string `json:"personalBests"`
+	Records       []string `json:"records"`
+}
+
+func (s *Server) getRunHandler(c *gin.Context) {
+	runID := c.Param("id")
+
+	var runUUID pgtype.UUID
+	if err := runUUID.Scan(runID); err != nil {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Invalid run ID format",
+		})
+		return
+	}
+
+	// Runs that are not approved are hidden like deleted ones, so neither
+	// they nor an ETag for them is served until a review approves them.
+	run, err := s.db.Queries().GetRunWithUserById(c.Request.Context(), runUUID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, APIResponse{
+			Success: false,
+			Error:   "Run not found",
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Error getting run: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to fetch run",
+		})
+		return
+	}
+
+	standings, err := s.db.Queries().GetRunStandings(c.Request.Context(), runUUID)
+	if err != nil {
+		log.Printf("Error getting run standings: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to fetch run",
+		})
+		return
+	}
+
+	response := RunDetailDao{
+		RunDao: RunDao{
+			ID:        run.ID.String(),
+			Image:     run.Image,
+			CreatedAt: run.CreatedAt.Time,
+		},
+		Images:        ImageVariants{Original: s.imageURL(run.Image)},
+		Rank:          standings.OverallRank,
+		PersonalBests: run.PersonalBests,
+		Records:       run.Records,
+	}
+
+	if err := json.Unmarshal(run.Data, &response.Data); err != nil {
+		log.Printf("Error unmarshaling run data: %v", err)
+	}
+
+	if run.UserName.Valid {
+		response.User = &UserInfo{
+			ID:       run.UserIDFull.String,
+			Name:     run.UserName.String,
+			Username: run.UserUsername.String,
+		}
+		response.UserRank = &standings.UserRank
+	}
+
+	jsonWithETag(c, http.StatusOK, response)
+}
+
+func (s *Server) imageURL(path string) string {
+	if s.imageBaseURL == "" {
+		return path
+	}
+	return strings.TrimSuffix(s.imageBaseURL, "/") + "/" + strings.TrimPrefix(path, "/")
+}
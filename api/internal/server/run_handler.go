@@ -1,14 +1,19 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/tt-trichter/app/api/internal/database"
+	"github.com/tt-trichter/app/api/internal/plausibility"
+	"github.com/tt-trichter/app/api/internal/samples"
 )
 
 type RunDco struct {
@@ -17,6 +22,10 @@ type RunDco struct {
 	Volume   float32 `json:"volume" binding:"required,gt=0"`
 	UserID   string  `json:"userId"`
 	Image    string  `json:"image"`
+	DeviceID string  `json:"deviceId"`
+	// Samples optionally carries the measured flow curve. It is stored
+	// separately and served by GET /runs/:id/samples.
+	Samples []samples.Sample `json:"samples,omitempty"`
 }
 
 type RunData struct {
@@ -33,8 +42,32 @@ type RunDao struct {
 	User      *UserInfo `json:"user"`
 }
 
+type RunPageDao struct {
+	Items      []RunDao `json:"items"`
+	NextCursor *string  `json:"nextCursor"`
+}
+
+type TrashedRunDao struct {
+	RunDao
+	DeletedAt time.Time `json:"deletedAt"`
+}
+
 func (s *Server) getRunsWithUsersHandler(c *gin.Context) {
-	runs, err := s.db.Queries().GetAllRunsWithUsers(c.Request.Context())
+	params, err := parseRunListParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Invalid query parameters",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	// Fetch one extra row to find out whether there is a next page.
+	limit := params.Limit
+	params.Limit++
+
+	runs, err := s.db.Queries().ListRunsWithUsers(c.Request.Context(), params)
 	if err != nil {
 		log.Printf("Error getting runs with users: %v", err)
 		c.JSON(http.StatusInternalServerError, APIResponse{
@@ -44,7 +77,23 @@ func (s *Server) getRunsWithUsersHandler(c *gin.Context) {
 		return
 	}
 
-	var response []RunDao
+	response := RunPageDao{Items: []RunDao{}}
+	if len(runs) > int(limit) {
+		runs = runs[:limit]
+		last := runs[len(runs)-1]
+		order := "desc"
+		if !params.Descending {
+			order = "asc"
+		}
+		nextCursor := encodeRunCursor(runCursor{
+			Sort:  params.Sort,
+			Order: order,
+			Value: last.SortValue,
+			ID:    last.ID.String(),
+		})
+		response.NextCursor = &nextCursor
+	}
+
 	for _, run := range runs {
 		runWithUser := RunDao{
 			ID:        run.ID.String(),
@@ -67,7 +116,7 @@ func (s *Server) getRunsWithUsersHandler(c *gin.Context) {
 			}
 		}
 
-		response = append(response, runWithUser)
+		response.Items = append(response.Items, runWithUser)
 	}
 
 	c.JSON(http.StatusOK, response)
@@ -84,36 +133,48 @@ func (s *Server) createRunHandler(c *gin.Context) {
 		return
 	}
 
-	if runDco.Image == "" {
-		runDco.Image = "trichter-images/placeholder.jpg"
+	if runDco.Samples != nil {
+		if err := samples.Validate(runDco.Samples); err != nil {
+			c.JSON(http.StatusBadRequest, APIResponse{
+				Success: false,
+				Error:   "Validation failed",
+				Details: err.Error(),
+			})
+			return
+		}
 	}
 
-	runData, err := json.Marshal(RunData{
-		Rate:     runDco.Rate,
-		Volume:   runDco.Volume,
-		Duration: runDco.Duration,
-	})
+	check, err := s.checkPlausibility(c.Request.Context(), runDco)
 	if err != nil {
-		log.Printf("Error marshaling run data: %v", err)
+		log.Printf("Error checking run plausibility: %v", err)
 		c.JSON(http.StatusInternalServerError, APIResponse{
 			Success: false,
 			Error:   "Internal server error",
 		})
 		return
 	}
+	if len(check.Impossible) > 0 {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Implausible run",
+			Details: check.Impossible,
+		})
+		return
+	}
 
-	var userId pgtype.Text
-	if runDco.UserID != "" {
-		userId = pgtype.Text{String: runDco.UserID, Valid: true}
+	params, err := s.runParams(c.Request.Context(), runDco, time.Time{}, check)
+	if err != nil {
+		log.Printf("Error marshaling run data: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Internal server error",
+		})
+		return
 	}
 
-	log.Printf("runData: %s", runData)
+	log.Printf("runData: %s", params.Data)
 	log.Printf("UserID: %s", runDco.UserID)
-	savedRun, err := s.db.Queries().SaveRun(c.Request.Context(), database.SaveRunParams{
-		UserID: userId,
-		Data:   runData,
-		Image:  runDco.Image,
-	})
+	savedRun, err := s.saveRun(c.Request.Context(), params, runDco.Samples)
 	if err != nil {
 		log.Printf("Error saving run: %v", err)
 		c.JSON(http.StatusInternalServerError, APIResponse{
@@ -125,11 +186,94 @@ func (s *Server) createRunHandler(c *gin.Context) {
 
 	log.Printf("Created new run: %s", savedRun.ID.String())
 
-	// TODO: Emit SSE event for RunCreated
+	if runDco.Samples != nil {
+		savedRun, _ = s.verifyRun(c.Request.Context(), savedRun)
+	}
+
+	s.scoreRun(c.Request.Context(), savedRun)
+
+	// Held runs stay off the leaderboards and out of duels until approved.
+	if savedRun.ReviewStatus != reviewStatusApproved {
+		log.Printf("Run %s held for review: %v", savedRun.ID.String(), savedRun.ReviewReasons)
+		c.JSON(http.StatusAccepted, APIResponse{
+			Success: true,
+			Details: savedRun.ReviewReasons,
+		})
+		return
+	}
+
+	s.recordDuelRun(c.Request.Context(), savedRun)
+	s.announceRun(c.Request.Context(), savedRun)
 
 	c.JSON(http.StatusOK, APIResponse{Success: true})
 }
 
+// runParams prepares a run that passed the plausibility check for SaveRun,
+// assigning it to the event and division it belongs to. A zero recordedAt
+// stores the run at the current time.
+func (s *Server) runParams(ctx context.Context, runDco RunDco, recordedAt time.Time, check plausibility.Result) (database.SaveRunParams, error) {
+	reviewStatus := reviewStatusApproved
+	if len(check.Suspicious) > 0 {
+		reviewStatus = reviewStatusPending
+	}
+
+	if runDco.Image == "" {
+		runDco.Image = "trichter-images/placeholder.jpg"
+	}
+
+	runData, err := json.Marshal(RunData{
+		Rate:     runDco.Rate,
+		Volume:   runDco.Volume,
+		Duration: runDco.Duration,
+	})
+	if err != nil {
+		return database.SaveRunParams{}, err
+	}
+
+	var userId pgtype.Text
+	if runDco.UserID != "" {
+		userId = pgtype.Text{String: runDco.UserID, Valid: true}
+	}
+
+	var deviceID pgtype.Text
+	if runDco.DeviceID != "" {
+		deviceID = pgtype.Text{String: runDco.DeviceID, Valid: true}
+	}
+
+	var createdAt pgtype.Timestamp
+	at := time.Now().UTC()
+	if !recordedAt.IsZero() {
+		at = recordedAt.UTC()
+		createdAt = pgtype.Timestamp{Time: at, Valid: true}
+	}
+
+	eventID, err := s.db.Queries().FindActiveEventId(ctx, database.FindActiveEventIdParams{
+		At:       pgtype.Timestamp{Time: at, Valid: true},
+		DeviceID: deviceID,
+	})
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		log.Printf("Error finding active event: %v", err)
+	}
+
+	divisionID, err := s.db.Queries().FindDivisionId(ctx, float64(runDco.Volume))
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		log.Printf("Error finding division: %v", err)
+	}
+
+	return database.SaveRunParams{
+		UserID:        userId,
+		Data:          runData,
+		Image:         runDco.Image,
+		DeviceID:      deviceID,
+		EventID:       eventID,
+		DivisionID:    divisionID,
+		ReviewStatus:  reviewStatus,
+		AnomalyScore:  check.AnomalyScore,
+		ReviewReasons: check.Suspicious,
+		CreatedAt:     createdAt,
+	}, nil
+}
+
 func (s *Server) updateRunUserHandler(c *gin.Context) {
 	runID := c.Param("id")
 
@@ -157,10 +301,17 @@ func (s *Server) updateRunUserHandler(c *gin.Context) {
 
 	userIDText := pgtype.Text{String: request.UserID, Valid: true}
 
-	_, err := s.db.Queries().UpdateRunWithUser(c.Request.Context(), database.UpdateRunWithUserParams{
+	run, err := s.db.Queries().UpdateRunWithUser(c.Request.Context(), database.UpdateRunWithUserParams{
 		ID:     runUUID,
 		UserID: userIDText,
 	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, APIResponse{
+			Success: false,
+			Error:   "Run not found",
+		})
+		return
+	}
 	if err != nil {
 		log.Printf("Error updating run user: %v", err)
 		c.JSON(http.StatusInternalServerError, APIResponse{
@@ -172,7 +323,9 @@ func (s *Server) updateRunUserHandler(c *gin.Context) {
 
 	log.Printf("Updated run %s with user %s", runID, request.UserID)
 
-	// TODO: Emit SSE event for RunUpdated
+	s.events.Publish(EventRunUpdated, s.runDaoWithUser(c.Request.Context(), run))
+	s.detectRecords(c.Request.Context(), run)
+	s.awardAchievements(c.Request.Context(), run.UserID, true)
 
 	c.JSON(http.StatusOK, APIResponse{Success: true})
 }
@@ -180,9 +333,6 @@ func (s *Server) updateRunUserHandler(c *gin.Context) {
 func (s *Server) deleteRunHandler(c *gin.Context) {
 	runID := c.Param("id")
 
-	// TODO: Implement proper auth check for admin role
-	// For now, we'll skip the auth check as it requires JWT token validation
-
 	var runUUID pgtype.UUID
 	if err := runUUID.Scan(runID); err != nil {
 		c.JSON(http.StatusBadRequest, APIResponse{
@@ -192,7 +342,7 @@ func (s *Server) deleteRunHandler(c *gin.Context) {
 		return
 	}
 
-	err := s.db.Queries().DeleteRun(c.Request.Context(), runUUID)
+	deleted, err := s.db.Queries().DeleteRun(c.Request.Context(), runUUID)
 	if err != nil {
 		log.Printf("Error deleting run: %v", err)
 		c.JSON(http.StatusInternalServerError, APIResponse{
@@ -201,22 +351,154 @@ func (s *Server) deleteRunHandler(c *gin.Context) {
 		})
 		return
 	}
+	if deleted == 0 {
+		c.JSON(http.StatusNotFound, APIResponse{
+			Success: false,
+			Error:   "Run not found",
+		})
+		return
+	}
 
-	log.Printf("Deleted run: %s", runID)
+	log.Printf("Moved run to trash: %s", runID)
 
-	// TODO: Emit SSE event for RunDeleted
+	s.events.Publish(EventRunDeleted, gin.H{"id": runID})
 
 	c.JSON(http.StatusOK, APIResponse{Success: true})
 }
 
-func (s *Server) runsSSEHandler(c *gin.Context) {
-	c.Header("Content-Type", "text/event-stream")
-	c.Header("Cache-Control", "no-cache")
-	c.Header("Connection", "keep-alive")
-	c.Header("Access-Control-Allow-Origin", "*")
+func (s *Server) restoreRunHandler(c *gin.Context) {
+	runID := c.Param("id")
 
-	// TODO: Implement proper SSE with event emitter
-	// For now, just keep the connection open
+	var runUUID pgtype.UUID
+	if err := runUUID.Scan(runID); err != nil {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Invalid run ID format",
+		})
+		return
+	}
 
-	<-c.Request.Context().Done()
+	run, err := s.db.Queries().RestoreRun(c.Request.Context(), runUUID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, APIResponse{
+			Success: false,
+			Error:   "Run not found in trash",
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Error restoring run: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to restore run",
+		})
+		return
+	}
+
+	log.Printf("Restored run: %s", runID)
+
+	s.events.Publish(EventRunRestored, s.runDaoWithUser(c.Request.Context(), run))
+	s.awardAchievements(c.Request.Context(), run.UserID, true)
+
+	c.JSON(http.StatusOK, APIResponse{Success: true})
+}
+
+// runDaoWithUser converts a stored run into its API representation,
+// looking up the assigned user if there is one.
+func (s *Server) runDaoWithUser(ctx context.Context, run database.Run) RunDao {
+	dao := RunDao{
+		ID:        run.ID.String(),
+		Image:     run.Image,
+		CreatedAt: run.CreatedAt.Time,
+	}
+	if err := json.Unmarshal(run.Data, &dao.Data); err != nil {
+		log.Printf("Error unmarshaling run data: %v", err)
+	}
+
+	if run.UserID.Valid {
+		user, err := s.db.Queries().GetUserById(ctx, run.UserID.String)
+		if err != nil {
+			log.Printf("Error getting user %s: %v", run.UserID.String, err)
+		} else {
+			dao.User = &UserInfo{
+				ID:       user.ID,
+				Name:     user.Name,
+				Username: user.Username,
+			}
+		}
+	}
+
+	return dao
+}
+
+func (s *Server) getTrashedRunsHandler(c *gin.Context) {
+	runs, err := s.db.Queries().GetDeletedRunsWithUsers(c.Request.Context())
+	if err != nil {
+		log.Printf("Error getting trashed runs: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to fetch trashed runs",
+		})
+		return
+	}
+
+	response := []TrashedRunDao{}
+	for _, run := range runs {
+		trashed := TrashedRunDao{
+			RunDao: RunDao{
+				ID:        run.ID.String(),
+				Image:     run.Image,
+				CreatedAt: run.CreatedAt.Time,
+			},
+			DeletedAt: run.DeletedAt.Time,
+		}
+
+		if err := json.Unmarshal(run.Data, &trashed.Data); err != nil {
+			log.Printf("Error unmarshaling run data: %v", err)
+			continue
+		}
+
+		if run.UserName.Valid {
+			trashed.User = &UserInfo{
+				ID:       run.UserIDFull.String,
+				Name:     run.UserName.String,
+				Username: run.UserUsername.String,
+			}
+		}
+
+		response = append(response, trashed)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// lookupRun loads the non-deleted run named by the :id path parameter,
+// writing the error response itself when it cannot.
+func (s *Server) lookupRun(c *gin.Context) (database.Run, bool) {
+	var runUUID pgtype.UUID
+	if err := runUUID.Scan(c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Invalid run ID format",
+		})
+		return database.Run{}, false
+	}
+
+	run, err := s.db.Queries().GetRunById(c.Request.Context(), runUUID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, APIResponse{
+			Success: false,
+			Error:   "Run not found",
+		})
+		return database.Run{}, false
+	}
+	if err != nil {
+		log.Printf("Error getting run: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to fetch run",
+		})
+		return database.Run{}, false
+	}
+	return run, true
 }
@@ -0,0 +1,257 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/tt-trichter/app/api/internal/database"
+)
+
+type RunCorrectionDco struct {
+	Duration *float32 `json:"duration" binding:"omitempty,gt=0"`
+	Rate     *float32 `json:"rate" binding:"omitempty,gt=0"`
+	Volume   *float32 `json:"volume" binding:"omitempty,gt=0"`
+	Reason   string   `json:"reason" binding:"required"`
+}
+
+type RunRevisionDao struct {
+	ID        string    `json:"id"`
+	Data      RunData   `json:"data"`
+	Reason    string    `json:"reason"`
+	EditedBy  string    `json:"editedBy"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type RunHistoryDao struct {
+	Current   RunData          `json:"current"`
+	Revisions []RunRevisionDao `json:"revisions"`
+}
+
+func (s *Server) correctRunHandler(c *gin.Context) {
+	runID := c.Param("id")
+
+	var correction RunCorrectionDco
+	if err := c.ShouldBindJSON(&correction); err != nil {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Validation failed",
+			Details: err.Error(),
+		})
+		return
+	}
+	// The reason is the audit trail of the correction, so whitespace alone
+	// does not count.
+	correction.Reason = strings.TrimSpace(correction.Reason)
+	if correction.Reason == "" {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Validation failed",
+			Details: "reason must not be blank",
+		})
+		return
+	}
+	if correction.Duration == nil && correction.Rate == nil && correction.Volume == nil {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Validation failed",
+			Details: "at least one of duration, rate or volume is required",
+		})
+		return
+	}
+
+	var runUUID pgtype.UUID
+	if err := runUUID.Scan(runID); err != nil {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Invalid run ID format",
+		})
+		return
+	}
+
+	ctx := c.Request.Context()
+	tx, err := s.db.Pool().Begin(ctx)
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Internal server error",
+		})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	queries := s.db.Queries().WithTx(tx)
+
+	run, err := queries.GetRunForUpdate(ctx, runUUID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, APIResponse{
+			Success: false,
+			Error:   "Run not found",
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Error getting run: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to correct run",
+		})
+		return
+	}
+
+	var data RunData
+	if err := json.Unmarshal(run.Data, &data); err != nil {
+		log.Printf("Error unmarshaling run data: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Internal server error",
+		})
+		return
+	}
+
+	if correction.Duration != nil {
+		data.Duration = *correction.Duration
+	}
+	if correction.Rate != nil {
+		data.Rate = *correction.Rate
+	}
+	if correction.Volume != nil {
+		data.Volume = *correction.Volume
+	}
+
+	correctedData, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("Error marshaling run data: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Internal server error",
+		})
+		return
+	}
+
+	if _, err := queries.CreateRunRevision(ctx, database.CreateRunRevisionParams{
+		RunID:    runUUID,
+		Data:     run.Data,
+		Reason:   correction.Reason,
+		EditedBy: c.GetString(gin.AuthUserKey),
+	}); err != nil {
+		log.Printf("Error saving run revision: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to correct run",
+		})
+		return
+	}
+
+	corrected, err := queries.UpdateRunData(ctx, database.UpdateRunDataParams{
+		ID:   runUUID,
+		Data: correctedData,
+	})
+	if err != nil {
+		log.Printf("Error updating run data: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to correct run",
+		})
+		return
+	}
+
+	// A corrected volume may move the run into another division.
+	if _, err := queries.AssignRunsToDivisions(ctx, runUUID); err != nil {
+		log.Printf("Error reclassifying run: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to correct run",
+		})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("Error committing run correction: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to correct run",
+		})
+		return
+	}
+
+	log.Printf("Corrected run %s: %s", runID, correction.Reason)
+
+	s.events.Publish(EventRunUpdated, s.runDaoWithUser(ctx, corrected))
+	s.scoreRun(ctx, corrected)
+	// Corrected figures may set or lose personal bests and records.
+	if corrected.ReviewStatus == reviewStatusApproved {
+		s.detectRecords(ctx, corrected)
+	}
+	s.awardAchievements(ctx, corrected.UserID, true)
+
+	c.JSON(http.StatusOK, APIResponse{Success: true})
+}
+
+func (s *Server) getRunHistoryHandler(c *gin.Context) {
+	runID := c.Param("id")
+
+	var runUUID pgtype.UUID
+	if err := runUUID.Scan(runID); err != nil {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Invalid run ID format",
+		})
+		return
+	}
+
+	run, err := s.db.Queries().GetRunById(c.Request.Context(), runUUID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, APIResponse{
+			Success: false,
+			Error:   "Run not found",
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Error getting run: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to fetch run history",
+		})
+		return
+	}
+
+	revisions, err := s.db.Queries().GetRunRevisions(c.Request.Context(), runUUID)
+	if err != nil {
+		log.Printf("Error getting run revisions: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to fetch run history",
+		})
+		return
+	}
+
+	response := RunHistoryDao{Revisions: []RunRevisionDao{}}
+	if err := json.Unmarshal(run.Data, &response.Current); err != nil {
+		log.Printf("Error unmarshaling run data: %v", err)
+	}
+
+	for _, revision := range revisions {
+		revisionDao := RunRevisionDao{
+			ID:        revision.ID.String(),
+			Reason:    revision.Reason,
+			EditedBy:  revision.EditedBy,
+			CreatedAt: revision.CreatedAt.Time,
+		}
+		if err := json.Unmarshal(revision.Data, &revisionDao.Data); err != nil {
+			log.Printf("Error unmarshaling revision data: %v", err)
+			continue
+		}
+		response.Revisions = append(response.Revisions, revisionDao)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,211 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/tt-trichter/app/api/internal/database"
+	"github.com/tt-trichter/app/api/internal/samples"
+)
+
+// maxSampleUploadBytes bounds sample uploads; a full curve in CSV stays well
+// below it.
+const maxSampleUploadBytes = 2 << 20
+
+type RunSamplesDao struct {
+	RunID string `json:"runId"`
+	// Count is the number of stored samples, Samples may hold fewer when
+	// the curve was downsampled.
+	Count       int32            `json:"count"`
+	Downsampled bool             `json:"downsampled"`
+	Samples     []samples.Sample `json:"samples"`
+	// Verification compares the reported figures with the derived ones.
+	Verification *RunVerificationDao `json:"verification"`
+}
+
+// saveRun stores a run together with its optional flow curve, so a run is
+// never saved with half of what the device sent.
+func (s *Server) saveRun(ctx context.Context, params database.SaveRunParams, curve []samples.Sample) (database.Run, error) {
+	if curve == nil {
+		return s.db.Queries().SaveRun(ctx, params)
+	}
+
+	tx, err := s.db.Pool().Begin(ctx)
+	if err != nil {
+		return database.Run{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	queries := s.db.Queries().WithTx(tx)
+
+	run, err := queries.SaveRun(ctx, params)
+	if err != nil {
+		return database.Run{}, err
+	}
+	if _, err := queries.SaveRunSamples(ctx, database.SaveRunSamplesParams{
+		RunID:       run.ID,
+		SampleCount: int32(len(curve)),
+		Data:        samples.Encode(curve),
+	}); err != nil {
+		return database.Run{}, err
+	}
+
+	return run, tx.Commit(ctx)
+}
+
+// uploadRunSamplesHandler attaches a flow curve to a run that was created
+// without one. The body is CSV ("t,flow" lines), the binary format described
+// by samples.RecordSize, or a JSON array of samples. Curves cannot be
+// replaced once stored, so the route requires basic auth.
+func (s *Server) uploadRunSamplesHandler(c *gin.Context) {
+	run, ok := s.lookupRun(c)
+	if !ok {
+		return
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxSampleUploadBytes)
+
+	var curve []samples.Sample
+	var err error
+	switch mediaType {
+	case "text/csv":
+		curve, err = samples.ReadCSV(body)
+	case "application/octet-stream":
+		curve, err = samples.ReadBinary(body)
+	case "application/json":
+		err = json.NewDecoder(body).Decode(&curve)
+	default:
+		c.JSON(http.StatusUnsupportedMediaType, APIResponse{
+			Success: false,
+			Error:   "Unsupported media type",
+			Details: "use text/csv, application/octet-stream or application/json",
+		})
+		return
+	}
+	if err == nil {
+		err = samples.Validate(curve)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Invalid samples",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	saved, err := s.db.Queries().SaveRunSamples(c.Request.Context(), database.SaveRunSamplesParams{
+		RunID:       run.ID,
+		SampleCount: int32(len(curve)),
+		Data:        samples.Encode(curve),
+	})
+	if err != nil {
+		log.Printf("Error saving run samples: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to save samples",
+		})
+		return
+	}
+	if saved == 0 {
+		c.JSON(http.StatusConflict, APIResponse{
+			Success: false,
+			Error:   "Run already has samples",
+		})
+		return
+	}
+
+	log.Printf("Stored %d samples for run %s", len(curve), run.ID.String())
+
+	// The run was already scored and, unless held, published with its
+	// reported figures.
+	ctx := c.Request.Context()
+	verified, changed := s.verifyRun(ctx, run)
+	if changed {
+		s.scoreRun(ctx, verified)
+	}
+	switch {
+	case verified.ReviewStatus != reviewStatusApproved:
+		if run.ReviewStatus == reviewStatusApproved {
+			log.Printf("Run %s held for review after sample verification", run.ID.String())
+			s.events.Publish(EventRunHeld, gin.H{"id": run.ID.String()})
+		}
+	case changed:
+		s.events.Publish(EventRunUpdated, s.runDaoWithUser(ctx, verified))
+		s.detectRecords(ctx, verified)
+		s.awardAchievements(ctx, verified.UserID, true)
+	}
+
+	c.JSON(http.StatusCreated, APIResponse{Success: true})
+}
+
+// getRunSamplesHandler serves a run's flow curve. The points parameter
+// downsamples it for plotting.
+func (s *Server) getRunSamplesHandler(c *gin.Context) {
+	points := 0
+	if pointsStr := c.Query("points"); pointsStr != "" {
+		value, err := strconv.Atoi(pointsStr)
+		if err != nil || value < 3 || value > samples.MaxSamples {
+			c.JSON(http.StatusBadRequest, APIResponse{
+				Success: false,
+				Error:   "Invalid query parameters",
+				Details: "points must be between 3 and 50000",
+			})
+			return
+		}
+		points = value
+	}
+
+	run, ok := s.lookupRun(c)
+	if !ok {
+		return
+	}
+
+	stored, err := s.db.Queries().GetRunSamples(c.Request.Context(), run.ID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, APIResponse{
+			Success: false,
+			Error:   "Run has no samples",
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Error getting run samples: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to fetch samples",
+		})
+		return
+	}
+
+	curve, err := samples.Decode(stored.Data)
+	if err != nil {
+		log.Printf("Error decoding samples of run %s: %v", run.ID.String(), err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to fetch samples",
+		})
+		return
+	}
+
+	response := RunSamplesDao{
+		RunID:        run.ID.String(),
+		Count:        stored.SampleCount,
+		Samples:      curve,
+		Verification: s.runVerification(stored),
+	}
+	if points > 0 && points < len(curve) {
+		response.Samples = samples.Downsample(curve, points)
+		response.Downsampled = true
+	}
+
+	jsonWithETag(c, http.StatusOK, response)
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	"github.com/tt-trichter/app/api/internal/database"
+	"github.com/tt-trichter/app/api/internal/scoring"
+)
+
+// scoreRunData evaluates a formula against a run's measurements. ok is false
+// when the run has no finite score, e.g. after a division by zero.
+func scoreRunData(expression *scoring.Expression, data []byte) (score float64, ok bool) {
+	var values map[string]float64
+	if err := json.Unmarshal(data, &values); err != nil {
+		log.Printf("Error unmarshaling run data: %v", err)
+		return 0, false
+	}
+
+	score, err := expression.Evaluate(values)
+	if err != nil {
+		return 0, false
+	}
+	return score, true
+}
+
+// rescoreFormula replaces the cached scores of one formula for every run,
+// deleted ones included so a restored run ranks straight away.
+func rescoreFormula(ctx context.Context, queries *database.Queries, formula database.ScoringFormula) error {
+	expression, err := scoring.Parse(formula.Expression)
+	if err != nil {
+		return err
+	}
+
+	if err := queries.DeleteFormulaScores(ctx, formula.Name); err != nil {
+		return err
+	}
+
+	runs, err := queries.GetRunDataForScoring(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, run := range runs {
+		score, ok := scoreRunData(expression, run.Data)
+		if !ok {
+			continue
+		}
+		if err := queries.SaveRunScore(ctx, database.SaveRunScoreParams{
+			RunID:   run.ID,
+			Formula: formula.Name,
+			Score:   score,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// scoreRun refreshes the cached scores of every formula for a run whose
+// measurements were just recorded or corrected.
+func (s *Server) scoreRun(ctx context.Context, run database.Run) {
+	formulas, err := s.db.Queries().ListScoringFormulas(ctx)
+	if err != nil {
+		log.Printf("Error listing scoring formulas: %v", err)
+		return
+	}
+
+	for _, formula := range formulas {
+		expression, err := scoring.Parse(formula.Expression)
+		if err != nil {
+			log.Printf("Error parsing scoring formula %s: %v", formula.Name, err)
+			continue
+		}
+
+		score, ok := scoreRunData(expression, run.Data)
+		if !ok {
+			err = s.db.Queries().DeleteRunScore(ctx, database.DeleteRunScoreParams{
+				RunID:   run.ID,
+				Formula: formula.Name,
+			})
+		} else {
+			err = s.db.Queries().SaveRunScore(ctx, database.SaveRunScoreParams{
+				RunID:   run.ID,
+				Formula: formula.Name,
+				Score:   score,
+			})
+		}
+		if err != nil {
+			log.Printf("Error caching %s score for run %s: %v", formula.Name, run.ID.String(), err)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package server
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/tt-trichter/app/api/internal/database"
+	"github.com/tt-trichter/app/api/internal/scoring"
+)
+
+// Formula names appear in leaderboard URLs, so keep them URL friendly.
+var scoringFormulaName = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,63}$`)
+
+type ScoringFormulaDco struct {
+	Name        string `json:"name"`
+	Expression  string `json:"expression" binding:"required"`
+	Description string `json:"description"`
+}
+
+type ScoringFormulaDao struct {
+	Name        string    `json:"name"`
+	Expression  string    `json:"expression"`
+	Description string    `json:"description,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+func newScoringFormulaDao(formula database.ScoringFormula) ScoringFormulaDao {
+	return ScoringFormulaDao{
+		Name:        formula.Name,
+		Expression:  formula.Expression,
+		Description: formula.Description.String,
+		CreatedAt:   formula.CreatedAt.Time,
+		UpdatedAt:   formula.UpdatedAt.Time,
+	}
+}
+
+func (s *Server) listScoringFormulasHandler(c *gin.Context) {
+	formulas, err := s.db.Queries().ListScoringFormulas(c.Request.Context())
+	if err != nil {
+		log.Printf("Error listing scoring formulas: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to fetch scoring formulas",
+		})
+		return
+	}
+
+	response := make([]ScoringFormulaDao, 0, len(formulas))
+	for _, formula := range formulas {
+		response = append(response, newScoringFormulaDao(formula))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (s *Server) createScoringFormulaHandler(c *gin.Context) {
+	var formulaDco ScoringFormulaDco
+	if !bindScoringFormulaDco(c, &formulaDco) {
+		return
+	}
+	if !scoringFormulaName.MatchString(formulaDco.Name) {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Validation failed",
+			Details: "name must be 1-64 lowercase letters, digits or dashes",
+		})
+		return
+	}
+
+	s.saveScoringFormula(c, http.StatusCreated, func(queries *database.Queries) (database.ScoringFormula, error) {
+		return queries.CreateScoringFormula(c.Request.Context(), database.CreateScoringFormulaParams{
+			Name:        formulaDco.Name,
+			Expression:  formulaDco.Expression,
+			Description: pgtype.Text{String: formulaDco.Description, Valid: formulaDco.Description != ""},
+		})
+	})
+}
+
+func (s *Server) updateScoringFormulaHandler(c *gin.Context) {
+	var formulaDco ScoringFormulaDco
+	if !bindScoringFormulaDco(c, &formulaDco) {
+		return
+	}
+
+	s.saveScoringFormula(c, http.StatusOK, func(queries *database.Queries) (database.ScoringFormula, error) {
+		return queries.UpdateScoringFormula(c.Request.Context(), database.UpdateScoringFormulaParams{
+			Name:        c.Param("name"),
+			Expression:  formulaDco.Expression,
+			Description: pgtype.Text{String: formulaDco.Description, Valid: formulaDco.Description != ""},
+		})
+	})
+}
+
+// saveScoringFormula stores a formula and recomputes its cached scores in
+// the same transaction, so leaderboards never mix old and new scores.
+func (s *Server) saveScoringFormula(c *gin.Context, status int, save func(*database.Queries) (database.ScoringFormula, error)) {
+	ctx := c.Request.Context()
+
+	tx, err := s.db.Pool().Begin(ctx)
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to save scoring formula",
+		})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	queries := s.db.Queries().WithTx(tx)
+
+	formula, err := save(queries)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		c.JSON(http.StatusConflict, APIResponse{
+			Success: false,
+			Error:   "Scoring formula already exists",
+		})
+		return
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, APIResponse{
+			Success: false,
+			Error:   "Scoring formula not found",
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Error saving scoring formula: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to save scoring formula",
+		})
+		return
+	}
+
+	if err := rescoreFormula(ctx, queries, formula); err != nil {
+		log.Printf("Error scoring runs with formula %s: %v", formula.Name, err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to save scoring formula",
+		})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("Error committing scoring formula: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to save scoring formula",
+		})
+		return
+	}
+
+	log.Printf("Saved scoring formula %s: %s", formula.Name, formula.Expression)
+
+	c.JSON(status, newScoringFormulaDao(formula))
+}
+
+func (s *Server) deleteScoringFormulaHandler(c *gin.Context) {
+	deleted, err := s.db.Queries().DeleteScoringFormula(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		log.Printf("Error deleting scoring formula: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to delete scoring formula",
+		})
+		return
+	}
+	if deleted == 0 {
+		c.JSON(http.StatusNotFound, APIResponse{
+			Success: false,
+			Error:   "Scoring formula not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, APIResponse{Success: true})
+}
+
+// requireScoringFormula checks that a leaderboard's score parameter names an
+// existing formula, writing the error response itself when it does not.
+func (s *Server) requireScoringFormula(c *gin.Context, name string) bool {
+	if name == "" {
+		return true
+	}
+
+	_, err := s.db.Queries().GetScoringFormula(c.Request.Context(), name)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Invalid query parameters",
+			Details: "unknown scoring formula " + name,
+		})
+		return false
+	}
+	if err != nil {
+		log.Printf("Error getting scoring formula: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to fetch leaderboard",
+		})
+		return false
+	}
+	return true
+}
+
+func bindScoringFormulaDco(c *gin.Context, formulaDco *ScoringFormulaDco) bool {
+	if err := c.ShouldBindJSON(formulaDco); err != nil {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Validation failed",
+			Details: err.Error(),
+		})
+		return false
+	}
+	if _, err := scoring.Parse(formulaDco.Expression); err != nil {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Invalid expression",
+			Details: err.Error(),
+		})
+		return false
+	}
+	return true
+}
@@ -0,0 +1,463 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/tt-trichter/app/api/internal/database"
+)
+
+const (
+	seasonCloseInterval = time.Hour
+	// seasonArchiveLimit caps how many users are archived per metric.
+	seasonArchiveLimit = 1000
+)
+
+type SeasonDco struct {
+	Name     string    `json:"name" binding:"required"`
+	StartsAt time.Time `json:"startsAt" binding:"required"`
+	EndsAt   time.Time `json:"endsAt" binding:"required"`
+}
+
+type SeasonDao struct {
+	ID       string     `json:"id"`
+	Name     string     `json:"name"`
+	StartsAt time.Time  `json:"startsAt"`
+	EndsAt   time.Time  `json:"endsAt"`
+	ClosedAt *time.Time `json:"closedAt"`
+}
+
+type SeasonStandingDao struct {
+	Rank       int64     `json:"rank"`
+	Score      float64   `json:"score"`
+	RunID      string    `json:"runId"`
+	RecordedAt time.Time `json:"recordedAt"`
+	User       UserInfo  `json:"user"`
+}
+
+type SeasonStandingsDao struct {
+	Season    SeasonDao           `json:"season"`
+	Metric    string              `json:"metric"`
+	Final     bool                `json:"final"`
+	Standings []SeasonStandingDao `json:"standings"`
+}
+
+type ChampionshipDao struct {
+	SeasonID   string    `json:"seasonId"`
+	SeasonName string    `json:"seasonName"`
+	EndedAt    time.Time `json:"endedAt"`
+	Metric     string    `json:"metric"`
+	Score      float64   `json:"score"`
+	RunID      string    `json:"runId"`
+}
+
+func newSeasonDao(season database.Season) SeasonDao {
+	dao := SeasonDao{
+		ID:       season.ID.String(),
+		Name:     season.Name,
+		StartsAt: season.StartsAt.Time,
+		EndsAt:   season.EndsAt.Time,
+	}
+	if season.ClosedAt.Valid {
+		dao.ClosedAt = &season.ClosedAt.Time
+	}
+	return dao
+}
+
+func (s *Server) listSeasonsHandler(c *gin.Context) {
+	seasons, err := s.db.Queries().ListSeasons(c.Request.Context())
+	if err != nil {
+		log.Printf("Error listing seasons: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to fetch seasons",
+		})
+		return
+	}
+
+	response := make([]SeasonDao, 0, len(seasons))
+	for _, season := range seasons {
+		response = append(response, newSeasonDao(season))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (s *Server) createSeasonHandler(c *gin.Context) {
+	var seasonDco SeasonDco
+	if !bindSeasonDco(c, &seasonDco) {
+		return
+	}
+
+	season, err := s.db.Queries().CreateSeason(c.Request.Context(), database.CreateSeasonParams{
+		Name:     seasonDco.Name,
+		StartsAt: pgtype.Timestamp{Time: seasonDco.StartsAt.UTC(), Valid: true},
+		EndsAt:   pgtype.Timestamp{Time: seasonDco.EndsAt.UTC(), Valid: true},
+	})
+	if err != nil {
+		log.Printf("Error creating season: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to create season",
+		})
+		return
+	}
+
+	log.Printf("Created season %s: %s", season.ID.String(), season.Name)
+
+	c.JSON(http.StatusCreated, newSeasonDao(season))
+}
+
+func (s *Server) updateSeasonHandler(c *gin.Context) {
+	var seasonUUID pgtype.UUID
+	if err := seasonUUID.Scan(c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Invalid season ID format",
+		})
+		return
+	}
+
+	var seasonDco SeasonDco
+	if !bindSeasonDco(c, &seasonDco) {
+		return
+	}
+
+	season, err := s.db.Queries().UpdateSeason(c.Request.Context(), database.UpdateSeasonParams{
+		ID:       seasonUUID,
+		Name:     seasonDco.Name,
+		StartsAt: pgtype.Timestamp{Time: seasonDco.StartsAt.UTC(), Valid: true},
+		EndsAt:   pgtype.Timestamp{Time: seasonDco.EndsAt.UTC(), Valid: true},
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, APIResponse{
+			Success: false,
+			Error:   "Season not found or already closed",
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Error updating season: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to update season",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, newSeasonDao(season))
+}
+
+func (s *Server) closeSeasonHandler(c *gin.Context) {
+	var seasonUUID pgtype.UUID
+	if err := seasonUUID.Scan(c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Invalid season ID format",
+		})
+		return
+	}
+
+	season, err := s.db.Queries().GetSeason(c.Request.Context(), seasonUUID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, APIResponse{
+			Success: false,
+			Error:   "Season not found",
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Error getting season: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to close season",
+		})
+		return
+	}
+
+	closed, err := s.closeSeason(c.Request.Context(), season)
+	if err != nil {
+		log.Printf("Error closing season: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to close season",
+		})
+		return
+	}
+	if !closed {
+		c.JSON(http.StatusConflict, APIResponse{
+			Success: false,
+			Error:   "Season already closed",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, APIResponse{Success: true})
+}
+
+func (s *Server) getCurrentSeasonStandingsHandler(c *gin.Context) {
+	season, err := s.db.Queries().GetCurrentSeason(c.Request.Context(), pgtype.Timestamp{Time: time.Now().UTC(), Valid: true})
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, APIResponse{
+			Success: false,
+			Error:   "No season is running",
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Error getting current season: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to fetch standings",
+		})
+		return
+	}
+
+	s.writeSeasonStandings(c, season)
+}
+
+func (s *Server) getSeasonStandingsHandler(c *gin.Context) {
+	var seasonUUID pgtype.UUID
+	if err := seasonUUID.Scan(c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Invalid season ID format",
+		})
+		return
+	}
+
+	season, err := s.db.Queries().GetSeason(c.Request.Context(), seasonUUID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, APIResponse{
+			Success: false,
+			Error:   "Season not found",
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Error getting season: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to fetch standings",
+		})
+		return
+	}
+
+	s.writeSeasonStandings(c, season)
+}
+
+// writeSeasonStandings serves the archived standings of a closed season, or
+// the live best-per-user leaderboard of one that is still open.
+func (s *Server) writeSeasonStandings(c *gin.Context, season database.Season) {
+	metric := database.LeaderboardMetric(c.DefaultQuery("metric", string(database.LeaderboardMetricRate)))
+	if !metric.Valid() {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Invalid query parameters",
+			Details: "metric must be one of rate, duration, volume",
+		})
+		return
+	}
+
+	response := SeasonStandingsDao{
+		Season:    newSeasonDao(season),
+		Metric:    string(metric),
+		Final:     season.ClosedAt.Valid,
+		Standings: []SeasonStandingDao{},
+	}
+
+	if season.ClosedAt.Valid {
+		standings, err := s.db.Queries().GetSeasonStandings(c.Request.Context(), database.GetSeasonStandingsParams{
+			SeasonID: season.ID,
+			Metric:   string(metric),
+		})
+		if err != nil {
+			log.Printf("Error getting season standings: %v", err)
+			c.JSON(http.StatusInternalServerError, APIResponse{
+				Success: false,
+				Error:   "Failed to fetch standings",
+			})
+			return
+		}
+
+		for _, standing := range standings {
+			response.Standings = append(response.Standings, SeasonStandingDao{
+				Rank:       int64(standing.Rank),
+				Score:      standing.Score,
+				RunID:      standing.RunID.String(),
+				RecordedAt: standing.RecordedAt.Time,
+				User: UserInfo{
+					ID:       standing.UserID,
+					Name:     standing.UserName,
+					Username: standing.UserUsername,
+				},
+			})
+		}
+
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	rows, err := seasonLeaderboard(c.Request.Context(), s.db.Queries(), season, metric, defaultPageLimit)
+	if err != nil {
+		log.Printf("Error getting season leaderboard: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to fetch standings",
+		})
+		return
+	}
+
+	for _, row := range rows {
+		response.Standings = append(response.Standings, SeasonStandingDao{
+			Rank:       row.Rank,
+			Score:      row.Score,
+			RunID:      row.ID.String(),
+			RecordedAt: row.CreatedAt.Time,
+			User: UserInfo{
+				ID:       row.UserIDFull.String,
+				Name:     row.UserName.String,
+				Username: row.UserUsername.String,
+			},
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func seasonLeaderboard(ctx context.Context, queries *database.Queries, season database.Season, metric database.LeaderboardMetric, limit int32) ([]database.GetLeaderboardRow, error) {
+	return queries.GetLeaderboard(ctx, database.GetLeaderboardParams{
+		Metric:      metric,
+		From:        season.StartsAt,
+		To:          season.EndsAt,
+		BestPerUser: true,
+		RequireUser: true,
+		Limit:       limit,
+	})
+}
+
+// closeSeason marks the season closed and archives its final standings for
+// every metric in one transaction. The transaction is repeatable read so all
+// metrics are archived from the same snapshot of the runs. It reports false
+// if the season had already been closed.
+func (s *Server) closeSeason(ctx context.Context, season database.Season) (bool, error) {
+	tx, err := s.db.Pool().BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead})
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	queries := s.db.Queries().WithTx(tx)
+
+	closed, err := queries.CloseSeason(ctx, season.ID)
+	if err != nil {
+		return false, err
+	}
+	if closed == 0 {
+		return false, nil
+	}
+
+	for _, metric := range database.LeaderboardMetrics {
+		rows, err := seasonLeaderboard(ctx, queries, season, metric, seasonArchiveLimit)
+		if err != nil {
+			return false, err
+		}
+
+		for _, row := range rows {
+			if err := queries.CreateSeasonStanding(ctx, database.CreateSeasonStandingParams{
+				SeasonID:     season.ID,
+				Metric:       string(metric),
+				Rank:         int32(row.Rank),
+				UserID:       row.UserIDFull.String,
+				UserName:     row.UserName.String,
+				UserUsername: row.UserUsername.String,
+				RunID:        row.ID,
+				Score:        row.Score,
+				RecordedAt:   row.CreatedAt,
+			}); err != nil {
+				return false, err
+			}
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, err
+	}
+
+	log.Printf("Closed season %s and archived its standings", season.Name)
+	return true, nil
+}
+
+// runSeasonClosing closes seasons once their end boundary has passed.
+func (s *Server) runSeasonClosing() {
+	ticker := time.NewTicker(seasonCloseInterval)
+	defer ticker.Stop()
+
+	for {
+		s.closeEndedSeasons()
+		<-ticker.C
+	}
+}
+
+func (s *Server) closeEndedSeasons() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	seasons, err := s.db.Queries().GetSeasonsToClose(ctx, pgtype.Timestamp{Time: time.Now().UTC(), Valid: true})
+	if err != nil {
+		log.Printf("Error listing seasons to close: %v", err)
+		return
+	}
+
+	for _, season := range seasons {
+		if _, err := s.closeSeason(ctx, season); err != nil {
+			log.Printf("Error closing season %s: %v", season.Name, err)
+		}
+	}
+}
+
+func (s *Server) userChampionships(ctx context.Context, userID string) ([]ChampionshipDao, error) {
+	rows, err := s.db.Queries().GetUserChampionships(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	championships := make([]ChampionshipDao, 0, len(rows))
+	for _, row := range rows {
+		championships = append(championships, ChampionshipDao{
+			SeasonID:   row.SeasonID.String(),
+			SeasonName: row.SeasonName,
+			EndedAt:    row.SeasonEndsAt.Time,
+			Metric:     row.Metric,
+			Score:      row.Score,
+			RunID:      row.RunID.String(),
+		})
+	}
+	return championships, nil
+}
+
+func bindSeasonDco(c *gin.Context, seasonDco *SeasonDco) bool {
+	if err := c.ShouldBindJSON(seasonDco); err != nil {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Validation failed",
+			Details: err.Error(),
+		})
+		return false
+	}
+	if !seasonDco.EndsAt.After(seasonDco.StartsAt) {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Validation failed",
+			Details: "endsAt must be after startsAt",
+		})
+		return false
+	}
+	return true
+}
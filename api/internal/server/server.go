@@ -10,20 +10,60 @@ import (
 	_ "github.com/joho/godotenv/autoload"
 
 	"github.com/tt-trichter/app/api/internal/database"
+	"github.com/tt-trichter/app/api/internal/plausibility"
+	"github.com/tt-trichter/app/api/internal/rating"
 )
 
+const defaultTrashRetention = 30 * 24 * time.Hour
+
 type Server struct {
-	port int
-	db   database.Service
+	port    int
+	db      database.Service
+	events  *EventBroker
+	nights  nightClock
+	ratings rating.Config
+
+	metricCheck     metricCheck
+	plausibility    plausibility.Limits
+	reportThreshold int
+
+	imageBaseURL         string
+	trashRetention       time.Duration
+	maxRunBackdate       time.Duration
+	duelWindow           time.Duration
+	duelTimeout          time.Duration
+	deviceTimeout        time.Duration
+	idempotencyRetention time.Duration
 }
 
 func NewServer() *http.Server {
 	port, _ := strconv.Atoi(os.Getenv("REST_PORT"))
 	NewServer := &Server{
-		port: port,
-		db:   database.NewService(),
+		port:                 port,
+		db:                   database.NewService(),
+		events:               NewEventBroker(),
+		nights:               nightClockFromEnv(),
+		ratings:              rating.ConfigFromEnv(),
+		metricCheck:          metricCheckFromEnv(),
+		plausibility:         plausibility.LimitsFromEnv(),
+		reportThreshold:      intFromEnv("RUN_REPORT_THRESHOLD", defaultReportThreshold),
+		imageBaseURL:         os.Getenv("PUBLIC_IMAGE_BASE_URL"),
+		trashRetention:       durationFromEnv("RUN_TRASH_RETENTION", defaultTrashRetention),
+		maxRunBackdate:       durationFromEnv("RUN_MAX_BACKDATE", defaultMaxRunBackdate),
+		duelWindow:           durationFromEnv("DUEL_WINDOW", defaultDuelWindow),
+		duelTimeout:          durationFromEnv("DUEL_TIMEOUT", defaultDuelTimeout),
+		deviceTimeout:        durationFromEnv("DEVICE_HEARTBEAT_TIMEOUT", defaultDeviceTimeout),
+		idempotencyRetention: durationFromEnv("IDEMPOTENCY_KEY_RETENTION", defaultIdempotencyRetention),
 	}
 
+	go NewServer.runTrashRetention()
+	go NewServer.backfillAchievements()
+	go NewServer.runSeasonClosing()
+	go NewServer.backfillDivisions()
+	go NewServer.runDuelExpiry()
+	go NewServer.runIdempotencyKeyExpiry()
+	go NewServer.runDeviceStatusCheck()
+
 	server := &http.Server{
 		Addr:         fmt.Sprintf(":%d", NewServer.port),
 		Handler:      NewServer.RegisterRoutes(),
@@ -34,3 +74,19 @@ func NewServer() *http.Server {
 
 	return server
 }
+
+func durationFromEnv(key string, fallback time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
+}
+
+func intFromEnv(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
+}
@@ -1,3 +1,101 @@
 package server
 
-//TODO
+import (
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	EventRunCreated  = "run-created"
+	EventRunUpdated  = "run-updated"
+	EventRunDeleted  = "run-deleted"
+	EventRunRestored = "run-restored"
+	// EventRunHeld is sent when a published run is held for review. Clients
+	// hide it until it is approved, which sends run-created again.
+	EventRunHeld = "run-held"
+)
+
+const sseKeepAliveInterval = 15 * time.Second
+
+type Event struct {
+	Name string
+	Data any
+}
+
+// EventBroker fans out server events to every connected SSE client.
+type EventBroker struct {
+	mu          sync.RWMutex
+	subscribers map[chan Event]struct{}
+}
+
+func NewEventBroker() *EventBroker {
+	return &EventBroker{
+		subscribers: make(map[chan Event]struct{}),
+	}
+}
+
+func (b *EventBroker) Subscribe() chan Event {
+	ch := make(chan Event, 16)
+
+	b.mu.Lock()
+	b.subscribers[ch] = struct{}{}
+	b.mu.Unlock()
+
+	return ch
+}
+
+func (b *EventBroker) Unsubscribe(ch chan Event) {
+	b.mu.Lock()
+	delete(b.subscribers, ch)
+	b.mu.Unlock()
+}
+
+// Publish never blocks; events are dropped for clients that are not keeping up.
+func (b *EventBroker) Publish(name string, data any) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for ch := range b.subscribers {
+		select {
+		case ch <- Event{Name: name, Data: data}:
+		default:
+			log.Printf("Dropping %s event for slow SSE client", name)
+		}
+	}
+}
+
+func (s *Server) runsSSEHandler(c *gin.Context) {
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("Access-Control-Allow-Origin", "*")
+
+	// The stream outlives the server's WriteTimeout, so lift it for this request.
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		log.Printf("Error clearing SSE write deadline: %v", err)
+	}
+
+	events := s.events.Subscribe()
+	defer s.events.Unsubscribe(events)
+
+	keepAlive := time.NewTicker(sseKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case event := <-events:
+			c.SSEvent(event.Name, event.Data)
+			return true
+		case <-keepAlive.C:
+			_, err := io.WriteString(w, ": keep-alive\n\n")
+			return err == nil
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
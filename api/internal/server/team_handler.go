@@ -0,0 +1,991 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/tt-trichter/app/api/internal/database"
+)
+
+const (
+	teamRoleCaptain = "captain"
+	teamRoleMember  = "member"
+)
+
+const (
+	invitationStatusPending  = "pending"
+	invitationStatusAccepted = "accepted"
+	invitationStatusDeclined = "declined"
+	invitationStatusRevoked  = "revoked"
+)
+
+const (
+	defaultTeamBestN = 3
+	maxTeamBestN     = 50
+)
+
+var (
+	errNotTeamCaptain  = errors.New("only team captains can do this")
+	errNotTeamMember   = errors.New("user is not a member of this team")
+	errLastTeamCaptain = errors.New("promote another captain before the last captain steps down")
+)
+
+// TeamDco creates or renames a team. The API has no user sessions of its
+// own, so the web app authenticates with basic auth and names the acting
+// user as actorId in every team mutation.
+type TeamDco struct {
+	Name        string `json:"name" binding:"required,max=64"`
+	Description string `json:"description" binding:"max=500"`
+	ActorID     string `json:"actorId" binding:"required"`
+}
+
+type TeamActorDco struct {
+	ActorID string `json:"actorId" binding:"required"`
+}
+
+type TeamInvitationDco struct {
+	UserID  string `json:"userId" binding:"required"`
+	ActorID string `json:"actorId" binding:"required"`
+}
+
+type TeamMemberRoleDco struct {
+	Role    string `json:"role" binding:"required,oneof=captain member"`
+	ActorID string `json:"actorId" binding:"required"`
+}
+
+type TeamDao struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description *string   `json:"description"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+type TeamListEntryDao struct {
+	TeamDao
+	MemberCount int64 `json:"memberCount"`
+}
+
+type TeamMemberDao struct {
+	User     UserInfo  `json:"user"`
+	Role     string    `json:"role"`
+	JoinedAt time.Time `json:"joinedAt"`
+}
+
+// TeamStatsDao sums up the runs members recorded while on the team.
+type TeamStatsDao struct {
+	Runs        int64      `json:"runs"`
+	TotalVolume float64    `json:"totalVolume"`
+	BestRate    float64    `json:"bestRate"`
+	AverageRate float64    `json:"averageRate"`
+	LastRunAt   *time.Time `json:"lastRunAt"`
+}
+
+type TeamProfileDao struct {
+	TeamDao
+	Members []TeamMemberDao `json:"members"`
+	Stats   TeamStatsDao    `json:"stats"`
+}
+
+type UserTeamDao struct {
+	TeamDao
+	Role     string    `json:"role"`
+	JoinedAt time.Time `json:"joinedAt"`
+}
+
+type TeamInvitationDao struct {
+	ID        string    `json:"id"`
+	TeamID    string    `json:"teamId"`
+	TeamName  string    `json:"teamName,omitempty"`
+	User      *UserInfo `json:"user,omitempty"`
+	InvitedBy *string   `json:"invitedBy"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type TeamLeaderboardEntryDao struct {
+	Rank    int64   `json:"rank"`
+	TeamID  string  `json:"teamId"`
+	Name    string  `json:"name"`
+	Score   float64 `json:"score"`
+	Runs    int64   `json:"runs"`
+	Members int64   `json:"members"`
+}
+
+type TeamLeaderboardDao struct {
+	Window   string                    `json:"window"`
+	From     *time.Time                `json:"from"`
+	To       *time.Time                `json:"to"`
+	Rule     string                    `json:"rule"`
+	Metric   string                    `json:"metric"`
+	BestN    int32                     `json:"bestN,omitempty"`
+	Division string                    `json:"division,omitempty"`
+	Entries  []TeamLeaderboardEntryDao `json:"entries"`
+}
+
+func newTeamDao(team database.Team) TeamDao {
+	dao := TeamDao{
+		ID:        team.ID.String(),
+		Name:      team.Name,
+		CreatedAt: team.CreatedAt.Time,
+	}
+	if team.Description.Valid {
+		dao.Description = &team.Description.String
+	}
+	return dao
+}
+
+func teamDescription(description string) pgtype.Text {
+	return pgtype.Text{String: description, Valid: description != ""}
+}
+
+func (s *Server) listTeamsHandler(c *gin.Context) {
+	teams, err := s.db.Queries().ListTeams(c.Request.Context())
+	if err != nil {
+		log.Printf("Error listing teams: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to fetch teams",
+		})
+		return
+	}
+
+	response := make([]TeamListEntryDao, 0, len(teams))
+	for _, team := range teams {
+		response = append(response, TeamListEntryDao{
+			TeamDao: newTeamDao(database.Team{
+				ID:          team.ID,
+				Name:        team.Name,
+				Description: team.Description,
+				CreatedAt:   team.CreatedAt,
+			}),
+			MemberCount: team.MemberCount,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// createTeamHandler creates a team with the acting user as its captain.
+func (s *Server) createTeamHandler(c *gin.Context) {
+	var teamDco TeamDco
+	if err := c.ShouldBindJSON(&teamDco); err != nil {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Validation failed",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	ctx := c.Request.Context()
+	if !s.requireUser(c, teamDco.ActorID) {
+		return
+	}
+
+	tx, err := s.db.Pool().Begin(ctx)
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to create team",
+		})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	queries := s.db.Queries().WithTx(tx)
+
+	team, err := queries.CreateTeam(ctx, database.CreateTeamParams{
+		Name:        teamDco.Name,
+		Description: teamDescription(teamDco.Description),
+	})
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		c.JSON(http.StatusConflict, APIResponse{
+			Success: false,
+			Error:   "Team name is already taken",
+		})
+		return
+	}
+	if err == nil {
+		_, err = queries.AddTeamMember(ctx, database.AddTeamMemberParams{
+			TeamID: team.ID,
+			UserID: teamDco.ActorID,
+			Role:   teamRoleCaptain,
+		})
+	}
+	if err == nil {
+		err = tx.Commit(ctx)
+	}
+	if err != nil {
+		log.Printf("Error creating team: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to create team",
+		})
+		return
+	}
+
+	log.Printf("Created team %s: %s", team.ID.String(), team.Name)
+
+	c.JSON(http.StatusCreated, newTeamDao(team))
+}
+
+// getTeamHandler returns the team profile with its members and stats.
+func (s *Server) getTeamHandler(c *gin.Context) {
+	team, ok := s.lookupTeam(c)
+	if !ok {
+		return
+	}
+	ctx := c.Request.Context()
+
+	members, err := s.db.Queries().GetTeamMembers(ctx, team.ID)
+	if err != nil {
+		log.Printf("Error getting team members: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to fetch team",
+		})
+		return
+	}
+
+	stats, err := s.db.Queries().GetTeamStats(ctx, team.ID)
+	if err != nil {
+		log.Printf("Error getting team stats: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to fetch team",
+		})
+		return
+	}
+
+	response := TeamProfileDao{
+		TeamDao: newTeamDao(team),
+		Members: make([]TeamMemberDao, 0, len(members)),
+		Stats: TeamStatsDao{
+			Runs:        stats.Runs,
+			TotalVolume: stats.TotalVolume,
+			BestRate:    stats.BestRate,
+			AverageRate: stats.AverageRate,
+		},
+	}
+	if stats.LastRunAt.Valid {
+		response.Stats.LastRunAt = &stats.LastRunAt.Time
+	}
+	for _, member := range members {
+		response.Members = append(response.Members, TeamMemberDao{
+			User: UserInfo{
+				ID:       member.UserID,
+				Name:     member.UserName,
+				Username: member.UserUsername,
+			},
+			Role:     member.Role,
+			JoinedAt: member.JoinedAt.Time,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (s *Server) updateTeamHandler(c *gin.Context) {
+	team, ok := s.lookupTeam(c)
+	if !ok {
+		return
+	}
+
+	var teamDco TeamDco
+	if err := c.ShouldBindJSON(&teamDco); err != nil {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Validation failed",
+			Details: err.Error(),
+		})
+		return
+	}
+	if !s.requireTeamCaptain(c, team.ID, teamDco.ActorID) {
+		return
+	}
+
+	updated, err := s.db.Queries().UpdateTeam(c.Request.Context(), database.UpdateTeamParams{
+		ID:          team.ID,
+		Name:        teamDco.Name,
+		Description: teamDescription(teamDco.Description),
+	})
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		c.JSON(http.StatusConflict, APIResponse{
+			Success: false,
+			Error:   "Team name is already taken",
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Error updating team: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to update team",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, newTeamDao(updated))
+}
+
+func (s *Server) deleteTeamHandler(c *gin.Context) {
+	team, ok := s.lookupTeam(c)
+	if !ok {
+		return
+	}
+
+	var actorDco TeamActorDco
+	if err := c.ShouldBindJSON(&actorDco); err != nil {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Validation failed",
+			Details: err.Error(),
+		})
+		return
+	}
+	if !s.requireTeamCaptain(c, team.ID, actorDco.ActorID) {
+		return
+	}
+
+	if _, err := s.db.Queries().DeleteTeam(c.Request.Context(), team.ID); err != nil {
+		log.Printf("Error deleting team: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to delete team",
+		})
+		return
+	}
+
+	log.Printf("Deleted team %s: %s", team.ID.String(), team.Name)
+
+	c.JSON(http.StatusOK, APIResponse{Success: true})
+}
+
+// updateTeamMemberHandler lets a captain promote a member or demote a
+// captain. A team always keeps at least one captain.
+func (s *Server) updateTeamMemberHandler(c *gin.Context) {
+	team, ok := s.lookupTeam(c)
+	if !ok {
+		return
+	}
+
+	var roleDco TeamMemberRoleDco
+	if err := c.ShouldBindJSON(&roleDco); err != nil {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Validation failed",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	userID := c.Param("userId")
+	s.changeTeamMembership(c, team.ID, func(ctx context.Context, queries *database.Queries, members map[string]string) error {
+		if members[roleDco.ActorID] != teamRoleCaptain {
+			return errNotTeamCaptain
+		}
+		if members[userID] == "" {
+			return errNotTeamMember
+		}
+		if roleDco.Role != teamRoleCaptain && members[userID] == teamRoleCaptain && countCaptains(members) == 1 {
+			return errLastTeamCaptain
+		}
+		_, err := queries.SetTeamMemberRole(ctx, database.SetTeamMemberRoleParams{
+			TeamID: team.ID,
+			UserID: userID,
+			Role:   roleDco.Role,
+		})
+		return err
+	})
+}
+
+// removeTeamMemberHandler removes a member, either by a captain or by the
+// member leaving. The team is disbanded when its last member leaves.
+func (s *Server) removeTeamMemberHandler(c *gin.Context) {
+	team, ok := s.lookupTeam(c)
+	if !ok {
+		return
+	}
+
+	var actorDco TeamActorDco
+	if err := c.ShouldBindJSON(&actorDco); err != nil {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Validation failed",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	userID := c.Param("userId")
+	s.changeTeamMembership(c, team.ID, func(ctx context.Context, queries *database.Queries, members map[string]string) error {
+		if actorDco.ActorID != userID && members[actorDco.ActorID] != teamRoleCaptain {
+			return errNotTeamCaptain
+		}
+		if members[userID] == "" {
+			return errNotTeamMember
+		}
+		if len(members) == 1 {
+			log.Printf("Disbanding team %s: %s", team.ID.String(), team.Name)
+			_, err := queries.DeleteTeam(ctx, team.ID)
+			return err
+		}
+		if members[userID] == teamRoleCaptain && countCaptains(members) == 1 {
+			return errLastTeamCaptain
+		}
+		_, err := queries.RemoveTeamMember(ctx, database.RemoveTeamMemberParams{
+			TeamID: team.ID,
+			UserID: userID,
+		})
+		return err
+	})
+}
+
+// changeTeamMembership runs change with the team's members locked, mapped
+// from user ID to role, and writes the response.
+func (s *Server) changeTeamMembership(c *gin.Context, teamID pgtype.UUID, change func(context.Context, *database.Queries, map[string]string) error) {
+	ctx := c.Request.Context()
+
+	tx, err := s.db.Pool().Begin(ctx)
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to update team membership",
+		})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	queries := s.db.Queries().WithTx(tx)
+
+	locked, err := queries.LockTeamMembers(ctx, teamID)
+	if err == nil {
+		members := make(map[string]string, len(locked))
+		for _, member := range locked {
+			members[member.UserID] = member.Role
+		}
+		err = change(ctx, queries, members)
+	}
+	switch {
+	case errors.Is(err, errNotTeamCaptain):
+		c.JSON(http.StatusForbidden, APIResponse{
+			Success: false,
+			Error:   "Only team captains can do this",
+		})
+		return
+	case errors.Is(err, errNotTeamMember):
+		c.JSON(http.StatusNotFound, APIResponse{
+			Success: false,
+			Error:   "Team member not found",
+		})
+		return
+	case errors.Is(err, errLastTeamCaptain):
+		c.JSON(http.StatusConflict, APIResponse{
+			Success: false,
+			Error:   "Promote another captain before the last captain steps down",
+		})
+		return
+	}
+	if err == nil {
+		err = tx.Commit(ctx)
+	}
+	if err != nil {
+		log.Printf("Error updating team membership: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to update team membership",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, APIResponse{Success: true})
+}
+
+func countCaptains(members map[string]string) int {
+	captains := 0
+	for _, role := range members {
+		if role == teamRoleCaptain {
+			captains++
+		}
+	}
+	return captains
+}
+
+func (s *Server) listTeamInvitationsHandler(c *gin.Context) {
+	team, ok := s.lookupTeam(c)
+	if !ok {
+		return
+	}
+
+	invitations, err := s.db.Queries().GetTeamInvitations(c.Request.Context(), team.ID)
+	if err != nil {
+		log.Printf("Error getting team invitations: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to fetch invitations",
+		})
+		return
+	}
+
+	response := make([]TeamInvitationDao, 0, len(invitations))
+	for _, invitation := range invitations {
+		dao := TeamInvitationDao{
+			ID:     invitation.ID.String(),
+			TeamID: team.ID.String(),
+			User: &UserInfo{
+				ID:       invitation.UserID,
+				Name:     invitation.UserName,
+				Username: invitation.UserUsername,
+			},
+			CreatedAt: invitation.CreatedAt.Time,
+		}
+		if invitation.InvitedBy.Valid {
+			dao.InvitedBy = &invitation.InvitedBy.String
+		}
+		response = append(response, dao)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// createTeamInvitationHandler lets a captain invite a user. A user has at
+// most one pending invitation per team.
+func (s *Server) createTeamInvitationHandler(c *gin.Context) {
+	team, ok := s.lookupTeam(c)
+	if !ok {
+		return
+	}
+
+	var invitationDco TeamInvitationDco
+	if err := c.ShouldBindJSON(&invitationDco); err != nil {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Validation failed",
+			Details: err.Error(),
+		})
+		return
+	}
+	if !s.requireTeamCaptain(c, team.ID, invitationDco.ActorID) || !s.requireUser(c, invitationDco.UserID) {
+		return
+	}
+
+	ctx := c.Request.Context()
+	_, err := s.db.Queries().GetTeamMember(ctx, database.GetTeamMemberParams{
+		TeamID: team.ID,
+		UserID: invitationDco.UserID,
+	})
+	if err == nil {
+		c.JSON(http.StatusConflict, APIResponse{
+			Success: false,
+			Error:   "User is already a member of this team",
+		})
+		return
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		log.Printf("Error getting team member: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to create invitation",
+		})
+		return
+	}
+
+	invitation, err := s.db.Queries().CreateTeamInvitation(ctx, database.CreateTeamInvitationParams{
+		TeamID:    team.ID,
+		UserID:    invitationDco.UserID,
+		InvitedBy: pgtype.Text{String: invitationDco.ActorID, Valid: true},
+	})
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		c.JSON(http.StatusConflict, APIResponse{
+			Success: false,
+			Error:   "User already has a pending invitation to this team",
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Error creating team invitation: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to create invitation",
+		})
+		return
+	}
+
+	log.Printf("Invited %s to team %s", invitation.UserID, team.Name)
+
+	c.JSON(http.StatusCreated, TeamInvitationDao{
+		ID:        invitation.ID.String(),
+		TeamID:    team.ID.String(),
+		InvitedBy: &invitationDco.ActorID,
+		CreatedAt: invitation.CreatedAt.Time,
+	})
+}
+
+func (s *Server) acceptTeamInvitationHandler(c *gin.Context) {
+	s.respondToTeamInvitation(c, invitationStatusAccepted)
+}
+
+func (s *Server) declineTeamInvitationHandler(c *gin.Context) {
+	s.respondToTeamInvitation(c, invitationStatusDeclined)
+}
+
+func (s *Server) revokeTeamInvitationHandler(c *gin.Context) {
+	s.respondToTeamInvitation(c, invitationStatusRevoked)
+}
+
+// respondToTeamInvitation settles a pending invitation. Only the invited
+// user may accept or decline it, only a captain may revoke it. Accepting
+// makes the user a member.
+func (s *Server) respondToTeamInvitation(c *gin.Context, status string) {
+	team, ok := s.lookupTeam(c)
+	if !ok {
+		return
+	}
+
+	var invitationUUID pgtype.UUID
+	if err := invitationUUID.Scan(c.Param("invitationId")); err != nil {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Invalid invitation ID format",
+		})
+		return
+	}
+
+	var actorDco TeamActorDco
+	if err := c.ShouldBindJSON(&actorDco); err != nil {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Validation failed",
+			Details: err.Error(),
+		})
+		return
+	}
+	if status == invitationStatusRevoked && !s.requireTeamCaptain(c, team.ID, actorDco.ActorID) {
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	tx, err := s.db.Pool().Begin(ctx)
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to update invitation",
+		})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	queries := s.db.Queries().WithTx(tx)
+
+	invitation, err := queries.GetTeamInvitationForUpdate(ctx, database.GetTeamInvitationForUpdateParams{
+		ID:     invitationUUID,
+		TeamID: team.ID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, APIResponse{
+			Success: false,
+			Error:   "Invitation not found",
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Error getting team invitation: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to update invitation",
+		})
+		return
+	}
+	if status != invitationStatusRevoked && invitation.UserID != actorDco.ActorID {
+		c.JSON(http.StatusForbidden, APIResponse{
+			Success: false,
+			Error:   "Only the invited user can respond to an invitation",
+		})
+		return
+	}
+	if invitation.Status != invitationStatusPending {
+		c.JSON(http.StatusConflict, APIResponse{
+			Success: false,
+			Error:   "Invitation is no longer pending",
+		})
+		return
+	}
+
+	_, err = queries.RespondToTeamInvitation(ctx, database.RespondToTeamInvitationParams{
+		ID:     invitation.ID,
+		Status: status,
+	})
+	if err == nil && status == invitationStatusAccepted {
+		_, err = queries.AddTeamMember(ctx, database.AddTeamMemberParams{
+			TeamID: team.ID,
+			UserID: invitation.UserID,
+			Role:   teamRoleMember,
+		})
+	}
+	if err == nil {
+		err = tx.Commit(ctx)
+	}
+	if err != nil {
+		log.Printf("Error updating team invitation: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to update invitation",
+		})
+		return
+	}
+
+	log.Printf("Invitation of %s to team %s %s", invitation.UserID, team.Name, status)
+
+	c.JSON(http.StatusOK, APIResponse{Success: true})
+}
+
+func (s *Server) getUserTeamsHandler(c *gin.Context) {
+	teams, err := s.db.Queries().GetUserTeams(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		log.Printf("Error getting user teams: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to fetch teams",
+		})
+		return
+	}
+
+	response := make([]UserTeamDao, 0, len(teams))
+	for _, team := range teams {
+		response = append(response, UserTeamDao{
+			TeamDao: newTeamDao(database.Team{
+				ID:          team.ID,
+				Name:        team.Name,
+				Description: team.Description,
+				CreatedAt:   team.CreatedAt,
+			}),
+			Role:     team.Role,
+			JoinedAt: team.JoinedAt.Time,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (s *Server) getUserTeamInvitationsHandler(c *gin.Context) {
+	invitations, err := s.db.Queries().GetUserTeamInvitations(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		log.Printf("Error getting user team invitations: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to fetch invitations",
+		})
+		return
+	}
+
+	response := make([]TeamInvitationDao, 0, len(invitations))
+	for _, invitation := range invitations {
+		dao := TeamInvitationDao{
+			ID:        invitation.ID.String(),
+			TeamID:    invitation.TeamID.String(),
+			TeamName:  invitation.TeamName,
+			CreatedAt: invitation.CreatedAt.Time,
+		}
+		if invitation.InvitedBy.Valid {
+			dao.InvitedBy = &invitation.InvitedBy.String
+		}
+		response = append(response, dao)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// getTeamLeaderboardHandler ranks teams within the same windows as the run
+// leaderboards. The rule decides how members' runs are combined: the
+// average of the best n runs, the average of all runs or the total litres.
+func (s *Server) getTeamLeaderboardHandler(c *gin.Context) {
+	window := c.Param("window")
+
+	from, to, ok := s.resolveLeaderboardWindow(c, window)
+	if !ok {
+		return
+	}
+
+	params, err := parseTeamLeaderboardParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Invalid query parameters",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	response := TeamLeaderboardDao{
+		Window:  window,
+		Rule:    string(params.Rule),
+		Metric:  string(params.Metric),
+		Entries: []TeamLeaderboardEntryDao{},
+	}
+	if params.Rule == database.TeamRuleBest {
+		response.BestN = params.BestN
+	}
+	if params.Rule == database.TeamRuleTotal {
+		response.Metric = string(database.LeaderboardMetricVolume)
+	}
+	if params.DivisionID.Valid {
+		response.Division = params.DivisionID.String()
+	}
+	if !from.IsZero() {
+		response.From = &from
+		params.From = pgtype.Timestamp{Time: from.UTC(), Valid: true}
+	}
+	if !to.IsZero() {
+		response.To = &to
+		params.To = pgtype.Timestamp{Time: to.UTC(), Valid: true}
+	}
+
+	rows, err := s.db.Queries().GetTeamLeaderboard(c.Request.Context(), params)
+	if err != nil {
+		log.Printf("Error getting team leaderboard: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to fetch leaderboard",
+		})
+		return
+	}
+	for _, row := range rows {
+		response.Entries = append(response.Entries, TeamLeaderboardEntryDao{
+			Rank:    row.Rank,
+			TeamID:  row.ID.String(),
+			Name:    row.Name,
+			Score:   row.Score,
+			Runs:    row.Runs,
+			Members: row.Members,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func parseTeamLeaderboardParams(c *gin.Context) (database.GetTeamLeaderboardParams, error) {
+	params := database.GetTeamLeaderboardParams{
+		Rule:   database.TeamLeaderboardRule(c.DefaultQuery("rule", string(database.TeamRuleBest))),
+		Metric: database.LeaderboardMetric(c.DefaultQuery("metric", string(database.LeaderboardMetricRate))),
+		BestN:  defaultTeamBestN,
+		Limit:  defaultPageLimit,
+	}
+	if !params.Rule.Valid() {
+		return params, errors.New("rule must be one of best, average, total")
+	}
+	if !params.Metric.Valid() {
+		return params, errors.New("metric must be one of rate, duration, volume")
+	}
+
+	if n := c.Query("n"); n != "" {
+		value, err := strconv.Atoi(n)
+		if err != nil || value < 1 || value > maxTeamBestN {
+			return params, errors.New("n must be between 1 and 50")
+		}
+		params.BestN = int32(value)
+	}
+
+	if division := c.Query("division"); division != "" {
+		if err := params.DivisionID.Scan(division); err != nil {
+			return params, errors.New("division must be a division ID")
+		}
+	}
+
+	if limitStr := c.Query("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > maxPageLimit {
+			return params, errors.New("limit must be between 1 and 200")
+		}
+		params.Limit = int32(limit)
+	}
+
+	return params, nil
+}
+
+// lookupTeam loads the team named by the :id path parameter, writing the
+// error response itself when it cannot.
+func (s *Server) lookupTeam(c *gin.Context) (database.Team, bool) {
+	var teamUUID pgtype.UUID
+	if err := teamUUID.Scan(c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Invalid team ID format",
+		})
+		return database.Team{}, false
+	}
+
+	team, err := s.db.Queries().GetTeam(c.Request.Context(), teamUUID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, APIResponse{
+			Success: false,
+			Error:   "Team not found",
+		})
+		return database.Team{}, false
+	}
+	if err != nil {
+		log.Printf("Error getting team: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to fetch team",
+		})
+		return database.Team{}, false
+	}
+	return team, true
+}
+
+func (s *Server) requireTeamCaptain(c *gin.Context, teamID pgtype.UUID, userID string) bool {
+	member, err := s.db.Queries().GetTeamMember(c.Request.Context(), database.GetTeamMemberParams{
+		TeamID: teamID,
+		UserID: userID,
+	})
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		log.Printf("Error getting team member: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to fetch team",
+		})
+		return false
+	}
+	if err != nil || member.Role != teamRoleCaptain {
+		c.JSON(http.StatusForbidden, APIResponse{
+			Success: false,
+			Error:   "Only team captains can do this",
+		})
+		return false
+	}
+	return true
+}
+
+func (s *Server) requireUser(c *gin.Context, userID string) bool {
+	_, err := s.db.Queries().GetUserById(c.Request.Context(), userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, APIResponse{
+			Success: false,
+			Error:   "User not found",
+		})
+		return false
+	}
+	if err != nil {
+		log.Printf("Error getting user: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to fetch user",
+		})
+		return false
+	}
+	return true
+}
@@ -0,0 +1,961 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/tt-trichter/app/api/internal/database"
+)
+
+const EventBracketUpdated = "bracket-updated"
+
+const (
+	tournamentStatusRegistration = "registration"
+	tournamentStatusRunning      = "running"
+)
+
+// tournamentSeedingLimit caps how many leaderboard ranks are read to seed
+// participants; anyone ranked lower is seeded after the ranked players.
+const tournamentSeedingLimit = 1000
+
+var errMatchNotReady = errors.New("match is not ready for a result")
+
+type TournamentDco struct {
+	Name   string `json:"name" binding:"required"`
+	Format string `json:"format" binding:"required,oneof=single double"`
+	Metric string `json:"metric"`
+}
+
+type TournamentParticipantDco struct {
+	UserID string `json:"userId" binding:"required"`
+}
+
+// MatchResultDco links either a finished duel or one run per player.
+type MatchResultDco struct {
+	DuelID string `json:"duelId"`
+	RunA   string `json:"runA"`
+	RunB   string `json:"runB"`
+}
+
+type TournamentDao struct {
+	ID           string     `json:"id"`
+	Name         string     `json:"name"`
+	Format       string     `json:"format"`
+	Metric       string     `json:"metric"`
+	Status       string     `json:"status"`
+	WinnerUserID *string    `json:"winnerUserId"`
+	StartedAt    *time.Time `json:"startedAt"`
+	FinishedAt   *time.Time `json:"finishedAt"`
+	CreatedAt    time.Time  `json:"createdAt"`
+}
+
+type TournamentParticipantDao struct {
+	UserInfo
+	Seed *int32 `json:"seed"`
+}
+
+type TournamentDetailDao struct {
+	TournamentDao
+	Participants []TournamentParticipantDao `json:"participants"`
+}
+
+type MatchSlotRefDao struct {
+	MatchID string `json:"matchId"`
+	Slot    string `json:"slot"`
+}
+
+type TournamentMatchDao struct {
+	ID         string           `json:"id"`
+	Bracket    string           `json:"bracket"`
+	Round      int32            `json:"round"`
+	Position   int32            `json:"position"`
+	Status     string           `json:"status"`
+	PlayerA    *UserInfo        `json:"playerA"`
+	PlayerB    *UserInfo        `json:"playerB"`
+	Winner     *UserInfo        `json:"winner"`
+	DuelID     *string          `json:"duelId"`
+	RunA       *string          `json:"runA"`
+	RunB       *string          `json:"runB"`
+	WinnerTo   *MatchSlotRefDao `json:"winnerTo"`
+	LoserTo    *MatchSlotRefDao `json:"loserTo"`
+	FinishedAt *time.Time       `json:"finishedAt"`
+}
+
+// BracketDao is the full tree, grouped by bracket and round for rendering.
+type BracketDao struct {
+	Tournament TournamentDao          `json:"tournament"`
+	Winners    [][]TournamentMatchDao `json:"winners"`
+	Losers     [][]TournamentMatchDao `json:"losers"`
+	Final      *TournamentMatchDao    `json:"final"`
+}
+
+type matchResult struct {
+	Winner pgtype.Text
+	DuelID pgtype.UUID
+	RunA   pgtype.UUID
+	RunB   pgtype.UUID
+}
+
+func newTournamentDao(tournament database.Tournament) TournamentDao {
+	dao := TournamentDao{
+		ID:        tournament.ID.String(),
+		Name:      tournament.Name,
+		Format:    tournament.Format,
+		Metric:    tournament.Metric,
+		Status:    tournament.Status,
+		CreatedAt: tournament.CreatedAt.Time,
+	}
+	if tournament.WinnerUserID.Valid {
+		dao.WinnerUserID = &tournament.WinnerUserID.String
+	}
+	if tournament.StartedAt.Valid {
+		dao.StartedAt = &tournament.StartedAt.Time
+	}
+	if tournament.FinishedAt.Valid {
+		dao.FinishedAt = &tournament.FinishedAt.Time
+	}
+	return dao
+}
+
+func (s *Server) listTournamentsHandler(c *gin.Context) {
+	tournaments, err := s.db.Queries().ListTournaments(c.Request.Context())
+	if err != nil {
+		log.Printf("Error listing tournaments: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to fetch tournaments",
+		})
+		return
+	}
+
+	response := make([]TournamentDao, 0, len(tournaments))
+	for _, tournament := range tournaments {
+		response = append(response, newTournamentDao(tournament))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (s *Server) createTournamentHandler(c *gin.Context) {
+	var tournamentDco TournamentDco
+	if err := c.ShouldBindJSON(&tournamentDco); err != nil {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Validation failed",
+			Details: err.Error(),
+		})
+		return
+	}
+	if tournamentDco.Metric == "" {
+		tournamentDco.Metric = string(database.LeaderboardMetricRate)
+	}
+	if !database.LeaderboardMetric(tournamentDco.Metric).Valid() {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Validation failed",
+			Details: "metric must be one of rate, duration, volume",
+		})
+		return
+	}
+
+	tournament, err := s.db.Queries().CreateTournament(c.Request.Context(), database.CreateTournamentParams{
+		Name:   tournamentDco.Name,
+		Format: tournamentDco.Format,
+		Metric: tournamentDco.Metric,
+	})
+	if err != nil {
+		log.Printf("Error creating tournament: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to create tournament",
+		})
+		return
+	}
+
+	log.Printf("Created tournament %s: %s", tournament.ID.String(), tournament.Name)
+
+	c.JSON(http.StatusCreated, newTournamentDao(tournament))
+}
+
+func (s *Server) getTournamentHandler(c *gin.Context) {
+	tournament, ok := s.lookupTournament(c)
+	if !ok {
+		return
+	}
+
+	participants, err := s.db.Queries().GetTournamentParticipants(c.Request.Context(), tournament.ID)
+	if err != nil {
+		log.Printf("Error getting tournament participants: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to fetch tournament",
+		})
+		return
+	}
+
+	response := TournamentDetailDao{
+		TournamentDao: newTournamentDao(tournament),
+		Participants:  make([]TournamentParticipantDao, 0, len(participants)),
+	}
+	for _, participant := range participants {
+		dao := TournamentParticipantDao{
+			UserInfo: UserInfo{
+				ID:       participant.UserID,
+				Name:     participant.UserName,
+				Username: participant.UserUsername,
+			},
+		}
+		if participant.Seed.Valid {
+			dao.Seed = &participant.Seed.Int32
+		}
+		response.Participants = append(response.Participants, dao)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (s *Server) addTournamentParticipantHandler(c *gin.Context) {
+	tournament, ok := s.lookupTournament(c)
+	if !ok {
+		return
+	}
+	if !requireTournamentRegistration(c, tournament) {
+		return
+	}
+
+	var participantDco TournamentParticipantDco
+	if err := c.ShouldBindJSON(&participantDco); err != nil {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Validation failed",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	ctx := c.Request.Context()
+	if _, err := s.db.Queries().GetUserById(ctx, participantDco.UserID); errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, APIResponse{
+			Success: false,
+			Error:   "User not found",
+		})
+		return
+	} else if err != nil {
+		log.Printf("Error getting user: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to register participant",
+		})
+		return
+	}
+
+	if _, err := s.db.Queries().AddTournamentParticipant(ctx, database.AddTournamentParticipantParams{
+		TournamentID: tournament.ID,
+		UserID:       participantDco.UserID,
+	}); err != nil {
+		log.Printf("Error adding tournament participant: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to register participant",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, APIResponse{Success: true})
+}
+
+func (s *Server) removeTournamentParticipantHandler(c *gin.Context) {
+	tournament, ok := s.lookupTournament(c)
+	if !ok {
+		return
+	}
+	if !requireTournamentRegistration(c, tournament) {
+		return
+	}
+
+	removed, err := s.db.Queries().RemoveTournamentParticipant(c.Request.Context(), database.RemoveTournamentParticipantParams{
+		TournamentID: tournament.ID,
+		UserID:       c.Param("userId"),
+	})
+	if err != nil {
+		log.Printf("Error removing tournament participant: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to remove participant",
+		})
+		return
+	}
+	if removed == 0 {
+		c.JSON(http.StatusNotFound, APIResponse{
+			Success: false,
+			Error:   "Participant not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, APIResponse{Success: true})
+}
+
+// startTournamentHandler closes registration, seeds the participants by
+// their leaderboard rank and generates the bracket.
+func (s *Server) startTournamentHandler(c *gin.Context) {
+	var tournamentUUID pgtype.UUID
+	if err := tournamentUUID.Scan(c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Invalid tournament ID format",
+		})
+		return
+	}
+
+	ctx := c.Request.Context()
+	tx, err := s.db.Pool().Begin(ctx)
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to start tournament",
+		})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	queries := s.db.Queries().WithTx(tx)
+
+	tournament, err := queries.GetTournamentForUpdate(ctx, tournamentUUID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, APIResponse{
+			Success: false,
+			Error:   "Tournament not found",
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Error getting tournament: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to start tournament",
+		})
+		return
+	}
+	if !requireTournamentRegistration(c, tournament) {
+		return
+	}
+
+	seeded, err := seedParticipants(ctx, queries, tournament)
+	if err != nil {
+		log.Printf("Error seeding tournament: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to start tournament",
+		})
+		return
+	}
+
+	layout, err := newBracketLayout(tournament.Format, len(seeded))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Cannot start tournament",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	if err := createBracket(ctx, queries, tournament, layout, seeded); err != nil {
+		log.Printf("Error creating bracket: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to start tournament",
+		})
+		return
+	}
+
+	if _, err := queries.StartTournament(ctx, tournament.ID); err != nil {
+		log.Printf("Error starting tournament: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to start tournament",
+		})
+		return
+	}
+
+	// Byes are settled straight away.
+	if err := advanceTournament(ctx, queries, tournament); err != nil {
+		log.Printf("Error advancing tournament: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to start tournament",
+		})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("Error committing tournament start: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to start tournament",
+		})
+		return
+	}
+
+	log.Printf("Started tournament %s with %d participants", tournament.ID.String(), len(seeded))
+
+	bracket, err := s.tournamentBracket(ctx, tournament.ID)
+	if err != nil {
+		log.Printf("Error building bracket: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to fetch bracket",
+		})
+		return
+	}
+	s.events.Publish(EventBracketUpdated, bracket)
+
+	c.JSON(http.StatusOK, bracket)
+}
+
+func (s *Server) getTournamentBracketHandler(c *gin.Context) {
+	tournament, ok := s.lookupTournament(c)
+	if !ok {
+		return
+	}
+
+	bracket, err := s.tournamentBracket(c.Request.Context(), tournament.ID)
+	if err != nil {
+		log.Printf("Error building bracket: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to fetch bracket",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, bracket)
+}
+
+func (s *Server) recordMatchResultHandler(c *gin.Context) {
+	tournament, ok := s.lookupTournament(c)
+	if !ok {
+		return
+	}
+
+	var matchUUID pgtype.UUID
+	if err := matchUUID.Scan(c.Param("matchId")); err != nil {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Invalid match ID format",
+		})
+		return
+	}
+
+	var resultDco MatchResultDco
+	if err := c.ShouldBindJSON(&resultDco); err != nil {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Validation failed",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	ctx := c.Request.Context()
+	match, err := s.db.Queries().GetTournamentMatch(ctx, database.GetTournamentMatchParams{
+		ID:           matchUUID,
+		TournamentID: tournament.ID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, APIResponse{
+			Success: false,
+			Error:   "Match not found",
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Error getting match: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to record result",
+		})
+		return
+	}
+	if match.Status != matchStatusReady {
+		c.JSON(http.StatusConflict, APIResponse{
+			Success: false,
+			Error:   "Match is not ready for a result",
+			Details: match.Status,
+		})
+		return
+	}
+
+	var result matchResult
+	switch {
+	case resultDco.DuelID != "":
+		result, err = s.duelMatchResult(ctx, match, resultDco.DuelID)
+	case resultDco.RunA != "" && resultDco.RunB != "":
+		result, err = s.runsMatchResult(ctx, tournament, match, resultDco.RunA, resultDco.RunB)
+	default:
+		err = errors.New("either duelId or both runA and runB are required")
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Invalid match result",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	err = s.recordMatchResult(ctx, tournament.ID, match.ID, result)
+	if errors.Is(err, errMatchNotReady) {
+		c.JSON(http.StatusConflict, APIResponse{
+			Success: false,
+			Error:   "Match is not ready for a result",
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Error recording match result: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to record result",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, APIResponse{Success: true})
+}
+
+// duelMatchResult takes the result of a finished duel between the two
+// players of a match.
+func (s *Server) duelMatchResult(ctx context.Context, match database.TournamentMatch, duelID string) (matchResult, error) {
+	var duelUUID pgtype.UUID
+	if err := duelUUID.Scan(duelID); err != nil {
+		return matchResult{}, errors.New("invalid duel ID format")
+	}
+
+	duel, err := s.db.Queries().GetDuel(ctx, duelUUID)
+	if err != nil {
+		return matchResult{}, errors.New("duel not found")
+	}
+	if duel.Status != duelStatusFinished || !duel.WinnerRunID.Valid {
+		return matchResult{}, errors.New("duel has no winner")
+	}
+
+	runA, errA := s.db.Queries().GetRunById(ctx, duel.RunA)
+	runB, errB := s.db.Queries().GetRunById(ctx, duel.RunB)
+	if errA != nil || errB != nil {
+		return matchResult{}, errors.New("duel runs not found")
+	}
+
+	result, ok := matchResultFromRuns(match, runA, runB, duel.WinnerRunID)
+	if !ok {
+		return matchResult{}, errors.New("duel was not between the players of this match")
+	}
+	result.DuelID = duel.ID
+	return result, nil
+}
+
+// runsMatchResult compares one run of each player on the tournament metric.
+func (s *Server) runsMatchResult(ctx context.Context, tournament database.Tournament, match database.TournamentMatch, runAID, runBID string) (matchResult, error) {
+	var runAUUID, runBUUID pgtype.UUID
+	if runAUUID.Scan(runAID) != nil || runBUUID.Scan(runBID) != nil {
+		return matchResult{}, errors.New("invalid run ID format")
+	}
+
+	runA, errA := s.db.Queries().GetRunById(ctx, runAUUID)
+	runB, errB := s.db.Queries().GetRunById(ctx, runBUUID)
+	if errA != nil || errB != nil {
+		return matchResult{}, errors.New("run not found")
+	}
+
+	winner := duelWinner(database.LeaderboardMetric(tournament.Metric), runA, runB)
+	if !winner.Valid {
+		return matchResult{}, errors.New("runs are tied, record a rematch")
+	}
+
+	result, ok := matchResultFromRuns(match, runA, runB, winner)
+	if !ok {
+		return matchResult{}, errors.New("runs do not belong to the players of this match")
+	}
+	return result, nil
+}
+
+// matchResultFromRuns maps two runs onto the match slots by their users.
+func matchResultFromRuns(match database.TournamentMatch, a, b database.Run, winnerRunID pgtype.UUID) (matchResult, bool) {
+	if a.UserID == match.PlayerB && b.UserID == match.PlayerA {
+		a, b = b, a
+	}
+	if a.UserID != match.PlayerA || b.UserID != match.PlayerB {
+		return matchResult{}, false
+	}
+
+	result := matchResult{Winner: a.UserID, RunA: a.ID, RunB: b.ID}
+	if winnerRunID == b.ID {
+		result.Winner = b.UserID
+	}
+	return result, true
+}
+
+// recordDuelInTournaments fills ready tournament matches between the two
+// duellists with the duel's result.
+func (s *Server) recordDuelInTournaments(ctx context.Context, duel database.Duel) {
+	if duel.Status != duelStatusFinished || !duel.WinnerRunID.Valid {
+		return
+	}
+
+	runA, errA := s.db.Queries().GetRunById(ctx, duel.RunA)
+	runB, errB := s.db.Queries().GetRunById(ctx, duel.RunB)
+	if errA != nil || errB != nil || !runA.UserID.Valid || !runB.UserID.Valid {
+		return
+	}
+
+	matches, err := s.db.Queries().FindReadyTournamentMatches(ctx, database.FindReadyTournamentMatchesParams{
+		UserA: runA.UserID,
+		UserB: runB.UserID,
+	})
+	if err != nil {
+		log.Printf("Error finding tournament matches for duel: %v", err)
+		return
+	}
+
+	for _, match := range matches {
+		result, ok := matchResultFromRuns(match, runA, runB, duel.WinnerRunID)
+		if !ok {
+			continue
+		}
+		result.DuelID = duel.ID
+		if err := s.recordMatchResult(ctx, match.TournamentID, match.ID, result); err != nil {
+			log.Printf("Error recording duel %s in tournament: %v", duel.ID.String(), err)
+		}
+	}
+}
+
+// recordMatchResult stores a match result, advances the bracket and
+// announces the new state.
+func (s *Server) recordMatchResult(ctx context.Context, tournamentID, matchID pgtype.UUID, result matchResult) error {
+	tx, err := s.db.Pool().Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	queries := s.db.Queries().WithTx(tx)
+
+	tournament, err := queries.GetTournamentForUpdate(ctx, tournamentID)
+	if err != nil {
+		return err
+	}
+	if tournament.Status != tournamentStatusRunning {
+		return errMatchNotReady
+	}
+
+	recorded, err := queries.RecordTournamentMatchResult(ctx, database.RecordTournamentMatchResultParams{
+		ID:           matchID,
+		WinnerUserID: result.Winner,
+		DuelID:       result.DuelID,
+		RunA:         result.RunA,
+		RunB:         result.RunB,
+	})
+	if err != nil {
+		return err
+	}
+	if recorded == 0 {
+		return errMatchNotReady
+	}
+
+	if err := advanceTournament(ctx, queries, tournament); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	log.Printf("Recorded result for match %s: %s won", matchID.String(), result.Winner.String)
+
+	s.rateMatch(ctx, tournamentID, matchID)
+
+	bracket, err := s.tournamentBracket(ctx, tournamentID)
+	if err != nil {
+		log.Printf("Error building bracket: %v", err)
+		return nil
+	}
+	s.events.Publish(EventBracketUpdated, bracket)
+	return nil
+}
+
+// seedParticipants orders the participants by their best-per-user rank on
+// the tournament metric and stores the seeds. Unranked players follow in
+// registration order.
+func seedParticipants(ctx context.Context, queries *database.Queries, tournament database.Tournament) ([]string, error) {
+	participants, err := queries.GetTournamentParticipants(ctx, tournament.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := queries.GetLeaderboard(ctx, database.GetLeaderboardParams{
+		Metric:      database.LeaderboardMetric(tournament.Metric),
+		BestPerUser: true,
+		RequireUser: true,
+		Limit:       tournamentSeedingLimit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	ranks := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		ranks[row.UserID.String] = row.Rank
+	}
+
+	sort.SliceStable(participants, func(i, j int) bool {
+		rankI, rankedI := ranks[participants[i].UserID]
+		rankJ, rankedJ := ranks[participants[j].UserID]
+		if rankedI != rankedJ {
+			return rankedI
+		}
+		return rankI < rankJ
+	})
+
+	seeded := make([]string, 0, len(participants))
+	for i, participant := range participants {
+		if err := queries.SetTournamentSeed(ctx, database.SetTournamentSeedParams{
+			TournamentID: tournament.ID,
+			UserID:       participant.UserID,
+			Seed:         pgtype.Int4{Int32: int32(i + 1), Valid: true},
+		}); err != nil {
+			return nil, err
+		}
+		seeded = append(seeded, participant.UserID)
+	}
+	return seeded, nil
+}
+
+func createBracket(ctx context.Context, queries *database.Queries, tournament database.Tournament, layout bracketLayout, seeded []string) error {
+	firstRound := layout.firstRound(seeded)
+	for _, key := range layout.matches() {
+		players := firstRound[key]
+		if err := queries.CreateTournamentMatch(ctx, database.CreateTournamentMatchParams{
+			TournamentID: tournament.ID,
+			Bracket:      key.Bracket,
+			Round:        int32(key.Round),
+			Position:     int32(key.Position),
+			PlayerA:      pgtype.Text{String: players[0], Valid: players[0] != ""},
+			PlayerB:      pgtype.Text{String: players[1], Valid: players[1] != ""},
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// advanceTournament propagates results through the bracket and finishes the
+// tournament once the final has a winner.
+func advanceTournament(ctx context.Context, queries *database.Queries, tournament database.Tournament) error {
+	matches, err := queries.GetTournamentMatches(ctx, tournament.ID)
+	if err != nil {
+		return err
+	}
+
+	layout := tournamentLayout(tournament, matches)
+	state := make(map[matchKey]*bracketMatch, len(matches))
+	ids := make(map[matchKey]pgtype.UUID, len(matches))
+	for _, match := range matches {
+		key := matchKey{match.Bracket, int(match.Round), int(match.Position)}
+		ids[key] = match.ID
+		state[key] = &bracketMatch{
+			PlayerA: match.PlayerA.String,
+			PlayerB: match.PlayerB.String,
+			Winner:  match.WinnerUserID.String,
+			Status:  match.Status,
+		}
+	}
+
+	for _, key := range layout.resolve(state) {
+		match := state[key]
+		if err := queries.UpdateTournamentMatchState(ctx, database.UpdateTournamentMatchStateParams{
+			ID:           ids[key],
+			PlayerA:      pgtype.Text{String: match.PlayerA, Valid: match.PlayerA != ""},
+			PlayerB:      pgtype.Text{String: match.PlayerB, Valid: match.PlayerB != ""},
+			WinnerUserID: pgtype.Text{String: match.Winner, Valid: match.Winner != ""},
+			Status:       match.Status,
+		}); err != nil {
+			return err
+		}
+	}
+
+	final := state[layout.finalMatch()]
+	if final != nil && final.decided() {
+		return queries.FinishTournament(ctx, database.FinishTournamentParams{
+			ID:           tournament.ID,
+			WinnerUserID: pgtype.Text{String: final.Winner, Valid: final.Winner != ""},
+		})
+	}
+	return nil
+}
+
+// tournamentLayout recovers the layout of a generated bracket from the size
+// of its first round.
+func tournamentLayout(tournament database.Tournament, matches []database.TournamentMatch) bracketLayout {
+	layout := bracketLayout{format: tournament.Format}
+	for _, match := range matches {
+		if match.Bracket == bracketWinners && match.Round == 1 {
+			layout.size += 2
+		}
+	}
+	for size := layout.size; size > 1; size /= 2 {
+		layout.rounds++
+	}
+	return layout
+}
+
+func (s *Server) tournamentBracket(ctx context.Context, tournamentID pgtype.UUID) (BracketDao, error) {
+	tournament, err := s.db.Queries().GetTournament(ctx, tournamentID)
+	if err != nil {
+		return BracketDao{}, err
+	}
+
+	participants, err := s.db.Queries().GetTournamentParticipants(ctx, tournamentID)
+	if err != nil {
+		return BracketDao{}, err
+	}
+	users := make(map[string]UserInfo, len(participants))
+	for _, participant := range participants {
+		users[participant.UserID] = UserInfo{
+			ID:       participant.UserID,
+			Name:     participant.UserName,
+			Username: participant.UserUsername,
+		}
+	}
+	user := func(id pgtype.Text) *UserInfo {
+		if !id.Valid {
+			return nil
+		}
+		info, ok := users[id.String]
+		if !ok {
+			info = UserInfo{ID: id.String}
+		}
+		return &info
+	}
+	optionalID := func(id pgtype.UUID) *string {
+		if !id.Valid {
+			return nil
+		}
+		value := id.String()
+		return &value
+	}
+
+	matches, err := s.db.Queries().GetTournamentMatches(ctx, tournamentID)
+	if err != nil {
+		return BracketDao{}, err
+	}
+
+	layout := tournamentLayout(tournament, matches)
+	winnersTo, losersTo := layout.targets()
+	ids := make(map[matchKey]string, len(matches))
+	for _, match := range matches {
+		ids[matchKey{match.Bracket, int(match.Round), int(match.Position)}] = match.ID.String()
+	}
+	ref := func(targets map[matchKey]slotTarget, key matchKey) *MatchSlotRefDao {
+		target, ok := targets[key]
+		if !ok {
+			return nil
+		}
+		return &MatchSlotRefDao{MatchID: ids[target.Match], Slot: [2]string{"a", "b"}[target.Slot]}
+	}
+
+	response := BracketDao{
+		Tournament: newTournamentDao(tournament),
+		Winners:    [][]TournamentMatchDao{},
+		Losers:     [][]TournamentMatchDao{},
+	}
+	for _, match := range matches {
+		key := matchKey{match.Bracket, int(match.Round), int(match.Position)}
+		dao := TournamentMatchDao{
+			ID:       match.ID.String(),
+			Bracket:  match.Bracket,
+			Round:    match.Round,
+			Position: match.Position,
+			Status:   match.Status,
+			PlayerA:  user(match.PlayerA),
+			PlayerB:  user(match.PlayerB),
+			Winner:   user(match.WinnerUserID),
+			DuelID:   optionalID(match.DuelID),
+			RunA:     optionalID(match.RunA),
+			RunB:     optionalID(match.RunB),
+			WinnerTo: ref(winnersTo, key),
+			LoserTo:  ref(losersTo, key),
+		}
+		if match.FinishedAt.Valid {
+			dao.FinishedAt = &match.FinishedAt.Time
+		}
+
+		switch match.Bracket {
+		case bracketWinners:
+			response.Winners = appendToRound(response.Winners, int(match.Round), dao)
+		case bracketLosers:
+			response.Losers = appendToRound(response.Losers, int(match.Round), dao)
+		case bracketFinal:
+			response.Final = &dao
+		}
+	}
+
+	return response, nil
+}
+
+func appendToRound(rounds [][]TournamentMatchDao, round int, match TournamentMatchDao) [][]TournamentMatchDao {
+	for len(rounds) < round {
+		rounds = append(rounds, []TournamentMatchDao{})
+	}
+	rounds[round-1] = append(rounds[round-1], match)
+	return rounds
+}
+
+// lookupTournament loads the tournament named by the :id path parameter,
+// writing the error response itself when it cannot.
+func (s *Server) lookupTournament(c *gin.Context) (database.Tournament, bool) {
+	var tournamentUUID pgtype.UUID
+	if err := tournamentUUID.Scan(c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Invalid tournament ID format",
+		})
+		return database.Tournament{}, false
+	}
+
+	tournament, err := s.db.Queries().GetTournament(c.Request.Context(), tournamentUUID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, APIResponse{
+			Success: false,
+			Error:   "Tournament not found",
+		})
+		return database.Tournament{}, false
+	}
+	if err != nil {
+		log.Printf("Error getting tournament: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to fetch tournament",
+		})
+		return database.Tournament{}, false
+	}
+	return tournament, true
+}
+
+func requireTournamentRegistration(c *gin.Context, tournament database.Tournament) bool {
+	if tournament.Status != tournamentStatusRegistration {
+		c.JSON(http.StatusConflict, APIResponse{
+			Success: false,
+			Error:   "Tournament registration is closed",
+		})
+		return false
+	}
+	return true
+}
@@ -1,11 +1,15 @@
 package server
 
 import (
+	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/tt-trichter/app/api/internal/database"
 )
@@ -16,6 +20,34 @@ type UserInfo struct {
 	Username string `json:"username"`
 }
 
+type UserProfileDao struct {
+	ID              string            `json:"id"`
+	Name            string            `json:"name"`
+	Username        string            `json:"username"`
+	DisplayUsername string            `json:"displayUsername"`
+	Image           *string           `json:"image"`
+	CreatedAt       time.Time         `json:"createdAt"`
+	RecentRuns      []RunDao          `json:"recentRuns"`
+	Championships   []ChampionshipDao `json:"championships"`
+}
+
+type UserStatsDao struct {
+	RunCount        int64             `json:"runCount"`
+	BestRate        float64           `json:"bestRate"`
+	MedianRate      float64           `json:"medianRate"`
+	MeanRate        float64           `json:"meanRate"`
+	FastestDuration float64           `json:"fastestDuration"`
+	TotalVolume     float64           `json:"totalVolume"`
+	FirstRunAt      *time.Time        `json:"firstRunAt"`
+	LastRunAt       *time.Time        `json:"lastRunAt"`
+	Rank            *int64            `json:"rank"`
+	CurrentStreak   int               `json:"currentStreak"`
+	LongestStreak   int               `json:"longestStreak"`
+	Championships   []ChampionshipDao `json:"championships"`
+}
+
+const defaultRecentRuns = 5
+
 func (s *Server) searchUsersHandler(c *gin.Context) {
 	name := c.Query("name")
 	limitStr := c.DefaultQuery("limit", "10")
@@ -54,3 +86,178 @@ func (s *Server) searchUsersHandler(c *gin.Context) {
 
 	c.JSON(http.StatusOK, response)
 }
+
+func (s *Server) getUserHandler(c *gin.Context) {
+	userID := c.Param("id")
+
+	recent, err := strconv.Atoi(c.DefaultQuery("recent", strconv.Itoa(defaultRecentRuns)))
+	if err != nil || recent < 0 || recent > maxPageLimit {
+		c.JSON(http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Invalid recent parameter",
+		})
+		return
+	}
+
+	user, err := s.db.Queries().GetUserProfile(c.Request.Context(), userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, APIResponse{
+			Success: false,
+			Error:   "User not found",
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Error getting user: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to fetch user",
+		})
+		return
+	}
+
+	runs, err := s.db.Queries().GetRecentRunsForUser(c.Request.Context(), database.GetRecentRunsForUserParams{
+		UserID: pgtype.Text{String: userID, Valid: true},
+		Limit:  int32(recent),
+	})
+	if err != nil {
+		log.Printf("Error getting recent runs: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to fetch user",
+		})
+		return
+	}
+
+	championships, err := s.userChampionships(c.Request.Context(), userID)
+	if err != nil {
+		log.Printf("Error getting championships: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to fetch user",
+		})
+		return
+	}
+
+	userInfo := &UserInfo{
+		ID:       user.ID,
+		Name:     user.Name,
+		Username: user.Username,
+	}
+
+	response := UserProfileDao{
+		ID:              user.ID,
+		Name:            user.Name,
+		Username:        user.Username,
+		DisplayUsername: user.DisplayUsername,
+		CreatedAt:       user.CreatedAt.Time,
+		RecentRuns:      []RunDao{},
+		Championships:   championships,
+	}
+	if user.Image.Valid {
+		response.Image = &user.Image.String
+	}
+
+	for _, run := range runs {
+		runDao := RunDao{
+			ID:        run.ID.String(),
+			Image:     run.Image,
+			CreatedAt: run.CreatedAt.Time,
+			User:      userInfo,
+		}
+		if err := json.Unmarshal(run.Data, &runDao.Data); err != nil {
+			log.Printf("Error unmarshaling run data: %v", err)
+			continue
+		}
+		response.RecentRuns = append(response.RecentRuns, runDao)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (s *Server) getUserStatsHandler(c *gin.Context) {
+	userID := c.Param("id")
+	ctx := c.Request.Context()
+
+	if _, err := s.db.Queries().GetUserById(ctx, userID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, APIResponse{
+				Success: false,
+				Error:   "User not found",
+			})
+			return
+		}
+		log.Printf("Error getting user: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to fetch user stats",
+		})
+		return
+	}
+
+	userIDText := pgtype.Text{String: userID, Valid: true}
+
+	stats, err := s.db.Queries().GetUserRunStats(ctx, userIDText)
+	if err != nil {
+		log.Printf("Error getting user run stats: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to fetch user stats",
+		})
+		return
+	}
+
+	championships, err := s.userChampionships(ctx, userID)
+	if err != nil {
+		log.Printf("Error getting championships: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to fetch user stats",
+		})
+		return
+	}
+
+	response := UserStatsDao{
+		RunCount:        stats.RunCount,
+		BestRate:        stats.BestRate,
+		MedianRate:      stats.MedianRate,
+		MeanRate:        stats.MeanRate,
+		FastestDuration: stats.FastestDuration,
+		TotalVolume:     stats.TotalVolume,
+		Championships:   championships,
+	}
+
+	if stats.RunCount > 0 {
+		response.FirstRunAt = &stats.FirstRunAt.Time
+		response.LastRunAt = &stats.LastRunAt.Time
+
+		rank, err := s.db.Queries().GetUserRank(ctx, userIDText)
+		if err != nil {
+			log.Printf("Error getting user rank: %v", err)
+			c.JSON(http.StatusInternalServerError, APIResponse{
+				Success: false,
+				Error:   "Failed to fetch user stats",
+			})
+			return
+		}
+		response.Rank = &rank
+
+		runTimes, err := s.db.Queries().GetRunTimesForUser(ctx, userIDText)
+		if err != nil {
+			log.Printf("Error getting run times: %v", err)
+			c.JSON(http.StatusInternalServerError, APIResponse{
+				Success: false,
+				Error:   "Failed to fetch user stats",
+			})
+			return
+		}
+
+		times := make([]time.Time, 0, len(runTimes))
+		for _, t := range runTimes {
+			times = append(times, t.Time)
+		}
+		response.CurrentStreak, response.LongestStreak = s.nights.streaks(times, time.Now())
+	}
+
+	c.JSON(http.StatusOK, response)
+}
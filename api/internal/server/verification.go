@@ -0,0 +1,340 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/tt-trichter/app/api/internal/database"
+	"github.com/tt-trichter/app/api/internal/samples"
+)
+
+const (
+	runVerdictConsistent = "consistent"
+	runVerdictCorrected  = "corrected"
+	runVerdictFlagged    = "flagged"
+)
+
+const defaultMetricTolerance = 0.1
+
+// metricCheck decides what happens to a run whose reported figures disagree
+// with its flow samples by more than the relative tolerance: either the
+// derived figures replace the reported ones or the run is flagged.
+type metricCheck struct {
+	tolerance float64
+	correct   bool
+}
+
+// metricCheckFromEnv reads RUN_METRIC_TOLERANCE, e.g. 0.1 for 10%, and
+// RUN_METRIC_POLICY, which is flag (the default) or correct.
+func metricCheckFromEnv() metricCheck {
+	check := metricCheck{tolerance: defaultMetricTolerance}
+	if value := os.Getenv("RUN_METRIC_TOLERANCE"); value != "" {
+		tolerance, err := strconv.ParseFloat(value, 64)
+		if err != nil || tolerance <= 0 {
+			log.Printf("Invalid RUN_METRIC_TOLERANCE %q, falling back to %g", value, defaultMetricTolerance)
+		} else {
+			check.tolerance = tolerance
+		}
+	}
+	switch policy := os.Getenv("RUN_METRIC_POLICY"); policy {
+	case "", "flag":
+	case "correct":
+		check.correct = true
+	default:
+		log.Printf("Invalid RUN_METRIC_POLICY %q, falling back to flag", policy)
+	}
+	return check
+}
+
+// RunVerificationDao sets the reported figures of a run next to the ones
+// derived from its samples. Deviations are relative; null means the device
+// reported something the samples do not show at all.
+type RunVerificationDao struct {
+	Verdict    string              `json:"verdict"`
+	Tolerance  float64             `json:"tolerance"`
+	Reported   RunData             `json:"reported"`
+	Derived    samples.Metrics     `json:"derived"`
+	Deviations map[string]*float64 `json:"deviations"`
+}
+
+type FlaggedRunDao struct {
+	RunDao
+	Reported RunData         `json:"reported"`
+	Derived  samples.Metrics `json:"derived"`
+}
+
+func runDeviations(reported RunData, derived samples.Metrics) map[string]float64 {
+	return map[string]float64{
+		"duration": samples.Deviation(float64(reported.Duration), derived.Duration),
+		"volume":   samples.Deviation(float64(reported.Volume), derived.Volume),
+		"rate":     samples.Deviation(float64(reported.Rate), derived.AverageFlow),
+	}
+}
+
+func (m metricCheck) consistent(deviations map[string]float64) bool {
+	for _, deviation := range deviations {
+		if deviation > m.tolerance {
+			return false
+		}
+	}
+	return true
+}
+
+// verifyRun checks a run's reported figures against its samples once they
+// are stored. Depending on the policy a disagreeing run is corrected, with
+// the reported figures kept as a revision, or flagged and held for review.
+// A run is also held instead of corrected when the derived figures are not
+// plausible.
+// It returns the run as stored afterwards and whether its data changed. Runs
+// without samples or that were verified before are returned unchanged.
+func (s *Server) verifyRun(ctx context.Context, run database.Run) (database.Run, bool) {
+	tx, err := s.db.Pool().Begin(ctx)
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return run, false
+	}
+	defer tx.Rollback(ctx)
+
+	queries := s.db.Queries().WithTx(tx)
+
+	locked, err := queries.GetRunForUpdate(ctx, run.ID)
+	if err != nil {
+		log.Printf("Error getting run for verification: %v", err)
+		return run, false
+	}
+
+	stored, err := queries.GetRunSamples(ctx, run.ID)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && stored.Verdict.Valid) {
+		return locked, false
+	}
+	if err != nil {
+		log.Printf("Error getting run samples: %v", err)
+		return run, false
+	}
+
+	curve, err := samples.Decode(stored.Data)
+	if err != nil {
+		log.Printf("Error decoding samples of run %s: %v", run.ID.String(), err)
+		return run, false
+	}
+
+	var reported RunData
+	if err := json.Unmarshal(locked.Data, &reported); err != nil {
+		log.Printf("Error unmarshaling run data: %v", err)
+		return run, false
+	}
+
+	derived := samples.Derive(curve)
+	deviations := runDeviations(reported, derived)
+
+	verdict := runVerdictConsistent
+	verified := locked
+	switch {
+	case s.metricCheck.consistent(deviations):
+	case s.metricCheck.correct && derived.Duration > 0:
+		var problems []string
+		problems, err = s.derivedFigureProblems(ctx, locked, derived)
+		switch {
+		case err != nil:
+		case len(problems) > 0:
+			verdict = runVerdictFlagged
+			verified, err = queries.HoldRunForReview(ctx, database.HoldRunForReviewParams{
+				ID:     run.ID,
+				Reason: "figures derived from the flow samples are not plausible: " + strings.Join(problems, "; "),
+			})
+		default:
+			verdict = runVerdictCorrected
+			verified, err = correctRunFromSamples(ctx, queries, locked, derived)
+		}
+	default:
+		verdict = runVerdictFlagged
+		verified, err = queries.HoldRunForReview(ctx, database.HoldRunForReviewParams{
+			ID:     run.ID,
+			Reason: "reported figures disagree with the flow samples",
+		})
+	}
+
+	var metrics []byte
+	if err == nil {
+		metrics, err = json.Marshal(derived)
+	}
+	if err == nil {
+		err = queries.SaveRunVerification(ctx, database.SaveRunVerificationParams{
+			RunID:    run.ID,
+			Metrics:  metrics,
+			Reported: locked.Data,
+			Verdict:  pgtype.Text{String: verdict, Valid: true},
+		})
+	}
+	if err == nil {
+		err = tx.Commit(ctx)
+	}
+	if err != nil {
+		log.Printf("Error verifying run %s: %v", run.ID.String(), err)
+		return run, false
+	}
+
+	if verdict != runVerdictConsistent {
+		log.Printf("Run %s %s: reported %+v, derived %+v", run.ID.String(), verdict, reported, derived)
+	}
+
+	return verified, verdict == runVerdictCorrected
+}
+
+// derivedFigureProblems runs the plausibility checks on the figures derived
+// from a run's samples before they replace the reported ones, so a glitched
+// curve cannot put an impossible rate on a run.
+func (s *Server) derivedFigureProblems(ctx context.Context, run database.Run, derived samples.Metrics) ([]string, error) {
+	check, err := s.checkPlausibility(ctx, RunDco{
+		Duration: float32(derived.Duration),
+		Rate:     float32(derived.AverageFlow),
+		Volume:   float32(derived.Volume),
+		DeviceID: run.DeviceID.String,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append(check.Impossible, check.Suspicious...), nil
+}
+
+// correctRunFromSamples replaces a run's figures with the derived ones,
+// keeping the reported figures as a revision like a manual correction.
+func correctRunFromSamples(ctx context.Context, queries *database.Queries, run database.Run, derived samples.Metrics) (database.Run, error) {
+	data, err := json.Marshal(RunData{
+		Duration: float32(derived.Duration),
+		Rate:     float32(derived.AverageFlow),
+		Volume:   float32(derived.Volume),
+	})
+	if err != nil {
+		return run, err
+	}
+
+	if _, err := queries.CreateRunRevision(ctx, database.CreateRunRevisionParams{
+		RunID:    run.ID,
+		Data:     run.Data,
+		Reason:   "Derived from flow samples",
+		EditedBy: "samples",
+	}); err != nil {
+		return run, err
+	}
+
+	if _, err := queries.UpdateRunData(ctx, database.UpdateRunDataParams{
+		ID:   run.ID,
+		Data: data,
+	}); err != nil {
+		return run, err
+	}
+
+	// A corrected volume may move the run into another division, so the run
+	// is read back once it is reclassified.
+	if _, err := queries.AssignRunsToDivisions(ctx, run.ID); err != nil {
+		return run, err
+	}
+
+	return queries.GetRunById(ctx, run.ID)
+}
+
+// runVerification describes the verification stored with a run's samples,
+// or returns nil when the run has not been verified.
+func (s *Server) runVerification(stored database.RunSample) *RunVerificationDao {
+	if !stored.Verdict.Valid {
+		return nil
+	}
+
+	verification := RunVerificationDao{
+		Verdict:    stored.Verdict.String,
+		Tolerance:  s.metricCheck.tolerance,
+		Deviations: map[string]*float64{},
+	}
+	if err := json.Unmarshal(stored.Reported, &verification.Reported); err != nil {
+		log.Printf("Error unmarshaling reported run data: %v", err)
+		return nil
+	}
+	if err := json.Unmarshal(stored.Metrics, &verification.Derived); err != nil {
+		log.Printf("Error unmarshaling derived run metrics: %v", err)
+		return nil
+	}
+
+	for metric, deviation := range runDeviations(verification.Reported, verification.Derived) {
+		if math.IsInf(deviation, 0) {
+			verification.Deviations[metric] = nil
+			continue
+		}
+		verification.Deviations[metric] = &deviation
+	}
+	return &verification
+}
+
+// getFlaggedRunsHandler lists runs whose reported figures disagree with
+// their samples, newest first, for an admin to review.
+func (s *Server) getFlaggedRunsHandler(c *gin.Context) {
+	limit := defaultPageLimit
+	if limitStr := c.Query("limit"); limitStr != "" {
+		value, err := strconv.Atoi(limitStr)
+		if err != nil || value < 1 || value > maxPageLimit {
+			c.JSON(http.StatusBadRequest, APIResponse{
+				Success: false,
+				Error:   "Invalid query parameters",
+				Details: "limit must be between 1 and 200",
+			})
+			return
+		}
+		limit = value
+	}
+
+	runs, err := s.db.Queries().GetRunsByVerdict(c.Request.Context(), database.GetRunsByVerdictParams{
+		Verdict: pgtype.Text{String: runVerdictFlagged, Valid: true},
+		Limit:   int32(limit),
+	})
+	if err != nil {
+		log.Printf("Error getting flagged runs: %v", err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to fetch flagged runs",
+		})
+		return
+	}
+
+	response := make([]FlaggedRunDao, 0, len(runs))
+	for _, run := range runs {
+		flagged := FlaggedRunDao{
+			RunDao: RunDao{
+				ID:        run.ID.String(),
+				Image:     run.Image,
+				CreatedAt: run.CreatedAt.Time,
+			},
+		}
+		if err := json.Unmarshal(run.Data, &flagged.Data); err != nil {
+			log.Printf("Error unmarshaling run data: %v", err)
+			continue
+		}
+		if err := json.Unmarshal(run.Reported, &flagged.Reported); err != nil {
+			log.Printf("Error unmarshaling reported run data: %v", err)
+			continue
+		}
+		if err := json.Unmarshal(run.Metrics, &flagged.Derived); err != nil {
+			log.Printf("Error unmarshaling derived run metrics: %v", err)
+			continue
+		}
+		if run.UserName.Valid {
+			flagged.User = &UserInfo{
+				ID:       run.UserIDFull.String,
+				Name:     run.UserName.String,
+				Username: run.UserUsername.String,
+			}
+		}
+		response = append(response, flagged)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
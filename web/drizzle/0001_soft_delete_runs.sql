@@ -0,0 +1,2 @@
+ALTER TABLE "runs" ADD COLUMN "deleted_at" timestamp;--> statement-breakpoint
+CREATE INDEX "runs_deleted_at_idx" ON "runs" USING btree ("deleted_at");
\ No newline at end of file
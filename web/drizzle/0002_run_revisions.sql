@@ -0,0 +1,11 @@
+CREATE TABLE "run_revisions" (
+	"id" uuid PRIMARY KEY DEFAULT gen_random_uuid() NOT NULL,
+	"run_id" uuid NOT NULL,
+	"data" jsonb NOT NULL,
+	"reason" text NOT NULL,
+	"edited_by" text NOT NULL,
+	"created_at" timestamp NOT NULL
+);
+--> statement-breakpoint
+ALTER TABLE "run_revisions" ADD CONSTRAINT "run_revisions_run_id_runs_id_fk" FOREIGN KEY ("run_id") REFERENCES "public"."runs"("id") ON DELETE cascade ON UPDATE no action;--> statement-breakpoint
+CREATE INDEX "run_revisions_run_id_idx" ON "run_revisions" USING btree ("run_id","created_at");
\ No newline at end of file
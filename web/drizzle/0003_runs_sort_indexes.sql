@@ -0,0 +1,5 @@
+CREATE INDEX "runs_rate_idx" ON "runs" USING btree ((("data"->>'rate')::float) desc,"id" DESC NULLS FIRST) WHERE "runs"."deleted_at" IS NULL;--> statement-breakpoint
+CREATE INDEX "runs_duration_idx" ON "runs" USING btree ((("data"->>'duration')::float) desc,"id" DESC NULLS FIRST) WHERE "runs"."deleted_at" IS NULL;--> statement-breakpoint
+CREATE INDEX "runs_volume_idx" ON "runs" USING btree ((("data"->>'volume')::float) desc,"id" DESC NULLS FIRST) WHERE "runs"."deleted_at" IS NULL;--> statement-breakpoint
+CREATE INDEX "runs_created_at_idx" ON "runs" USING btree ("created_at" DESC NULLS FIRST,"id" DESC NULLS FIRST) WHERE "runs"."deleted_at" IS NULL;--> statement-breakpoint
+CREATE INDEX "runs_user_id_created_at_idx" ON "runs" USING btree ("user_id","created_at" DESC NULLS FIRST) WHERE "runs"."deleted_at" IS NULL;
\ No newline at end of file
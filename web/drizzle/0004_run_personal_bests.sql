@@ -0,0 +1,2 @@
+ALTER TABLE "runs" ADD COLUMN "personal_bests" text[] DEFAULT '{}' NOT NULL;--> statement-breakpoint
+ALTER TABLE "runs" ADD COLUMN "records" text[] DEFAULT '{}' NOT NULL;
\ No newline at end of file
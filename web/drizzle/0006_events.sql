@@ -0,0 +1,15 @@
+CREATE TABLE "events" (
+	"id" uuid PRIMARY KEY DEFAULT gen_random_uuid() NOT NULL,
+	"name" text NOT NULL,
+	"venue" text,
+	"starts_at" timestamp NOT NULL,
+	"ends_at" timestamp,
+	"device_ids" text[] DEFAULT '{}' NOT NULL,
+	"created_at" timestamp NOT NULL
+);
+--> statement-breakpoint
+ALTER TABLE "runs" ADD COLUMN "device_id" text;--> statement-breakpoint
+ALTER TABLE "runs" ADD COLUMN "event_id" uuid;--> statement-breakpoint
+ALTER TABLE "runs" ADD CONSTRAINT "runs_event_id_events_id_fk" FOREIGN KEY ("event_id") REFERENCES "public"."events"("id") ON DELETE set null ON UPDATE no action;--> statement-breakpoint
+CREATE INDEX "runs_event_id_idx" ON "runs" USING btree ("event_id") WHERE "runs"."deleted_at" IS NULL;--> statement-breakpoint
+CREATE INDEX "events_starts_at_idx" ON "events" USING btree ("starts_at");
\ No newline at end of file
@@ -0,0 +1,25 @@
+CREATE TABLE "seasons" (
+	"id" uuid PRIMARY KEY DEFAULT gen_random_uuid() NOT NULL,
+	"name" text NOT NULL,
+	"starts_at" timestamp NOT NULL,
+	"ends_at" timestamp NOT NULL,
+	"closed_at" timestamp,
+	"created_at" timestamp NOT NULL
+);
+--> statement-breakpoint
+CREATE TABLE "season_standings" (
+	"season_id" uuid NOT NULL,
+	"metric" text NOT NULL,
+	"rank" integer NOT NULL,
+	"user_id" text NOT NULL,
+	"user_name" text NOT NULL,
+	"user_username" text NOT NULL,
+	"run_id" uuid NOT NULL,
+	"score" double precision NOT NULL,
+	"recorded_at" timestamp NOT NULL,
+	CONSTRAINT "season_standings_season_id_metric_user_id_pk" PRIMARY KEY("season_id","metric","user_id")
+);
+--> statement-breakpoint
+ALTER TABLE "season_standings" ADD CONSTRAINT "season_standings_season_id_seasons_id_fk" FOREIGN KEY ("season_id") REFERENCES "public"."seasons"("id") ON DELETE restrict ON UPDATE no action;--> statement-breakpoint
+CREATE INDEX "seasons_starts_at_idx" ON "seasons" USING btree ("starts_at","ends_at");--> statement-breakpoint
+CREATE INDEX "season_standings_user_id_idx" ON "season_standings" USING btree ("user_id","rank");
\ No newline at end of file
@@ -0,0 +1,18 @@
+CREATE TABLE "scoring_formulas" (
+	"name" text PRIMARY KEY NOT NULL,
+	"expression" text NOT NULL,
+	"description" text,
+	"created_at" timestamp NOT NULL,
+	"updated_at" timestamp NOT NULL
+);
+--> statement-breakpoint
+CREATE TABLE "run_scores" (
+	"run_id" uuid NOT NULL,
+	"formula" text NOT NULL,
+	"score" double precision NOT NULL,
+	CONSTRAINT "run_scores_run_id_formula_pk" PRIMARY KEY("run_id","formula")
+);
+--> statement-breakpoint
+ALTER TABLE "run_scores" ADD CONSTRAINT "run_scores_run_id_runs_id_fk" FOREIGN KEY ("run_id") REFERENCES "public"."runs"("id") ON DELETE cascade ON UPDATE no action;--> statement-breakpoint
+ALTER TABLE "run_scores" ADD CONSTRAINT "run_scores_formula_scoring_formulas_name_fk" FOREIGN KEY ("formula") REFERENCES "public"."scoring_formulas"("name") ON DELETE cascade ON UPDATE no action;--> statement-breakpoint
+CREATE INDEX "run_scores_formula_score_idx" ON "run_scores" USING btree ("formula","score" DESC NULLS FIRST);
\ No newline at end of file
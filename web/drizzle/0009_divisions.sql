@@ -0,0 +1,12 @@
+CREATE TABLE "divisions" (
+	"id" uuid PRIMARY KEY DEFAULT gen_random_uuid() NOT NULL,
+	"name" text NOT NULL,
+	"volume" double precision NOT NULL,
+	"tolerance" double precision NOT NULL,
+	"handicap" double precision DEFAULT 1 NOT NULL,
+	"created_at" timestamp NOT NULL
+);
+--> statement-breakpoint
+ALTER TABLE "runs" ADD COLUMN "division_id" uuid;--> statement-breakpoint
+ALTER TABLE "runs" ADD CONSTRAINT "runs_division_id_divisions_id_fk" FOREIGN KEY ("division_id") REFERENCES "public"."divisions"("id") ON DELETE set null ON UPDATE no action;--> statement-breakpoint
+CREATE INDEX "runs_division_id_idx" ON "runs" USING btree ("division_id") WHERE "runs"."deleted_at" IS NULL;
\ No newline at end of file
@@ -0,0 +1,19 @@
+CREATE TABLE "duels" (
+	"id" uuid PRIMARY KEY DEFAULT gen_random_uuid() NOT NULL,
+	"metric" text NOT NULL,
+	"device_a" text NOT NULL,
+	"device_b" text NOT NULL,
+	"run_a" uuid,
+	"run_b" uuid,
+	"winner_run_id" uuid,
+	"status" text NOT NULL,
+	"started_at" timestamp NOT NULL,
+	"finished_at" timestamp
+);
+--> statement-breakpoint
+ALTER TABLE "duels" ADD CONSTRAINT "duels_run_a_runs_id_fk" FOREIGN KEY ("run_a") REFERENCES "public"."runs"("id") ON DELETE set null ON UPDATE no action;--> statement-breakpoint
+ALTER TABLE "duels" ADD CONSTRAINT "duels_run_b_runs_id_fk" FOREIGN KEY ("run_b") REFERENCES "public"."runs"("id") ON DELETE set null ON UPDATE no action;--> statement-breakpoint
+ALTER TABLE "duels" ADD CONSTRAINT "duels_winner_run_id_runs_id_fk" FOREIGN KEY ("winner_run_id") REFERENCES "public"."runs"("id") ON DELETE set null ON UPDATE no action;--> statement-breakpoint
+CREATE INDEX "duels_status_idx" ON "duels" USING btree ("status","started_at" DESC NULLS FIRST);--> statement-breakpoint
+CREATE INDEX "duels_run_a_idx" ON "duels" USING btree ("run_a");--> statement-breakpoint
+CREATE INDEX "duels_run_b_idx" ON "duels" USING btree ("run_b");
\ No newline at end of file
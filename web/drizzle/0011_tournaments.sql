@@ -0,0 +1,42 @@
+CREATE TABLE "tournaments" (
+	"id" uuid PRIMARY KEY DEFAULT gen_random_uuid() NOT NULL,
+	"name" text NOT NULL,
+	"format" text NOT NULL,
+	"metric" text NOT NULL,
+	"status" text NOT NULL,
+	"winner_user_id" text,
+	"started_at" timestamp,
+	"finished_at" timestamp,
+	"created_at" timestamp NOT NULL
+);
+--> statement-breakpoint
+CREATE TABLE "tournament_participants" (
+	"tournament_id" uuid NOT NULL,
+	"user_id" text NOT NULL,
+	"seed" integer,
+	"created_at" timestamp NOT NULL,
+	CONSTRAINT "tournament_participants_tournament_id_user_id_pk" PRIMARY KEY("tournament_id","user_id")
+);
+--> statement-breakpoint
+CREATE TABLE "tournament_matches" (
+	"id" uuid PRIMARY KEY DEFAULT gen_random_uuid() NOT NULL,
+	"tournament_id" uuid NOT NULL,
+	"bracket" text NOT NULL,
+	"round" integer NOT NULL,
+	"position" integer NOT NULL,
+	"player_a" text,
+	"player_b" text,
+	"winner_user_id" text,
+	"status" text NOT NULL,
+	"duel_id" uuid,
+	"run_a" uuid,
+	"run_b" uuid,
+	"finished_at" timestamp,
+	CONSTRAINT "tournament_matches_tournament_id_bracket_round_position_unique" UNIQUE("tournament_id","bracket","round","position")
+);
+--> statement-breakpoint
+ALTER TABLE "tournament_participants" ADD CONSTRAINT "tournament_participants_tournament_id_tournaments_id_fk" FOREIGN KEY ("tournament_id") REFERENCES "public"."tournaments"("id") ON DELETE cascade ON UPDATE no action;--> statement-breakpoint
+ALTER TABLE "tournament_participants" ADD CONSTRAINT "tournament_participants_user_id_user_id_fk" FOREIGN KEY ("user_id") REFERENCES "public"."user"("id") ON DELETE cascade ON UPDATE no action;--> statement-breakpoint
+ALTER TABLE "tournament_matches" ADD CONSTRAINT "tournament_matches_tournament_id_tournaments_id_fk" FOREIGN KEY ("tournament_id") REFERENCES "public"."tournaments"("id") ON DELETE cascade ON UPDATE no action;--> statement-breakpoint
+ALTER TABLE "tournament_matches" ADD CONSTRAINT "tournament_matches_duel_id_duels_id_fk" FOREIGN KEY ("duel_id") REFERENCES "public"."duels"("id") ON DELETE set null ON UPDATE no action;--> statement-breakpoint
+CREATE INDEX "tournament_matches_players_idx" ON "tournament_matches" USING btree ("player_a","player_b") WHERE "tournament_matches"."status" = 'ready';
\ No newline at end of file
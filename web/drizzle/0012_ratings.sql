@@ -0,0 +1,23 @@
+CREATE TABLE "user_ratings" (
+	"user_id" text PRIMARY KEY NOT NULL,
+	"rating" double precision NOT NULL,
+	"games" integer NOT NULL,
+	"last_played_at" timestamp NOT NULL,
+	"updated_at" timestamp NOT NULL
+);
+--> statement-breakpoint
+CREATE TABLE "rating_history" (
+	"id" uuid PRIMARY KEY DEFAULT gen_random_uuid() NOT NULL,
+	"user_id" text NOT NULL,
+	"opponent_id" text NOT NULL,
+	"source" text NOT NULL,
+	"source_id" uuid NOT NULL,
+	"score" double precision NOT NULL,
+	"rating_before" double precision NOT NULL,
+	"rating_after" double precision NOT NULL,
+	"played_at" timestamp NOT NULL
+);
+--> statement-breakpoint
+ALTER TABLE "user_ratings" ADD CONSTRAINT "user_ratings_user_id_user_id_fk" FOREIGN KEY ("user_id") REFERENCES "public"."user"("id") ON DELETE cascade ON UPDATE no action;--> statement-breakpoint
+ALTER TABLE "rating_history" ADD CONSTRAINT "rating_history_user_id_user_id_fk" FOREIGN KEY ("user_id") REFERENCES "public"."user"("id") ON DELETE cascade ON UPDATE no action;--> statement-breakpoint
+CREATE INDEX "rating_history_user_id_idx" ON "rating_history" USING btree ("user_id","played_at" DESC NULLS FIRST);
\ No newline at end of file
@@ -0,0 +1,8 @@
+CREATE TABLE "run_samples" (
+	"run_id" uuid PRIMARY KEY NOT NULL,
+	"sample_count" integer NOT NULL,
+	"data" bytea NOT NULL,
+	"created_at" timestamp NOT NULL
+);
+--> statement-breakpoint
+ALTER TABLE "run_samples" ADD CONSTRAINT "run_samples_run_id_runs_id_fk" FOREIGN KEY ("run_id") REFERENCES "public"."runs"("id") ON DELETE cascade ON UPDATE no action;
\ No newline at end of file
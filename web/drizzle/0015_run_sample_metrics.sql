@@ -0,0 +1,4 @@
+ALTER TABLE "run_samples" ADD COLUMN "metrics" jsonb;--> statement-breakpoint
+ALTER TABLE "run_samples" ADD COLUMN "reported" jsonb;--> statement-breakpoint
+ALTER TABLE "run_samples" ADD COLUMN "verdict" text;--> statement-breakpoint
+CREATE INDEX "run_samples_verdict_idx" ON "run_samples" USING btree ("verdict");
\ No newline at end of file
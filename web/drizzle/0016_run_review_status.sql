@@ -0,0 +1,4 @@
+ALTER TABLE "runs" ADD COLUMN "review_status" text DEFAULT 'approved' NOT NULL;--> statement-breakpoint
+ALTER TABLE "runs" ADD COLUMN "anomaly_score" double precision DEFAULT 0 NOT NULL;--> statement-breakpoint
+ALTER TABLE "runs" ADD COLUMN "review_reasons" text[] DEFAULT '{}' NOT NULL;--> statement-breakpoint
+CREATE INDEX "runs_pending_review_idx" ON "runs" USING btree ("created_at") WHERE "runs"."review_status" = 'pending_review' AND "runs"."deleted_at" IS NULL;
\ No newline at end of file
@@ -0,0 +1,25 @@
+CREATE TABLE "run_reports" (
+	"id" uuid PRIMARY KEY DEFAULT gen_random_uuid() NOT NULL,
+	"run_id" uuid NOT NULL,
+	"user_id" text NOT NULL,
+	"reason" text,
+	"created_at" timestamp NOT NULL
+);
+--> statement-breakpoint
+CREATE TABLE "run_reviews" (
+	"id" uuid PRIMARY KEY DEFAULT gen_random_uuid() NOT NULL,
+	"run_id" uuid NOT NULL,
+	"status" text NOT NULL,
+	"reviewer" text NOT NULL,
+	"reason" text,
+	"created_at" timestamp NOT NULL
+);
+--> statement-breakpoint
+DROP INDEX "runs_pending_review_idx";--> statement-breakpoint
+ALTER TABLE "run_reports" ADD CONSTRAINT "run_reports_run_id_runs_id_fk" FOREIGN KEY ("run_id") REFERENCES "public"."runs"("id") ON DELETE cascade ON UPDATE no action;--> statement-breakpoint
+ALTER TABLE "run_reports" ADD CONSTRAINT "run_reports_user_id_user_id_fk" FOREIGN KEY ("user_id") REFERENCES "public"."user"("id") ON DELETE cascade ON UPDATE no action;--> statement-breakpoint
+ALTER TABLE "run_reviews" ADD CONSTRAINT "run_reviews_run_id_runs_id_fk" FOREIGN KEY ("run_id") REFERENCES "public"."runs"("id") ON DELETE cascade ON UPDATE no action;--> statement-breakpoint
+CREATE INDEX "runs_pending_review_idx" ON "runs" USING btree ("created_at") WHERE "runs"."review_status" = 'pending' AND "runs"."deleted_at" IS NULL;--> statement-breakpoint
+CREATE UNIQUE INDEX "run_reports_run_id_user_id_idx" ON "run_reports" USING btree ("run_id","user_id");--> statement-breakpoint
+CREATE INDEX "run_reviews_run_id_idx" ON "run_reviews" USING btree ("run_id","created_at");--> statement-breakpoint
+UPDATE "runs" SET "review_status" = 'pending' WHERE "review_status" = 'pending_review';
\ No newline at end of file
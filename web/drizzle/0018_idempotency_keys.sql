@@ -0,0 +1,12 @@
+CREATE TABLE "idempotency_keys" (
+	"key" text NOT NULL,
+	"route" text NOT NULL,
+	"fingerprint" text NOT NULL,
+	"status_code" integer,
+	"content_type" text,
+	"response" bytea,
+	"created_at" timestamp NOT NULL,
+	CONSTRAINT "idempotency_keys_key_route_pk" PRIMARY KEY("key","route")
+);
+--> statement-breakpoint
+CREATE INDEX "idempotency_keys_created_at_idx" ON "idempotency_keys" USING btree ("created_at");
\ No newline at end of file
@@ -0,0 +1,6 @@
+CREATE TABLE "run_client_ids" (
+	"client_id" text PRIMARY KEY NOT NULL,
+	"run_id" uuid NOT NULL
+);
+--> statement-breakpoint
+ALTER TABLE "run_client_ids" ADD CONSTRAINT "run_client_ids_run_id_runs_id_fk" FOREIGN KEY ("run_id") REFERENCES "public"."runs"("id") ON DELETE cascade ON UPDATE no action;
\ No newline at end of file
@@ -0,0 +1,7 @@
+CREATE TABLE "devices" (
+	"id" text PRIMARY KEY NOT NULL,
+	"clock_offset_ms" bigint,
+	"clock_boot_id" text,
+	"clock_synced_at" timestamp,
+	"created_at" timestamp NOT NULL
+);
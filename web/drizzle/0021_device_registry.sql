@@ -0,0 +1,11 @@
+ALTER TABLE "devices" ADD COLUMN "name" text;--> statement-breakpoint
+ALTER TABLE "devices" ADD COLUMN "registered_at" timestamp;--> statement-breakpoint
+ALTER TABLE "devices" ADD COLUMN "status" text DEFAULT 'offline' NOT NULL;--> statement-breakpoint
+ALTER TABLE "devices" ADD COLUMN "status_changed_at" timestamp;--> statement-breakpoint
+ALTER TABLE "devices" ADD COLUMN "last_heartbeat_at" timestamp;--> statement-breakpoint
+ALTER TABLE "devices" ADD COLUMN "firmware_version" text;--> statement-breakpoint
+ALTER TABLE "devices" ADD COLUMN "uptime_seconds" bigint;--> statement-breakpoint
+ALTER TABLE "devices" ADD COLUMN "wifi_rssi" integer;--> statement-breakpoint
+ALTER TABLE "devices" ADD COLUMN "sensor_healthy" boolean;--> statement-breakpoint
+ALTER TABLE "devices" ADD COLUMN "sensor_error" text;--> statement-breakpoint
+CREATE INDEX "devices_online_idx" ON "devices" USING btree ("last_heartbeat_at") WHERE "devices"."status" = 'online';
\ No newline at end of file
@@ -0,0 +1,22 @@
+CREATE TABLE "device_configs" (
+	"device_id" text PRIMARY KEY NOT NULL,
+	"version" integer NOT NULL,
+	"config" jsonb NOT NULL,
+	"updated_by" text NOT NULL,
+	"updated_at" timestamp NOT NULL,
+	"applied_version" integer,
+	"applied_at" timestamp
+);
+--> statement-breakpoint
+CREATE TABLE "device_config_revisions" (
+	"id" uuid PRIMARY KEY DEFAULT gen_random_uuid() NOT NULL,
+	"device_id" text NOT NULL,
+	"version" integer NOT NULL,
+	"config" jsonb NOT NULL,
+	"changed_by" text NOT NULL,
+	"created_at" timestamp NOT NULL
+);
+--> statement-breakpoint
+ALTER TABLE "device_configs" ADD CONSTRAINT "device_configs_device_id_devices_id_fk" FOREIGN KEY ("device_id") REFERENCES "public"."devices"("id") ON DELETE cascade ON UPDATE no action;--> statement-breakpoint
+ALTER TABLE "device_config_revisions" ADD CONSTRAINT "device_config_revisions_device_id_devices_id_fk" FOREIGN KEY ("device_id") REFERENCES "public"."devices"("id") ON DELETE cascade ON UPDATE no action;--> statement-breakpoint
+CREATE UNIQUE INDEX "device_config_revisions_device_id_version_idx" ON "device_config_revisions" USING btree ("device_id","version");
\ No newline at end of file
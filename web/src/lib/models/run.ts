@@ -1,7 +1,12 @@
 import type { runsTable } from '$lib/server/db/schema/runs';
 import { z } from 'zod';
 
-export type Run = typeof runsTable.$inferSelect;
+// The API owns the columns added after the initial schema; the web app only
+// reads the original ones.
+export type Run = Pick<
+	typeof runsTable.$inferSelect,
+	'id' | 'userId' | 'data' | 'createdAt' | 'image'
+>;
 export type RunDatabseInsertObject = typeof runsTable.$inferInsert;
 
 export type RunWithUser = Run & {
@@ -2,10 +2,39 @@ import { env } from '$env/dynamic/private';
 import { drizzle } from 'drizzle-orm/node-postgres';
 import * as runsSchema from '$lib/server/db/schema/runs';
 import * as authSchema from '$lib/server/db/schema/auth-schema';
+import * as achievementsSchema from '$lib/server/db/schema/achievements';
+import * as eventsSchema from '$lib/server/db/schema/events';
+import * as seasonsSchema from '$lib/server/db/schema/seasons';
+import * as scoringSchema from '$lib/server/db/schema/scoring';
+import * as divisionsSchema from '$lib/server/db/schema/divisions';
+import * as duelsSchema from '$lib/server/db/schema/duels';
+import * as tournamentsSchema from '$lib/server/db/schema/tournaments';
+import * as ratingsSchema from '$lib/server/db/schema/ratings';
+import * as teamsSchema from '$lib/server/db/schema/teams';
+import * as moderationSchema from '$lib/server/db/schema/moderation';
+import * as idempotencySchema from '$lib/server/db/schema/idempotency';
+import * as devicesSchema from '$lib/server/db/schema/devices';
+import * as deviceConfigsSchema from '$lib/server/db/schema/device-configs';
 
 export const db = drizzle({
 	connection: {
 		connectionString: env.DATABASE_URL
 	},
-	schema: { ...runsSchema, ...authSchema }
+	schema: {
+		...runsSchema,
+		...authSchema,
+		...achievementsSchema,
+		...eventsSchema,
+		...seasonsSchema,
+		...scoringSchema,
+		...divisionsSchema,
+		...duelsSchema,
+		...tournamentsSchema,
+		...ratingsSchema,
+		...teamsSchema,
+		...moderationSchema,
+		...idempotencySchema,
+		...devicesSchema,
+		...deviceConfigsSchema
+	}
 });
@@ -0,0 +1,19 @@
+import { pgTable, uuid, text, timestamp, primaryKey } from 'drizzle-orm/pg-core';
+import { user } from './auth-schema';
+import { runsTable } from './runs';
+
+export const userAchievementsTable = pgTable(
+	'user_achievements',
+	{
+		userId: text('user_id')
+			.references(() => user.id, { onDelete: 'cascade' })
+			.notNull(),
+		achievement: text().notNull(),
+		runId: uuid('run_id').references(() => runsTable.id, { onDelete: 'set null' }),
+		achievedAt: timestamp('achieved_at').notNull(),
+		createdAt: timestamp('created_at')
+			.$defaultFn(() => new Date())
+			.notNull()
+	},
+	(table) => [primaryKey({ columns: [table.userId, table.achievement] })]
+);
@@ -0,0 +1,42 @@
+import { pgTable, uuid, text, integer, jsonb, timestamp, uniqueIndex } from 'drizzle-orm/pg-core';
+import { devicesTable } from './devices';
+
+type DeviceConfig = {
+	pulsesPerLitre: number;
+	minRunVolume: number;
+	idleTimeoutSeconds: number;
+	displayBrightness: number;
+};
+
+// The configuration an admin set for a device and the version the device
+// last reported as applied.
+export const deviceConfigsTable = pgTable('device_configs', {
+	deviceId: text('device_id')
+		.primaryKey()
+		.references(() => devicesTable.id, { onDelete: 'cascade' }),
+	version: integer().notNull(),
+	config: jsonb('config').$type<DeviceConfig>().notNull(),
+	updatedBy: text('updated_by').notNull(),
+	updatedAt: timestamp('updated_at').notNull(),
+	appliedVersion: integer('applied_version'),
+	appliedAt: timestamp('applied_at')
+});
+
+export const deviceConfigRevisionsTable = pgTable(
+	'device_config_revisions',
+	{
+		id: uuid().primaryKey().defaultRandom(),
+		deviceId: text('device_id')
+			.references(() => devicesTable.id, { onDelete: 'cascade' })
+			.notNull(),
+		version: integer().notNull(),
+		config: jsonb('config').$type<DeviceConfig>().notNull(),
+		changedBy: text('changed_by').notNull(),
+		createdAt: timestamp('created_at')
+			.$defaultFn(() => new Date())
+			.notNull()
+	},
+	(table) => [
+		uniqueIndex('device_config_revisions_device_id_version_idx').on(table.deviceId, table.version)
+	]
+);
@@ -0,0 +1,32 @@
+import { pgTable, text, bigint, integer, boolean, timestamp, index } from 'drizzle-orm/pg-core';
+import { sql } from 'drizzle-orm';
+
+// Devices the API has heard from. The clock offset is what the API adds to
+// the device's clock to get server time, measured when it last sent its time
+// during the boot recorded with it.
+// Only registered devices take part in duels.
+export const devicesTable = pgTable(
+	'devices',
+	{
+		id: text().primaryKey(),
+		name: text(),
+		clockOffsetMs: bigint('clock_offset_ms', { mode: 'number' }),
+		clockBootId: text('clock_boot_id'),
+		clockSyncedAt: timestamp('clock_synced_at'),
+		registeredAt: timestamp('registered_at'),
+		status: text().$type<'online' | 'offline'>().default('offline').notNull(),
+		statusChangedAt: timestamp('status_changed_at'),
+		lastHeartbeatAt: timestamp('last_heartbeat_at'),
+		firmwareVersion: text('firmware_version'),
+		uptimeSeconds: bigint('uptime_seconds', { mode: 'number' }),
+		wifiRssi: integer('wifi_rssi'),
+		sensorHealthy: boolean('sensor_healthy'),
+		sensorError: text('sensor_error'),
+		createdAt: timestamp('created_at')
+			.$defaultFn(() => new Date())
+			.notNull()
+	},
+	(table) => [
+		index('devices_online_idx').on(table.lastHeartbeatAt).where(sql`${table.status} = 'online'`)
+	]
+);
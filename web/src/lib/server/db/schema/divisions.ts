@@ -0,0 +1,14 @@
+import { pgTable, uuid, text, timestamp, doublePrecision } from 'drizzle-orm/pg-core';
+
+export const divisionsTable = pgTable('divisions', {
+	id: uuid().primaryKey().defaultRandom(),
+	name: text().notNull(),
+	// Nominal volume in litres; runs within tolerance of it are classified
+	// into the division.
+	volume: doublePrecision().notNull(),
+	tolerance: doublePrecision().notNull(),
+	handicap: doublePrecision().default(1).notNull(),
+	createdAt: timestamp('created_at')
+		.$defaultFn(() => new Date())
+		.notNull()
+});
@@ -0,0 +1,23 @@
+import { pgTable, uuid, text, timestamp, index } from 'drizzle-orm/pg-core';
+import { runsTable } from './runs';
+
+export const duelsTable = pgTable(
+	'duels',
+	{
+		id: uuid().primaryKey().defaultRandom(),
+		metric: text().notNull(),
+		deviceA: text('device_a').notNull(),
+		deviceB: text('device_b').notNull(),
+		runA: uuid('run_a').references(() => runsTable.id, { onDelete: 'set null' }),
+		runB: uuid('run_b').references(() => runsTable.id, { onDelete: 'set null' }),
+		winnerRunId: uuid('winner_run_id').references(() => runsTable.id, { onDelete: 'set null' }),
+		status: text().notNull(),
+		startedAt: timestamp('started_at').notNull(),
+		finishedAt: timestamp('finished_at')
+	},
+	(table) => [
+		index('duels_status_idx').on(table.status, table.startedAt.desc()),
+		index('duels_run_a_idx').on(table.runA),
+		index('duels_run_b_idx').on(table.runB)
+	]
+);
@@ -0,0 +1,17 @@
+import { pgTable, uuid, text, timestamp, index } from 'drizzle-orm/pg-core';
+
+export const eventsTable = pgTable(
+	'events',
+	{
+		id: uuid().primaryKey().defaultRandom(),
+		name: text().notNull(),
+		venue: text(),
+		startsAt: timestamp('starts_at').notNull(),
+		endsAt: timestamp('ends_at'),
+		deviceIds: text('device_ids').array().default([]).notNull(),
+		createdAt: timestamp('created_at')
+			.$defaultFn(() => new Date())
+			.notNull()
+	},
+	(table) => [index('events_starts_at_idx').on(table.startsAt)]
+);
@@ -0,0 +1,23 @@
+import { pgTable, text, integer, timestamp, index, primaryKey } from 'drizzle-orm/pg-core';
+import { bytea } from './runs';
+
+// Responses the API replays when a client retries a request with the same
+// Idempotency-Key. A row without a status code is still in flight.
+export const idempotencyKeysTable = pgTable(
+	'idempotency_keys',
+	{
+		key: text().notNull(),
+		route: text().notNull(),
+		fingerprint: text().notNull(),
+		statusCode: integer('status_code'),
+		contentType: text('content_type'),
+		response: bytea(),
+		createdAt: timestamp('created_at')
+			.$defaultFn(() => new Date())
+			.notNull()
+	},
+	(table) => [
+		primaryKey({ columns: [table.key, table.route] }),
+		index('idempotency_keys_created_at_idx').on(table.createdAt)
+	]
+);
@@ -0,0 +1,39 @@
+import { pgTable, uuid, text, timestamp, index, uniqueIndex } from 'drizzle-orm/pg-core';
+import { user } from './auth-schema';
+import { runsTable } from './runs';
+
+// A user can report each run once; enough reports put it up for review.
+export const runReportsTable = pgTable(
+	'run_reports',
+	{
+		id: uuid().primaryKey().defaultRandom(),
+		runId: uuid('run_id')
+			.references(() => runsTable.id, { onDelete: 'cascade' })
+			.notNull(),
+		userId: text('user_id')
+			.references(() => user.id, { onDelete: 'cascade' })
+			.notNull(),
+		reason: text(),
+		createdAt: timestamp('created_at')
+			.$defaultFn(() => new Date())
+			.notNull()
+	},
+	(table) => [uniqueIndex('run_reports_run_id_user_id_idx').on(table.runId, table.userId)]
+);
+
+export const runReviewsTable = pgTable(
+	'run_reviews',
+	{
+		id: uuid().primaryKey().defaultRandom(),
+		runId: uuid('run_id')
+			.references(() => runsTable.id, { onDelete: 'cascade' })
+			.notNull(),
+		status: text().$type<'approved' | 'rejected'>().notNull(),
+		reviewer: text().notNull(),
+		reason: text(),
+		createdAt: timestamp('created_at')
+			.$defaultFn(() => new Date())
+			.notNull()
+	},
+	(table) => [index('run_reviews_run_id_idx').on(table.runId, table.createdAt)]
+);
@@ -0,0 +1,42 @@
+import {
+	pgTable,
+	uuid,
+	text,
+	timestamp,
+	integer,
+	doublePrecision,
+	index
+} from 'drizzle-orm/pg-core';
+import { user } from './auth-schema';
+
+export const userRatingsTable = pgTable('user_ratings', {
+	userId: text('user_id')
+		.primaryKey()
+		.references(() => user.id, { onDelete: 'cascade' }),
+	rating: doublePrecision().notNull(),
+	games: integer().notNull(),
+	lastPlayedAt: timestamp('last_played_at').notNull(),
+	updatedAt: timestamp('updated_at')
+		.$defaultFn(() => new Date())
+		.notNull()
+});
+
+// Each rated game adds one row per player. The source is the duel or
+// tournament match the game was played in.
+export const ratingHistoryTable = pgTable(
+	'rating_history',
+	{
+		id: uuid().primaryKey().defaultRandom(),
+		userId: text('user_id')
+			.references(() => user.id, { onDelete: 'cascade' })
+			.notNull(),
+		opponentId: text('opponent_id').notNull(),
+		source: text().notNull(),
+		sourceId: uuid('source_id').notNull(),
+		score: doublePrecision().notNull(),
+		ratingBefore: doublePrecision('rating_before').notNull(),
+		ratingAfter: doublePrecision('rating_after').notNull(),
+		playedAt: timestamp('played_at').notNull()
+	},
+	(table) => [index('rating_history_user_id_idx').on(table.userId, table.playedAt.desc())]
+);
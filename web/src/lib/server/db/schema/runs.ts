@@ -1,18 +1,131 @@
-import { pgTable, uuid, text, timestamp, jsonb } from 'drizzle-orm/pg-core';
+import {
+	pgTable,
+	uuid,
+	text,
+	timestamp,
+	jsonb,
+	integer,
+	doublePrecision,
+	index,
+	customType
+} from 'drizzle-orm/pg-core';
+import { sql } from 'drizzle-orm';
 import { user } from './auth-schema';
+import { eventsTable } from './events';
+import { divisionsTable } from './divisions';
 
-export const runsTable = pgTable('runs', {
-	id: uuid().primaryKey().defaultRandom(),
-	userId: text('user_id').references(() => user.id, { onDelete: 'cascade' }),
-	data: jsonb('data')
-		.$type<{
+export const bytea = customType<{ data: Buffer }>({
+	dataType() {
+		return 'bytea';
+	}
+});
+
+export const runsTable = pgTable(
+	'runs',
+	{
+		id: uuid().primaryKey().defaultRandom(),
+		userId: text('user_id').references(() => user.id, { onDelete: 'cascade' }),
+		data: jsonb('data')
+			.$type<{
+				duration: number;
+				rate: number;
+				volume: number;
+			}>()
+			.notNull(),
+		createdAt: timestamp('created_at')
+			.$defaultFn(() => new Date())
+			.notNull(),
+		image: text().notNull(),
+		deletedAt: timestamp('deleted_at'),
+		personalBests: text('personal_bests').array().default([]).notNull(),
+		records: text().array().default([]).notNull(),
+		deviceId: text('device_id'),
+		eventId: uuid('event_id').references(() => eventsTable.id, { onDelete: 'set null' }),
+		divisionId: uuid('division_id').references(() => divisionsTable.id, { onDelete: 'set null' }),
+		reviewStatus: text('review_status')
+			.$type<'approved' | 'pending' | 'rejected'>()
+			.default('approved')
+			.notNull(),
+		anomalyScore: doublePrecision('anomaly_score').default(0).notNull(),
+		reviewReasons: text('review_reasons').array().default([]).notNull()
+	},
+	(table) => [
+		index('runs_deleted_at_idx').on(table.deletedAt),
+		index('runs_rate_idx')
+			.on(sql`((${table.data}->>'rate')::float) desc`, table.id.desc())
+			.where(sql`${table.deletedAt} IS NULL`),
+		index('runs_duration_idx')
+			.on(sql`((${table.data}->>'duration')::float) desc`, table.id.desc())
+			.where(sql`${table.deletedAt} IS NULL`),
+		index('runs_volume_idx')
+			.on(sql`((${table.data}->>'volume')::float) desc`, table.id.desc())
+			.where(sql`${table.deletedAt} IS NULL`),
+		index('runs_created_at_idx')
+			.on(table.createdAt.desc(), table.id.desc())
+			.where(sql`${table.deletedAt} IS NULL`),
+		index('runs_user_id_created_at_idx')
+			.on(table.userId, table.createdAt.desc())
+			.where(sql`${table.deletedAt} IS NULL`),
+		index('runs_event_id_idx').on(table.eventId).where(sql`${table.deletedAt} IS NULL`),
+		index('runs_division_id_idx').on(table.divisionId).where(sql`${table.deletedAt} IS NULL`),
+		index('runs_pending_review_idx')
+			.on(table.createdAt)
+			.where(sql`${table.reviewStatus} = 'pending' AND ${table.deletedAt} IS NULL`)
+	]
+);
+
+export const runRevisionsTable = pgTable(
+	'run_revisions',
+	{
+		id: uuid().primaryKey().defaultRandom(),
+		runId: uuid('run_id')
+			.references(() => runsTable.id, { onDelete: 'cascade' })
+			.notNull(),
+		data: jsonb('data')
+			.$type<{
+				duration: number;
+				rate: number;
+				volume: number;
+			}>()
+			.notNull(),
+		reason: text().notNull(),
+		editedBy: text('edited_by').notNull(),
+		createdAt: timestamp('created_at')
+			.$defaultFn(() => new Date())
+			.notNull()
+	},
+	(table) => [index('run_revisions_run_id_idx').on(table.runId, table.createdAt)]
+);
+
+// Raw flow sensor samples of a run in the API's compact binary encoding.
+// Verification stores the figures derived from them next to the reported ones.
+export const runSamplesTable = pgTable(
+	'run_samples',
+	{
+		runId: uuid('run_id')
+			.primaryKey()
+			.references(() => runsTable.id, { onDelete: 'cascade' }),
+		sampleCount: integer('sample_count').notNull(),
+		data: bytea().notNull(),
+		metrics: jsonb('metrics'),
+		reported: jsonb('reported').$type<{
 			duration: number;
 			rate: number;
 			volume: number;
-		}>()
-		.notNull(),
-	createdAt: timestamp('created_at')
-		.$defaultFn(() => new Date())
-		.notNull(),
-	image: text().notNull()
+		}>(),
+		verdict: text().$type<'consistent' | 'corrected' | 'flagged'>(),
+		createdAt: timestamp('created_at')
+			.$defaultFn(() => new Date())
+			.notNull()
+	},
+	(table) => [index('run_samples_verdict_idx').on(table.verdict)]
+);
+
+// Maps the IDs devices generate for runs to the stored run, so a resent run
+// is not saved twice.
+export const runClientIdsTable = pgTable('run_client_ids', {
+	clientId: text('client_id').primaryKey(),
+	runId: uuid('run_id')
+		.references(() => runsTable.id, { onDelete: 'cascade' })
+		.notNull()
 });
@@ -0,0 +1,40 @@
+import {
+	pgTable,
+	uuid,
+	text,
+	timestamp,
+	doublePrecision,
+	index,
+	primaryKey
+} from 'drizzle-orm/pg-core';
+import { runsTable } from './runs';
+
+export const scoringFormulasTable = pgTable('scoring_formulas', {
+	name: text().primaryKey(),
+	expression: text().notNull(),
+	description: text(),
+	createdAt: timestamp('created_at')
+		.$defaultFn(() => new Date())
+		.notNull(),
+	updatedAt: timestamp('updated_at')
+		.$defaultFn(() => new Date())
+		.notNull()
+});
+
+// Scores are a cache of each formula evaluated against each run.
+export const runScoresTable = pgTable(
+	'run_scores',
+	{
+		runId: uuid('run_id')
+			.references(() => runsTable.id, { onDelete: 'cascade' })
+			.notNull(),
+		formula: text()
+			.references(() => scoringFormulasTable.name, { onDelete: 'cascade' })
+			.notNull(),
+		score: doublePrecision().notNull()
+	},
+	(table) => [
+		primaryKey({ columns: [table.runId, table.formula] }),
+		index('run_scores_formula_score_idx').on(table.formula, table.score.desc())
+	]
+);
@@ -0,0 +1,48 @@
+import {
+	pgTable,
+	uuid,
+	text,
+	timestamp,
+	integer,
+	doublePrecision,
+	index,
+	primaryKey
+} from 'drizzle-orm/pg-core';
+
+export const seasonsTable = pgTable(
+	'seasons',
+	{
+		id: uuid().primaryKey().defaultRandom(),
+		name: text().notNull(),
+		startsAt: timestamp('starts_at').notNull(),
+		endsAt: timestamp('ends_at').notNull(),
+		closedAt: timestamp('closed_at'),
+		createdAt: timestamp('created_at')
+			.$defaultFn(() => new Date())
+			.notNull()
+	},
+	(table) => [index('seasons_starts_at_idx').on(table.startsAt, table.endsAt)]
+);
+
+// Final standings are an archive: they keep the user's name and the run ID
+// even after either is deleted.
+export const seasonStandingsTable = pgTable(
+	'season_standings',
+	{
+		seasonId: uuid('season_id')
+			.references(() => seasonsTable.id, { onDelete: 'restrict' })
+			.notNull(),
+		metric: text().notNull(),
+		rank: integer().notNull(),
+		userId: text('user_id').notNull(),
+		userName: text('user_name').notNull(),
+		userUsername: text('user_username').notNull(),
+		runId: uuid('run_id').notNull(),
+		score: doublePrecision().notNull(),
+		recordedAt: timestamp('recorded_at').notNull()
+	},
+	(table) => [
+		primaryKey({ columns: [table.seasonId, table.metric, table.userId] }),
+		index('season_standings_user_id_idx').on(table.userId, table.rank)
+	]
+);
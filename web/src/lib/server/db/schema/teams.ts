@@ -0,0 +1,66 @@
+import {
+	pgTable,
+	uuid,
+	text,
+	timestamp,
+	index,
+	uniqueIndex,
+	primaryKey
+} from 'drizzle-orm/pg-core';
+import { sql } from 'drizzle-orm';
+import { user } from './auth-schema';
+
+export const teamsTable = pgTable('teams', {
+	id: uuid().primaryKey().defaultRandom(),
+	name: text().notNull().unique(),
+	description: text(),
+	createdAt: timestamp('created_at')
+		.$defaultFn(() => new Date())
+		.notNull()
+});
+
+export const teamMembersTable = pgTable(
+	'team_members',
+	{
+		teamId: uuid('team_id')
+			.references(() => teamsTable.id, { onDelete: 'cascade' })
+			.notNull(),
+		userId: text('user_id')
+			.references(() => user.id, { onDelete: 'cascade' })
+			.notNull(),
+		role: text().notNull(),
+		joinedAt: timestamp('joined_at')
+			.$defaultFn(() => new Date())
+			.notNull()
+	},
+	(table) => [
+		primaryKey({ columns: [table.teamId, table.userId] }),
+		index('team_members_user_id_idx').on(table.userId)
+	]
+);
+
+export const teamInvitationsTable = pgTable(
+	'team_invitations',
+	{
+		id: uuid().primaryKey().defaultRandom(),
+		teamId: uuid('team_id')
+			.references(() => teamsTable.id, { onDelete: 'cascade' })
+			.notNull(),
+		userId: text('user_id')
+			.references(() => user.id, { onDelete: 'cascade' })
+			.notNull(),
+		invitedBy: text('invited_by').references(() => user.id, { onDelete: 'set null' }),
+		status: text().notNull(),
+		createdAt: timestamp('created_at')
+			.$defaultFn(() => new Date())
+			.notNull(),
+		respondedAt: timestamp('responded_at')
+	},
+	(table) => [
+		// A user has at most one pending invitation per team.
+		uniqueIndex('team_invitations_pending_idx')
+			.on(table.teamId, table.userId)
+			.where(sql`${table.status} = 'pending'`),
+		index('team_invitations_user_id_idx').on(table.userId, table.status)
+	]
+);
@@ -0,0 +1,71 @@
+import {
+	pgTable,
+	uuid,
+	text,
+	timestamp,
+	integer,
+	index,
+	primaryKey,
+	unique
+} from 'drizzle-orm/pg-core';
+import { sql } from 'drizzle-orm';
+import { user } from './auth-schema';
+import { duelsTable } from './duels';
+
+export const tournamentsTable = pgTable('tournaments', {
+	id: uuid().primaryKey().defaultRandom(),
+	name: text().notNull(),
+	format: text().notNull(),
+	metric: text().notNull(),
+	status: text().notNull(),
+	winnerUserId: text('winner_user_id'),
+	startedAt: timestamp('started_at'),
+	finishedAt: timestamp('finished_at'),
+	createdAt: timestamp('created_at')
+		.$defaultFn(() => new Date())
+		.notNull()
+});
+
+export const tournamentParticipantsTable = pgTable(
+	'tournament_participants',
+	{
+		tournamentId: uuid('tournament_id')
+			.references(() => tournamentsTable.id, { onDelete: 'cascade' })
+			.notNull(),
+		userId: text('user_id')
+			.references(() => user.id, { onDelete: 'cascade' })
+			.notNull(),
+		seed: integer(),
+		createdAt: timestamp('created_at')
+			.$defaultFn(() => new Date())
+			.notNull()
+	},
+	(table) => [primaryKey({ columns: [table.tournamentId, table.userId] })]
+);
+
+export const tournamentMatchesTable = pgTable(
+	'tournament_matches',
+	{
+		id: uuid().primaryKey().defaultRandom(),
+		tournamentId: uuid('tournament_id')
+			.references(() => tournamentsTable.id, { onDelete: 'cascade' })
+			.notNull(),
+		bracket: text().notNull(),
+		round: integer().notNull(),
+		position: integer().notNull(),
+		playerA: text('player_a'),
+		playerB: text('player_b'),
+		winnerUserId: text('winner_user_id'),
+		status: text().notNull(),
+		duelId: uuid('duel_id').references(() => duelsTable.id, { onDelete: 'set null' }),
+		runA: uuid('run_a'),
+		runB: uuid('run_b'),
+		finishedAt: timestamp('finished_at')
+	},
+	(table) => [
+		unique().on(table.tournamentId, table.bracket, table.round, table.position),
+		index('tournament_matches_players_idx')
+			.on(table.playerA, table.playerB)
+			.where(sql`${table.status} = 'ready'`)
+	]
+);